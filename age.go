@@ -0,0 +1,453 @@
+package keyring
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"filippo.io/age"
+	"filippo.io/age/agessh"
+	"golang.org/x/crypto/ssh"
+)
+
+// ageFileExt is the extension of item files written by the age backend
+const ageFileExt = ".age"
+
+func init() {
+	supportedBackends[AgeBackend] = opener(func(cfg Config) (Keyring, error) {
+		if cfg.AgeDir == "" {
+			return nil, errors.New("No directory provided for age keyring")
+		}
+		if len(cfg.AgeRecipients) == 0 && cfg.AgeIdentityFile == "" &&
+			cfg.AgeSSHPublicKeyFile == "" && cfg.AgeSSHPrivateKeyFile == "" {
+			return nil, errors.New("No age recipients or identity file provided")
+		}
+
+		k := &ageKeyring{
+			dir:              cfg.AgeDir,
+			service:          cfg.ServiceName,
+			identityFile:     cfg.AgeIdentityFile,
+			sshPublicKeyFile: cfg.AgeSSHPublicKeyFile,
+			sshKeyFile:       cfg.AgeSSHPrivateKeyFile,
+			passphraseFunc:   cfg.AgeSSHPassphraseFunc,
+		}
+		if k.passphraseFunc == nil {
+			k.passphraseFunc = terminalPrompt
+		}
+
+		recipients := cfg.AgeRecipients
+		if cfg.AgeSSHPublicKeyFile != "" {
+			pubKey, err := readAgeFile(cfg.AgeSSHPublicKeyFile)
+			if err != nil {
+				return nil, err
+			}
+			recipients = append(recipients, string(pubKey))
+		}
+		for _, r := range recipients {
+			recipient, err := parseAgeRecipient(r)
+			if err != nil {
+				return nil, err
+			}
+			k.recipients = append(k.recipients, recipient)
+		}
+
+		return k, nil
+	})
+}
+
+// ageKeyring stores each item as a separate age encrypted file, so that they can be read
+// with the age command line tool. Files are named after a hash of the service and key,
+// which means listing keys requires decrypting every file.
+type ageKeyring struct {
+	dir              string
+	service          string
+	recipients       []age.Recipient
+	identityFile     string
+	sshPublicKeyFile string
+	sshKeyFile       string
+	passphraseFunc   PromptFunc
+
+	mu         sync.Mutex
+	identities []age.Identity
+}
+
+// parseAgeRecipient accepts either a native X25519 recipient or an SSH public key
+func parseAgeRecipient(s string) (age.Recipient, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "age1") {
+		return age.ParseX25519Recipient(s)
+	}
+	r, err := agessh.ParseRecipient(s)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse age recipient %q: %w", s, err)
+	}
+	return r, nil
+}
+
+// readAgeFile reads a key file, expanding a leading ~ to the home directory
+func readAgeFile(file string) ([]byte, error) {
+	path, err := expandTilde(file)
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadFile(path)
+}
+
+// loadIdentities reads the identity files on first use. The age identity file may be either
+// an age identity file or an unencrypted SSH private key, while the SSH private key file may
+// also be protected by a passphrase.
+func (k *ageKeyring) loadIdentities() ([]age.Identity, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if k.identities != nil {
+		return k.identities, nil
+	}
+	if k.identityFile == "" && k.sshKeyFile == "" {
+		return nil, errors.New("No age identity file provided")
+	}
+
+	var identities []age.Identity
+	if k.identityFile != "" {
+		b, err := readAgeFile(k.identityFile)
+		if err != nil {
+			return nil, err
+		}
+
+		ids, err := age.ParseIdentities(bytes.NewReader(b))
+		if err != nil {
+			identity, sshErr := agessh.ParseIdentity(b)
+			if sshErr != nil {
+				return nil, fmt.Errorf("Failed to parse age identity file %s: %w", k.identityFile, err)
+			}
+			ids = []age.Identity{identity}
+		}
+		identities = append(identities, ids...)
+	}
+
+	if k.sshKeyFile != "" {
+		identity, err := k.loadSSHIdentity()
+		if err != nil {
+			return nil, err
+		}
+		identities = append(identities, identity)
+	}
+
+	k.identities = identities
+	return identities, nil
+}
+
+// loadSSHIdentity parses the SSH private key file. If it's protected by a passphrase, the
+// passphrase is prompted for when an item is first decrypted.
+func (k *ageKeyring) loadSSHIdentity() (age.Identity, error) {
+	b, err := readAgeFile(k.sshKeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	identity, err := agessh.ParseIdentity(b)
+	var missing *ssh.PassphraseMissingError
+	if !errors.As(err, &missing) {
+		if err != nil {
+			return nil, fmt.Errorf("Failed to parse SSH private key %s: %w", k.sshKeyFile, err)
+		}
+		return identity, nil
+	}
+
+	// Older key formats don't include the public key, so fall back to the public key file
+	pubKey := missing.PublicKey
+	if pubKey == nil {
+		pubKeyFile := k.sshPublicKeyFile
+		if pubKeyFile == "" {
+			pubKeyFile = k.sshKeyFile + ".pub"
+		}
+		pb, err := readAgeFile(pubKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("The SSH private key %s is encrypted and its public key is needed: %w", k.sshKeyFile, err)
+		}
+		if pubKey, _, _, _, err = ssh.ParseAuthorizedKey(pb); err != nil {
+			return nil, fmt.Errorf("Failed to parse SSH public key %s: %w", pubKeyFile, err)
+		}
+	}
+
+	return agessh.NewEncryptedSSHIdentity(pubKey, b, func() ([]byte, error) {
+		passphrase, err := k.passphraseFunc(fmt.Sprintf("Enter passphrase for %s", k.sshKeyFile))
+		return []byte(passphrase), err
+	})
+}
+
+func (k *ageKeyring) filename(key string) string {
+	sum := sha256.Sum256([]byte(k.service + "/" + key))
+	return hex.EncodeToString(sum[:]) + ageFileExt
+}
+
+func (k *ageKeyring) path(key string) (string, error) {
+	dir, err := ensureDir(k.dir)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, k.filename(key)), nil
+}
+
+func (k *ageKeyring) decrypt(path string) (Item, error) {
+	var item Item
+	err := k.decryptStream(path, func(i Item, data io.Reader) error {
+		rest, err := io.ReadAll(data)
+		if err != nil {
+			return err
+		}
+		item = i
+		item.Data = append(item.Data, rest...)
+		return nil
+	})
+	return item, err
+}
+
+// decryptStream decrypts the item file at path, calling fn with the item and a reader of
+// any data following it. Items written by setStream have their data after the JSON encoded
+// item, rather than in it, so that it can be streamed.
+func (k *ageKeyring) decryptStream(path string, fn func(item Item, data io.Reader) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	identities, err := k.loadIdentities()
+	if err != nil {
+		return err
+	}
+
+	r, err := age.Decrypt(f, identities...)
+	if err != nil {
+		return err
+	}
+
+	dec := json.NewDecoder(r)
+	var item Item
+	if err = dec.Decode(&item); err != nil {
+		return err
+	}
+
+	data := bufio.NewReader(io.MultiReader(dec.Buffered(), r))
+	if b, err := data.Peek(1); err == nil && b[0] == '\n' {
+		_, _ = data.Discard(1)
+	}
+	return fn(item, data)
+}
+
+func (k *ageKeyring) Get(key string) (Item, error) {
+	path, err := k.path(key)
+	if err != nil {
+		return Item{}, WrapError(err, AgeBackend, k.service, key)
+	}
+
+	item, err := k.decrypt(path)
+	if os.IsNotExist(err) {
+		return Item{}, ErrKeyNotFound
+	} else if err != nil {
+		return Item{}, WrapError(err, AgeBackend, k.service, key)
+	}
+	return checkActive(item)
+}
+
+// GetMetadata for age only returns the modification time, as everything else is encrypted
+func (k *ageKeyring) GetMetadata(key string) (Metadata, error) {
+	path, err := k.path(key)
+	if err != nil {
+		return Metadata{}, WrapError(err, AgeBackend, k.service, key)
+	}
+
+	stat, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return Metadata{}, ErrKeyNotFound
+	} else if err != nil {
+		return Metadata{}, WrapError(err, AgeBackend, k.service, key)
+	}
+
+	return Metadata{
+		ModificationTime: stat.ModTime(),
+	}, nil
+}
+
+// recipientsFor returns the recipients that item is encrypted to, which are its
+// AgeRecipients if it has any and the keyring's otherwise
+func (k *ageKeyring) recipientsFor(item Item) ([]age.Recipient, error) {
+	if len(item.AgeRecipients) == 0 {
+		if len(k.recipients) == 0 {
+			return nil, errors.New("No age recipients provided")
+		}
+		return k.recipients, nil
+	}
+
+	recipients := make([]age.Recipient, 0, len(item.AgeRecipients))
+	for _, r := range item.AgeRecipients {
+		recipient, err := parseAgeRecipient(r)
+		if err != nil {
+			return nil, err
+		}
+		recipients = append(recipients, recipient)
+	}
+	return recipients, nil
+}
+
+func (k *ageKeyring) Set(item Item) error {
+	recipients, err := k.recipientsFor(item)
+	if err != nil {
+		return WrapError(err, AgeBackend, k.service, item.Key)
+	}
+	if err = ValidateRotationSchedule(item); err != nil {
+		return WrapError(err, AgeBackend, k.service, item.Key)
+	}
+
+	path, err := k.path(item.Key)
+	if err != nil {
+		return WrapError(err, AgeBackend, k.service, item.Key)
+	}
+
+	payload, err := json.Marshal(item)
+	if err != nil {
+		return WrapError(err, AgeBackend, k.service, item.Key)
+	}
+
+	var buf bytes.Buffer
+	w, err := age.Encrypt(&buf, recipients...)
+	if err != nil {
+		return WrapError(err, AgeBackend, k.service, item.Key)
+	}
+	if _, err = w.Write(payload); err != nil {
+		return WrapError(err, AgeBackend, k.service, item.Key)
+	}
+	if err = w.Close(); err != nil {
+		return WrapError(err, AgeBackend, k.service, item.Key)
+	}
+
+	return WrapError(ioutil.WriteFile(path, buf.Bytes(), 0600), AgeBackend, k.service, item.Key)
+}
+
+func (k *ageKeyring) Remove(key string) error {
+	path, err := k.path(key)
+	if err != nil {
+		return WrapError(err, AgeBackend, k.service, key)
+	}
+
+	err = os.Remove(path)
+	if os.IsNotExist(err) {
+		return ErrKeyNotFound
+	}
+	return WrapError(err, AgeBackend, k.service, key)
+}
+
+// Keys decrypts every item in the directory, skipping those whose file name shows they
+// belong to another service
+func (k *ageKeyring) Keys() ([]string, error) {
+	dir, err := ensureDir(k.dir)
+	if err != nil {
+		return nil, WrapError(err, AgeBackend, k.service, "")
+	}
+
+	matches, err := filepath.Glob(filepath.Join(dir, "*"+ageFileExt))
+	if err != nil {
+		return nil, WrapError(err, AgeBackend, k.service, "")
+	}
+
+	var keys = []string{}
+	for _, path := range matches {
+		var item Item
+		err := k.decryptStream(path, func(i Item, _ io.Reader) error {
+			item = i
+			return nil
+		})
+		var noMatch *age.NoIdentityMatchError
+		if errors.As(err, &noMatch) {
+			Debugf("Skipping %s, it isn't encrypted to our identity", path)
+			continue
+		} else if err != nil {
+			return nil, WrapError(err, AgeBackend, k.service, "")
+		}
+		if filepath.Base(path) != k.filename(item.Key) {
+			continue
+		}
+		keys = append(keys, item.Key)
+	}
+
+	return keys, nil
+}
+
+// setStream encrypts the data read from r to a temporary file as it's read, which then
+// replaces the item file
+func (k *ageKeyring) setStream(key string, r io.Reader, label, description string) error {
+	if len(k.recipients) == 0 {
+		return errors.New("No age recipients provided")
+	}
+
+	path, err := k.path(key)
+	if err != nil {
+		return WrapError(err, AgeBackend, k.service, key)
+	}
+
+	header, err := json.Marshal(Item{Key: key, Label: label, Description: description})
+	if err != nil {
+		return WrapError(err, AgeBackend, k.service, key)
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return WrapError(err, AgeBackend, k.service, key)
+	}
+	defer os.Remove(tmp.Name())
+
+	w, err := age.Encrypt(tmp, k.recipients...)
+	if err == nil {
+		_, err = w.Write(append(header, '\n'))
+	}
+	if err == nil {
+		_, err = io.Copy(w, r)
+	}
+	if err == nil {
+		err = w.Close()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return WrapError(err, AgeBackend, k.service, key)
+	}
+
+	return WrapError(os.Rename(tmp.Name(), path), AgeBackend, k.service, key)
+}
+
+// getStream decrypts the item's data to w as it's read
+func (k *ageKeyring) getStream(key string, w io.Writer) (label, description string, err error) {
+	path, err := k.path(key)
+	if err != nil {
+		return "", "", WrapError(err, AgeBackend, k.service, key)
+	}
+
+	err = k.decryptStream(path, func(item Item, data io.Reader) error {
+		if _, err := checkActive(item); err != nil {
+			return err
+		}
+		label, description = item.Label, item.Description
+
+		if _, err := w.Write(item.Data); err != nil {
+			return err
+		}
+		_, err := io.Copy(w, data)
+		return err
+	})
+	if os.IsNotExist(err) {
+		return "", "", ErrKeyNotFound
+	}
+	return label, description, WrapError(err, AgeBackend, k.service, key)
+}
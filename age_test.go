@@ -0,0 +1,233 @@
+package keyring
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"filippo.io/age"
+	"golang.org/x/crypto/ssh"
+)
+
+func openAgeKeyring(t *testing.T, dir, service string) Keyring {
+	t.Helper()
+
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return openAgeKeyringWithIdentity(t, dir, service, identity)
+}
+
+func openAgeKeyringWithIdentity(t *testing.T, dir, service string, identity *age.X25519Identity) Keyring {
+	t.Helper()
+
+	identityFile := filepath.Join(dir, identity.Recipient().String()+".key")
+	err := ioutil.WriteFile(identityFile, []byte(identity.String()+"\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	k, err := supportedBackends[AgeBackend](Config{
+		ServiceName:     service,
+		AgeDir:          filepath.Join(dir, "items"),
+		AgeRecipients:   []string{identity.Recipient().String()},
+		AgeIdentityFile: identityFile,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
+
+func TestAgeKeyringSetGetRemove(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keyring-age-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	k := openAgeKeyring(t, dir, "test")
+	if err = k.Set(Item{Key: "llamas", Data: []byte("llamas are great")}); err != nil {
+		t.Fatal(err)
+	}
+
+	item, err := k.Get("llamas")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(item.Data) != "llamas are great" {
+		t.Fatalf("Value stored was not the value retrieved: %q", item.Data)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "items", "*.age"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || filepath.Base(files[0]) == "llamas.age" {
+		t.Fatalf("Expected a single file named by hash, got: %v", files)
+	}
+
+	if err = k.Remove("llamas"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = k.Get("llamas"); err != ErrKeyNotFound {
+		t.Fatalf("Expected ErrKeyNotFound, got: %v", err)
+	}
+}
+
+func TestAgeKeyringItemRecipients(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keyring-age-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	aliceIdentity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bobIdentity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	alice := openAgeKeyringWithIdentity(t, dir, "shared", aliceIdentity)
+	bob := openAgeKeyringWithIdentity(t, dir, "shared", bobIdentity)
+
+	aliceRecipient, bobRecipient := aliceIdentity.Recipient().String(), bobIdentity.Recipient().String()
+	for _, item := range []Item{
+		{Key: "alice", Data: []byte("for alice"), AgeRecipients: []string{aliceRecipient}},
+		{Key: "bob", Data: []byte("for bob"), AgeRecipients: []string{bobRecipient}},
+		{Key: "both", Data: []byte("for both"), AgeRecipients: []string{aliceRecipient, bobRecipient}},
+	} {
+		if err = alice.Set(item); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, tc := range []struct {
+		kr       Keyring
+		readable []string
+		hidden   []string
+	}{
+		{alice, []string{"alice", "both"}, []string{"bob"}},
+		{bob, []string{"bob", "both"}, []string{"alice"}},
+	} {
+		for _, key := range tc.readable {
+			item, err := tc.kr.Get(key)
+			if err != nil {
+				t.Fatalf("Expected %q to be readable: %v", key, err)
+			}
+			if len(item.AgeRecipients) == 0 {
+				t.Fatalf("Expected %q to keep its recipients", key)
+			}
+		}
+		for _, key := range tc.hidden {
+			if _, err := tc.kr.Get(key); err == nil {
+				t.Fatalf("Expected %q not to be readable", key)
+			}
+		}
+
+		keys, err := tc.kr.Keys()
+		if err != nil {
+			t.Fatal(err)
+		}
+		sort.Strings(keys)
+		if !reflect.DeepEqual(keys, tc.readable) {
+			t.Fatalf("Expected keys %v, got %v", tc.readable, keys)
+		}
+	}
+
+	if err = alice.Set(Item{Key: "bad", AgeRecipients: []string{"not a recipient"}}); err == nil {
+		t.Fatal("Expected an error for an invalid recipient")
+	}
+}
+
+func TestAgeKeyringKeysIgnoresOtherServices(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keyring-age-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	llamas := openAgeKeyring(t, dir, "llamas")
+	alpacas := openAgeKeyring(t, dir, "alpacas")
+
+	if err = llamas.Set(Item{Key: "one"}); err != nil {
+		t.Fatal(err)
+	}
+	if err = alpacas.Set(Item{Key: "two"}); err != nil {
+		t.Fatal(err)
+	}
+
+	keys, err := llamas.Keys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 1 || keys[0] != "one" {
+		t.Fatalf("Unexpected keys: %v", keys)
+	}
+}
+
+func TestAgeKeyringEncryptedSSHKey(t *testing.T) {
+	dir := t.TempDir()
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := ssh.MarshalPrivateKeyWithPassphrase(priv, "llama@example.com", []byte("alpacas"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sshPub, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	privateKeyFile := filepath.Join(dir, "id_ed25519")
+	publicKeyFile := privateKeyFile + ".pub"
+	if err = ioutil.WriteFile(privateKeyFile, pem.EncodeToMemory(block), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err = ioutil.WriteFile(publicKeyFile, ssh.MarshalAuthorizedKey(sshPub), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var prompts int
+	k, err := supportedBackends[AgeBackend](Config{
+		AgeDir:               filepath.Join(dir, "items"),
+		AgeSSHPublicKeyFile:  publicKeyFile,
+		AgeSSHPrivateKeyFile: privateKeyFile,
+		AgeSSHPassphraseFunc: func(string) (string, error) {
+			prompts++
+			return "alpacas", nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = k.Set(Item{Key: "llamas", Data: []byte("llamas are great")}); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		item, err := k.Get("llamas")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(item.Data) != "llamas are great" {
+			t.Fatalf("Value stored was not the value retrieved: %q", item.Data)
+		}
+	}
+
+	if prompts != 1 {
+		t.Fatalf("Expected to be prompted for the passphrase once, got %d", prompts)
+	}
+}
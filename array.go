@@ -20,13 +20,16 @@ func NewArrayKeyring(initial []Item) *ArrayKeyring {
 // Get returns an Item matching Key
 func (k *ArrayKeyring) Get(key string) (Item, error) {
 	if i, ok := k.items[key]; ok {
-		return i, nil
+		return checkActive(i)
 	}
 	return Item{}, ErrKeyNotFound
 }
 
 // Set will store an item on the mock Keyring
 func (k *ArrayKeyring) Set(i Item) error {
+	if err := ValidateRotationSchedule(i); err != nil {
+		return err
+	}
 	if k.items == nil {
 		k.items = map[string]Item{}
 	}
@@ -34,6 +37,14 @@ func (k *ArrayKeyring) Set(i Item) error {
 	return nil
 }
 
+// SetIfNotExists will store an item on the mock Keyring only if its key isn't already present
+func (k *ArrayKeyring) SetIfNotExists(i Item) (bool, error) {
+	if _, ok := k.items[i.Key]; ok {
+		return false, nil
+	}
+	return true, k.Set(i)
+}
+
 // Remove will delete an Item from the Keyring
 func (k *ArrayKeyring) Remove(key string) error {
 	delete(k.items, key)
@@ -1,6 +1,9 @@
 package keyring
 
-import "testing"
+import (
+	"testing"
+	"time"
+)
 
 func TestArrayKeyringSetWhenEmpty(t *testing.T) {
 	k := &ArrayKeyring{}
@@ -23,3 +26,22 @@ func TestArrayKeyringSetWhenEmpty(t *testing.T) {
 		t.Fatalf("Key wasn't persisted: %q", foundItem.Key)
 	}
 }
+
+func TestArrayKeyringNotBefore(t *testing.T) {
+	k := &ArrayKeyring{}
+	notBefore := time.Now().Add(time.Hour)
+
+	if err := k.Set(Item{Key: "llamas", NotBefore: notBefore}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := k.Get("llamas"); err != ErrNotYetActive {
+		t.Fatalf("Expected ErrNotYetActive, got: %v", err)
+	}
+
+	if err := k.Set(Item{Key: "llamas", NotBefore: time.Now().Add(-time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := k.Get("llamas"); err != nil {
+		t.Fatalf("Expected an item past its NotBefore to be returned, got: %v", err)
+	}
+}
@@ -0,0 +1,132 @@
+// Package audit records an event for each read or write of a keyring item, identifying the
+// process that made it, for compliance auditing.
+//
+// Keys are recorded as SHA-256 hashes, so that the audit log doesn't reveal the names of
+// secrets to those who can read it.
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"time"
+
+	"github.com/99designs/keyring"
+)
+
+// AuditEvent describes an operation on a keyring item
+type AuditEvent struct {
+	Timestamp     time.Time `json:"timestamp"`
+	Operation     string    `json:"operation"`
+	KeyHash       string    `json:"key_hash"`
+	BackendName   string    `json:"backend"`
+	CallerPID     int       `json:"caller_pid"`
+	CallerUID     int       `json:"caller_uid"`
+	CallerProcess string    `json:"caller_process"`
+	Success       bool      `json:"success"`
+	ErrorCode     string    `json:"error_code,omitempty"`
+}
+
+// AuditSink records audit events
+type AuditSink interface {
+	Emit(AuditEvent) error
+}
+
+// NullSink discards audit events
+type NullSink struct{}
+
+// Emit does nothing
+func (NullSink) Emit(AuditEvent) error {
+	return nil
+}
+
+type auditKeyring struct {
+	keyring.Keyring
+	sink    AuditSink
+	backend string
+
+	// The caller is looked up once, as it can't change
+	pid     int
+	uid     int
+	process string
+}
+
+// NewAuditKeyring wraps kr so that each Get, Set and Remove is reported to sink, with
+// BackendName set to backend. GetMetadata and Keys aren't audited, as they don't reveal
+// or change item data. Errors from sink are logged when keyring.Debug is set but don't
+// fail the operation, which has already run.
+func NewAuditKeyring(kr keyring.Keyring, sink AuditSink, backend keyring.BackendType) keyring.Keyring {
+	process, err := os.Executable()
+	if err != nil {
+		process = os.Args[0]
+	}
+
+	return &auditKeyring{
+		Keyring: kr,
+		sink:    sink,
+		backend: string(backend),
+		pid:     os.Getpid(),
+		uid:     os.Getuid(),
+		process: process,
+	}
+}
+
+// KeyHash returns the hex encoded SHA-256 of key, as recorded in AuditEvent.KeyHash
+func KeyHash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// errorCode classifies err for AuditEvent.ErrorCode
+func errorCode(err error) string {
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, keyring.ErrKeyNotFound):
+		return "not_found"
+	case errors.Is(err, keyring.ErrReadOnly):
+		return "read_only"
+	case errors.Is(err, keyring.ErrCorrupted):
+		return "corrupted"
+	case errors.Is(err, keyring.ErrNotYetActive):
+		return "not_yet_active"
+	default:
+		return "error"
+	}
+}
+
+func (k *auditKeyring) emit(op string, key string, err error) {
+	event := AuditEvent{
+		Timestamp:     time.Now().UTC(),
+		Operation:     op,
+		KeyHash:       KeyHash(key),
+		BackendName:   k.backend,
+		CallerPID:     k.pid,
+		CallerUID:     k.uid,
+		CallerProcess: k.process,
+		Success:       err == nil,
+		ErrorCode:     errorCode(err),
+	}
+	if emitErr := k.sink.Emit(event); emitErr != nil {
+		keyring.Debugf("Failed to emit audit event for %s: %v", op, emitErr)
+	}
+}
+
+func (k *auditKeyring) Get(key string) (keyring.Item, error) {
+	item, err := k.Keyring.Get(key)
+	k.emit(keyring.OpGet, key, err)
+	return item, err
+}
+
+func (k *auditKeyring) Set(item keyring.Item) error {
+	err := k.Keyring.Set(item)
+	k.emit(keyring.OpSet, item.Key, err)
+	return err
+}
+
+func (k *auditKeyring) Remove(key string) error {
+	err := k.Keyring.Remove(key)
+	k.emit(keyring.OpRemove, key, err)
+	return err
+}
@@ -0,0 +1,57 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/99designs/keyring"
+)
+
+func TestAuditKeyring(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	sink, err := NewFileSink(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kr := NewAuditKeyring(&keyring.ArrayKeyring{}, sink, keyring.FileBackend)
+	_ = kr.Set(keyring.Item{Key: "llamas", Data: []byte("llamas are great")})
+	_, _ = kr.Get("llamas")
+	_, _ = kr.Get("alpacas")
+	_, _ = kr.Keys()
+	if err = sink.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var events []AuditEvent
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		var e AuditEvent
+		if err = json.Unmarshal(s.Bytes(), &e); err != nil {
+			t.Fatal(err)
+		}
+		events = append(events, e)
+	}
+
+	if len(events) != 3 {
+		t.Fatalf("Expected 3 events, got %d", len(events))
+	}
+	if events[0].Operation != keyring.OpSet || !events[0].Success || events[0].KeyHash != KeyHash("llamas") {
+		t.Fatalf("Unexpected Set event: %+v", events[0])
+	}
+	if events[2].Success || events[2].ErrorCode != "not_found" {
+		t.Fatalf("Expected a not_found Get event, got: %+v", events[2])
+	}
+	if events[1].BackendName != "file" || events[1].CallerPID != os.Getpid() || events[1].CallerProcess == "" {
+		t.Fatalf("Unexpected caller details: %+v", events[1])
+	}
+}
@@ -0,0 +1,40 @@
+package audit
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+)
+
+// FileSink appends audit events to a file as lines of JSON
+type FileSink struct {
+	mu sync.Mutex
+	f  *os.File
+}
+
+// NewFileSink opens path for appending, creating it if needed
+func NewFileSink(path string) (*FileSink, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return &FileSink{f: f}, nil
+}
+
+// Emit writes event as a line of JSON
+func (s *FileSink) Emit(event AuditEvent) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.f.Write(append(line, '\n'))
+	return err
+}
+
+// Close closes the file
+func (s *FileSink) Close() error {
+	return s.f.Close()
+}
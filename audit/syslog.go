@@ -0,0 +1,42 @@
+//go:build !windows && !plan9
+// +build !windows,!plan9
+
+package audit
+
+import (
+	"encoding/json"
+	"log/syslog"
+)
+
+// SyslogSink sends audit events to the system logger as JSON, with the auth facility.
+// Failed operations are logged as warnings.
+type SyslogSink struct {
+	w *syslog.Writer
+}
+
+// NewSyslogSink connects to the system logger, tagging messages with tag
+func NewSyslogSink(tag string) (*SyslogSink, error) {
+	w, err := syslog.New(syslog.LOG_AUTH|syslog.LOG_INFO, tag)
+	if err != nil {
+		return nil, err
+	}
+	return &SyslogSink{w: w}, nil
+}
+
+// Emit logs event
+func (s *SyslogSink) Emit(event AuditEvent) error {
+	msg, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	if !event.Success {
+		return s.w.Warning(string(msg))
+	}
+	return s.w.Info(string(msg))
+}
+
+// Close disconnects from the system logger
+func (s *SyslogSink) Close() error {
+	return s.w.Close()
+}
@@ -0,0 +1,62 @@
+package keyring
+
+import "runtime"
+
+// autoBackendOrder lists the backends NewAutoKeyring prefers on each platform. The native
+// credential store comes first, with the encrypted file backend as a fallback.
+var autoBackendOrder = map[string][]BackendType{
+	"darwin":  {KeychainBackend, FileBackend},
+	"windows": {WinCredBackend, FileBackend},
+	"linux":   {SecretServiceBackend, FileBackend},
+}
+
+// NewAutoKeyring opens the most appropriate backend for the current platform: the Keychain
+// on macOS, the credential manager on Windows and the Secret Service on Linux (when D-Bus
+// is available), falling back to the encrypted file backend. If cfg.AllowedBackends is set,
+// only those backends are considered.
+func NewAutoKeyring(cfg Config) (Keyring, error) {
+	if err := validateKeyPrefix(cfg.KeyPrefix); err != nil {
+		return nil, err
+	}
+
+	for _, backend := range autoBackends(runtime.GOOS, cfg.AllowedBackends) {
+		opener, ok := supportedBackends[backend]
+		if !ok {
+			Debugf("Skipping %s backend, it isn't available on this system", backend)
+			continue
+		}
+
+		kr, err := opener(cfg)
+		if err != nil {
+			Debugf("Skipping %s backend, it failed to open: %s", backend, err)
+			continue
+		}
+
+		Debugf("Selected %s backend as the preferred backend for %s", backend, runtime.GOOS)
+		return wrap(kr, cfg), nil
+	}
+
+	return nil, ErrNoAvailImpl
+}
+
+// autoBackends returns the backends NewAutoKeyring should try on goos, in order
+func autoBackends(goos string, allowed []BackendType) []BackendType {
+	order, ok := autoBackendOrder[goos]
+	if !ok {
+		order = []BackendType{FileBackend}
+	}
+	if allowed == nil {
+		return order
+	}
+
+	candidates := []BackendType{}
+	for _, b := range order {
+		for _, a := range allowed {
+			if a == b {
+				candidates = append(candidates, b)
+				break
+			}
+		}
+	}
+	return candidates
+}
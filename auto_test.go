@@ -0,0 +1,32 @@
+package keyring
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAutoBackends(t *testing.T) {
+	for _, tc := range []struct {
+		goos     string
+		allowed  []BackendType
+		expected []BackendType
+	}{
+		{"darwin", nil, []BackendType{KeychainBackend, FileBackend}},
+		{"windows", nil, []BackendType{WinCredBackend, FileBackend}},
+		{"linux", nil, []BackendType{SecretServiceBackend, FileBackend}},
+		{"freebsd", nil, []BackendType{FileBackend}},
+		{"linux", []BackendType{FileBackend, PassBackend}, []BackendType{FileBackend}},
+		{"darwin", []BackendType{PassBackend}, []BackendType{}},
+	} {
+		got := autoBackends(tc.goos, tc.allowed)
+		if !reflect.DeepEqual(got, tc.expected) {
+			t.Errorf("autoBackends(%q, %v) = %v, expected %v", tc.goos, tc.allowed, got, tc.expected)
+		}
+	}
+}
+
+func TestNewAutoKeyringRespectsAllowedBackends(t *testing.T) {
+	if _, err := NewAutoKeyring(Config{AllowedBackends: []BackendType{PassBackend}}); err != ErrNoAvailImpl {
+		t.Fatalf("Expected ErrNoAvailImpl, got: %v", err)
+	}
+}
@@ -0,0 +1,284 @@
+// Package azblob stores keyring items as blobs in an Azure Blob Storage container, for
+// items too large for Azure Key Vault's 25KB limit on secret values.
+//
+// Each item is a blob named after its key, whose content is the item's data. The label,
+// description and tags are kept in the blob's metadata. Importing the package registers
+// it with keyring.Open as the "azblob" backend, which uses Config.AzStorageAccountName,
+// and Config.AzContainerName or else Config.ServiceName as the container, authenticating
+// with the default Azure credential chain.
+package azblob
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/99designs/keyring"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	azstorage "github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
+)
+
+// Backend is the name that the backend is registered with keyring.Open under
+const Backend keyring.BackendType = "azblob"
+
+func init() {
+	keyring.RegisterBackend(Backend, func(cfg keyring.Config) (keyring.Keyring, error) {
+		if cfg.AzStorageAccountName == "" {
+			return nil, errors.New("No Azure storage account provided")
+		}
+
+		cred, err := azidentity.NewDefaultAzureCredential(nil)
+		if err != nil {
+			return nil, err
+		}
+
+		container := cfg.AzContainerName
+		if container == "" {
+			container = cfg.ServiceName
+		}
+
+		return New(Config{
+			AccountName:   cfg.AzStorageAccountName,
+			ContainerName: container,
+			Credential:    cred,
+		})
+	})
+}
+
+// Config configures access to Azure Blob Storage
+type Config struct {
+	// AccountName is the storage account, used to build the service URL
+	AccountName string
+	// ContainerName is the container that items are stored in. It's created by the
+	// first Set if it doesn't exist.
+	ContainerName string
+	// Credential authenticates requests to AccountName
+	Credential azcore.TokenCredential
+	// Client is the Blob Storage client to use instead of one for AccountName and
+	// Credential, e.g. one created from a connection string
+	Client *azstorage.Client
+	// Timeout bounds each operation. Zero means 30 seconds.
+	Timeout time.Duration
+}
+
+// AzBlobBackend is a keyring stored in an Azure Blob Storage container
+type AzBlobBackend struct {
+	client    *azstorage.Client
+	container string
+	timeout   time.Duration
+}
+
+// Names of the blob metadata that items' fields are stored in. Metadata values must be
+// ASCII, so they're URL query escaped.
+const (
+	metaLabel       = "label"
+	metaDescription = "description"
+	metaTags        = "tags"
+)
+
+// New returns an AzBlobBackend for the container described by cfg
+func New(cfg Config) (*AzBlobBackend, error) {
+	if cfg.ContainerName == "" {
+		return nil, errors.New("No Azure storage container provided")
+	}
+
+	client := cfg.Client
+	if client == nil {
+		if cfg.AccountName == "" || cfg.Credential == nil {
+			return nil, errors.New("An Azure storage account and credential are required without a client")
+		}
+
+		var err error
+		client, err = azstorage.NewClient(fmt.Sprintf("https://%s.blob.core.windows.net/", cfg.AccountName), cfg.Credential, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	b := &AzBlobBackend{
+		client:    client,
+		container: cfg.ContainerName,
+		timeout:   cfg.Timeout,
+	}
+	if b.timeout == 0 {
+		b.timeout = 30 * time.Second
+	}
+	return b, nil
+}
+
+func (b *AzBlobBackend) context() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), b.timeout)
+}
+
+func (b *AzBlobBackend) Get(key string) (keyring.Item, error) {
+	ctx, cancel := b.context()
+	defer cancel()
+
+	resp, err := b.client.DownloadStream(ctx, b.container, key, nil)
+	if bloberror.HasCode(err, bloberror.BlobNotFound, bloberror.ContainerNotFound) {
+		return keyring.Item{}, keyring.ErrKeyNotFound
+	} else if err != nil {
+		return keyring.Item{}, keyring.WrapError(err, Backend, b.container, key)
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return keyring.Item{}, keyring.WrapError(err, Backend, b.container, key)
+	}
+
+	item, err := decodeMetadata(key, resp.Metadata)
+	if err != nil {
+		return keyring.Item{}, err
+	}
+	item.Data = data
+	return item, nil
+}
+
+func (b *AzBlobBackend) GetMetadata(key string) (keyring.Metadata, error) {
+	ctx, cancel := b.context()
+	defer cancel()
+
+	blob := b.client.ServiceClient().NewContainerClient(b.container).NewBlobClient(key)
+	props, err := blob.GetProperties(ctx, nil)
+	if bloberror.HasCode(err, bloberror.BlobNotFound, bloberror.ContainerNotFound) {
+		return keyring.Metadata{}, keyring.ErrKeyNotFound
+	} else if err != nil {
+		return keyring.Metadata{}, keyring.WrapError(err, Backend, b.container, key)
+	}
+
+	item, err := decodeMetadata(key, props.Metadata)
+	if err != nil {
+		return keyring.Metadata{}, err
+	}
+
+	md := keyring.Metadata{Item: &item}
+	if props.LastModified != nil {
+		md.ModificationTime = *props.LastModified
+	}
+	return md, nil
+}
+
+func (b *AzBlobBackend) Set(item keyring.Item) error {
+	if err := keyring.ValidateRotationSchedule(item); err != nil {
+		return err
+	}
+
+	metadata, err := encodeMetadata(item)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := b.context()
+	defer cancel()
+
+	upload := func() error {
+		_, err := b.client.UploadStream(ctx, b.container, item.Key, bytes.NewReader(item.Data), &azstorage.UploadStreamOptions{
+			Metadata: metadata,
+		})
+		return err
+	}
+
+	err = upload()
+	if bloberror.HasCode(err, bloberror.ContainerNotFound) {
+		keyring.Debugf("Creating container %q", b.container)
+		if _, err = b.client.CreateContainer(ctx, b.container, nil); err != nil && !bloberror.HasCode(err, bloberror.ContainerAlreadyExists) {
+			return keyring.WrapError(err, Backend, b.container, item.Key)
+		}
+		err = upload()
+	}
+	return keyring.WrapError(err, Backend, b.container, item.Key)
+}
+
+func (b *AzBlobBackend) Remove(key string) error {
+	ctx, cancel := b.context()
+	defer cancel()
+
+	_, err := b.client.DeleteBlob(ctx, b.container, key, nil)
+	if bloberror.HasCode(err, bloberror.BlobNotFound, bloberror.ContainerNotFound) {
+		return keyring.ErrKeyNotFound
+	}
+	return keyring.WrapError(err, Backend, b.container, key)
+}
+
+func (b *AzBlobBackend) Keys() ([]string, error) {
+	ctx, cancel := b.context()
+	defer cancel()
+
+	var keys = []string{}
+	pager := b.client.NewListBlobsFlatPager(b.container, nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if bloberror.HasCode(err, bloberror.ContainerNotFound) {
+			return keys, nil
+		} else if err != nil {
+			return nil, keyring.WrapError(err, Backend, b.container, "")
+		}
+
+		for _, blob := range page.Segment.BlobItems {
+			if blob.Name != nil {
+				keys = append(keys, *blob.Name)
+			}
+		}
+	}
+	return keys, nil
+}
+
+// encodeMetadata returns the blob metadata for item's label, description and tags
+func encodeMetadata(item keyring.Item) (map[string]*string, error) {
+	metadata := map[string]*string{}
+	set := func(name, value string) {
+		if value != "" {
+			escaped := url.QueryEscape(value)
+			metadata[name] = &escaped
+		}
+	}
+
+	set(metaLabel, item.Label)
+	set(metaDescription, item.Description)
+	if len(item.Tags) > 0 {
+		tags, err := json.Marshal(item.Tags)
+		if err != nil {
+			return nil, err
+		}
+		set(metaTags, string(tags))
+	}
+	return metadata, nil
+}
+
+// decodeMetadata returns an item without data from blob metadata written by
+// encodeMetadata. The service returns metadata names with their case changed, so they're
+// compared case insensitively.
+func decodeMetadata(key string, metadata map[string]*string) (keyring.Item, error) {
+	item := keyring.Item{Key: key}
+	for name, value := range metadata {
+		if value == nil {
+			continue
+		}
+
+		v, err := url.QueryUnescape(*value)
+		if err != nil {
+			return keyring.Item{}, keyring.WrapError(err, Backend, "", key)
+		}
+
+		switch strings.ToLower(name) {
+		case metaLabel:
+			item.Label = v
+		case metaDescription:
+			item.Description = v
+		case metaTags:
+			if err = json.Unmarshal([]byte(v), &item.Tags); err != nil {
+				return keyring.Item{}, keyring.WrapError(err, Backend, "", key)
+			}
+		}
+	}
+	return item, nil
+}
@@ -0,0 +1,106 @@
+package azblob
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/99designs/keyring"
+	azstorage "github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+)
+
+func TestMetadataRoundTrip(t *testing.T) {
+	item := keyring.Item{
+		Key:         "llamas",
+		Label:       "Llamas & alpacas",
+		Description: "Über secret",
+		Tags:        map[string]string{"env": "prod"},
+	}
+
+	metadata, err := encodeMetadata(item)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The service returns metadata names canonicalised as HTTP headers
+	returned := map[string]*string{}
+	for name, value := range metadata {
+		returned[string(name[0]-'a'+'A')+name[1:]] = value
+	}
+
+	got, err := decodeMetadata(item.Key, returned)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, item) {
+		t.Fatalf("Expected %#v, got %#v", item, got)
+	}
+}
+
+func TestNewRequiresContainer(t *testing.T) {
+	if _, err := New(Config{AccountName: "llamas"}); err == nil {
+		t.Fatal("Expected an error without a container")
+	}
+}
+
+// TestAzBlobBackend runs against a storage account, such as the Azurite emulator, when
+// AZURE_STORAGE_CONNECTION_STRING is set
+func TestAzBlobBackend(t *testing.T) {
+	connStr := os.Getenv("AZURE_STORAGE_CONNECTION_STRING")
+	if connStr == "" {
+		t.Skip("AZURE_STORAGE_CONNECTION_STRING is not set")
+	}
+
+	client, err := azstorage.NewClientFromConnectionString(connStr, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := New(Config{ContainerName: "keyring-test", Client: client})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	item := keyring.Item{Key: "llamas/alpacas", Data: []byte("llamas are great"), Label: "Llamas", Tags: map[string]string{"env": "test"}}
+	if err = b.Set(item); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := b.Get(item.Key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, item) {
+		t.Fatalf("Expected %#v, got %#v", item, got)
+	}
+
+	md, err := b.GetMetadata(item.Key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if md.Label != item.Label || md.ModificationTime.IsZero() {
+		t.Fatalf("Unexpected metadata: %+v", md)
+	}
+
+	keys, err := b.Keys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, k := range keys {
+		found = found || k == item.Key
+	}
+	if !found {
+		t.Fatalf("Expected %q in keys %v", item.Key, keys)
+	}
+
+	if err = b.Remove(item.Key); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = b.Get(item.Key); err != keyring.ErrKeyNotFound {
+		t.Fatalf("Expected ErrKeyNotFound, got: %v", err)
+	}
+	if err = b.Remove(item.Key); err != keyring.ErrKeyNotFound {
+		t.Fatalf("Expected ErrKeyNotFound, got: %v", err)
+	}
+}
@@ -0,0 +1,76 @@
+package keyring
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// DefaultBulkConcurrency is the number of keys BulkRemove removes at once when no
+// concurrency is given
+const DefaultBulkConcurrency = 10
+
+// BulkRemover is implemented by backends that can delete many items in one request
+type BulkRemover interface {
+	BulkRemove(keys []string) (removed int, err error)
+}
+
+// MultiError collects the errors from an operation on many items
+type MultiError struct {
+	Errs []error
+}
+
+func (e *MultiError) Error() string {
+	msgs := make([]string, len(e.Errs))
+	for idx, err := range e.Errs {
+		msgs[idx] = err.Error()
+	}
+	return fmt.Sprintf("%d errors: %s", len(e.Errs), strings.Join(msgs, ", "))
+}
+
+// BulkRemove removes keys from kr, returning how many were removed. Backends implementing
+// BulkRemover use their native batch delete. For all others, up to concurrency keys are
+// removed at once, defaulting to DefaultBulkConcurrency. A failure doesn't stop the
+// remaining keys from being removed; the errors are returned together as a MultiError.
+func BulkRemove(kr Keyring, keys []string, concurrency int) (removed int, err error) {
+	if br, ok := kr.(BulkRemover); ok {
+		return br.BulkRemove(keys)
+	}
+	if concurrency <= 0 {
+		concurrency = DefaultBulkConcurrency
+	}
+
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		errs []error
+		sem  = make(chan struct{}, concurrency)
+	)
+	for _, key := range keys {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(key string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			err := kr.Remove(key)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", key, err))
+				return
+			}
+			removed++
+		}(key)
+	}
+	wg.Wait()
+
+	Debugf("Removed %d of %d keys", removed, len(keys))
+	if len(errs) > 0 {
+		return removed, &MultiError{Errs: errs}
+	}
+	return removed, nil
+}
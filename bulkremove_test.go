@@ -0,0 +1,53 @@
+package keyring
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+// lockedKeyring serialises access to an ArrayKeyring and fails to remove "guanacos"
+type lockedKeyring struct {
+	mu sync.Mutex
+	*ArrayKeyring
+}
+
+var errGuanacos = errors.New("guanacos can't be removed")
+
+func (k *lockedKeyring) Remove(key string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if key == "guanacos" {
+		return errGuanacos
+	}
+	return k.ArrayKeyring.Remove(key)
+}
+
+func TestBulkRemove(t *testing.T) {
+	k := &lockedKeyring{ArrayKeyring: NewArrayKeyring([]Item{
+		{Key: "llamas"},
+		{Key: "alpacas"},
+		{Key: "vicunas"},
+	})}
+
+	removed, err := BulkRemove(k, []string{"llamas", "guanacos", "alpacas"}, 2)
+	if removed != 2 {
+		t.Fatalf("Expected 2 keys removed, got %d", removed)
+	}
+
+	var multi *MultiError
+	if !errors.As(err, &multi) || len(multi.Errs) != 1 {
+		t.Fatalf("Expected a MultiError with 1 error, got: %v", err)
+	}
+	if !errors.Is(multi.Errs[0], errGuanacos) {
+		t.Fatalf("Unexpected error: %v", multi.Errs[0])
+	}
+
+	keys, err := k.Keys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 1 || keys[0] != "vicunas" {
+		t.Fatalf("Unexpected keys left: %v", keys)
+	}
+}
@@ -0,0 +1,187 @@
+package keyring
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ChainWritePolicy controls which keyrings in a chain Set and Remove write to
+type ChainWritePolicy string
+
+const (
+	// WriteAll writes to every keyring in the chain, even if some of them fail
+	WriteAll ChainWritePolicy = "all"
+	// WritePrimary only writes to the first keyring in the chain
+	WritePrimary ChainWritePolicy = "primary"
+	// WriteOnSuccess writes to the first keyring, and only if that succeeds, to the rest
+	WriteOnSuccess ChainWritePolicy = "on-success"
+)
+
+// ChainError is returned by a chained keyring when writing to some of its keyrings failed.
+// Errs holds the error from each keyring in the chain, or nil if it succeeded or wasn't
+// written to.
+type ChainError struct {
+	Op   string
+	Errs []error
+}
+
+func (e *ChainError) Error() string {
+	var msgs []string
+	for idx, err := range e.Errs {
+		if err != nil {
+			msgs = append(msgs, fmt.Sprintf("keyring %d: %v", idx, err))
+		}
+	}
+	return fmt.Sprintf("%s failed on %s", e.Op, strings.Join(msgs, ", "))
+}
+
+type chainKeyring struct {
+	krs    []Keyring
+	policy ChainWritePolicy
+}
+
+// NewChainKeyring chains krs together, reading from them in order and writing to them
+// according to policy, which defaults to WriteAll. Get and GetMetadata return the first
+// successful result and Keys lists the keys from every keyring. This is useful when
+// migrating between backends: put the new backend first so it's read from, and keep the
+// old one in sync until it's retired.
+func NewChainKeyring(policy ChainWritePolicy, krs ...Keyring) Keyring {
+	if policy == "" {
+		policy = WriteAll
+	}
+	return &chainKeyring{krs: krs, policy: policy}
+}
+
+// OpenChain opens every backend in cfg.AllowedBackends and chains them in that order using
+// cfg.ChainWritePolicy. Unlike Open, it fails if any of the backends can't be opened.
+func OpenChain(cfg Config) (Keyring, error) {
+	if len(cfg.AllowedBackends) == 0 {
+		return nil, fmt.Errorf("No backends to chain")
+	}
+	if err := validateKeyPrefix(cfg.KeyPrefix); err != nil {
+		return nil, err
+	}
+
+	// The backends are opened without wrap, which is applied once around the whole chain
+	var krs []Keyring
+	for _, backend := range cfg.AllowedBackends {
+		opener, ok := supportedBackends[backend]
+		if !ok {
+			return nil, fmt.Errorf("Failed to open %s backend: %v", backend, ErrNoAvailImpl)
+		}
+		kr, err := opener(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to open %s backend: %v", backend, err)
+		}
+		krs = append(krs, kr)
+	}
+
+	return wrap(NewChainKeyring(cfg.ChainWritePolicy, krs...), cfg), nil
+}
+
+func (k *chainKeyring) Get(key string) (Item, error) {
+	var firstErr error
+	for idx, kr := range k.krs {
+		item, err := kr.Get(key)
+		if err == nil {
+			return item, nil
+		}
+		Debugf("Failed to get %q from keyring %d in chain: %v", key, idx, err)
+		if firstErr == nil || firstErr == ErrKeyNotFound {
+			firstErr = err
+		}
+	}
+	if firstErr == nil {
+		firstErr = ErrKeyNotFound
+	}
+	return Item{}, firstErr
+}
+
+func (k *chainKeyring) GetMetadata(key string) (Metadata, error) {
+	var firstErr error
+	for _, kr := range k.krs {
+		md, err := kr.GetMetadata(key)
+		if err == nil {
+			return md, nil
+		}
+		if firstErr == nil || firstErr == ErrKeyNotFound {
+			firstErr = err
+		}
+	}
+	if firstErr == nil {
+		firstErr = ErrKeyNotFound
+	}
+	return Metadata{}, firstErr
+}
+
+// write calls fn for the keyrings selected by the write policy, returning the error from
+// each keyring and how many were written to
+func (k *chainKeyring) write(fn func(kr Keyring) error) ([]error, int) {
+	errs := make([]error, len(k.krs))
+	for idx, kr := range k.krs {
+		errs[idx] = fn(kr)
+		if idx == 0 && (k.policy == WritePrimary || (k.policy == WriteOnSuccess && errs[idx] != nil)) {
+			return errs, 1
+		}
+	}
+	return errs, len(k.krs)
+}
+
+func (k *chainKeyring) Set(item Item) error {
+	errs, _ := k.write(func(kr Keyring) error {
+		return kr.Set(item)
+	})
+
+	for _, err := range errs {
+		if err != nil {
+			return &ChainError{Op: OpSet, Errs: errs}
+		}
+	}
+	return nil
+}
+
+// Remove succeeds if the item was removed from at least one keyring, and none of the
+// others failed for a reason other than not having it
+func (k *chainKeyring) Remove(key string) error {
+	errs, written := k.write(func(kr Keyring) error {
+		return kr.Remove(key)
+	})
+
+	removed, failed := false, false
+	for idx, err := range errs[:written] {
+		switch err {
+		case nil:
+			removed = true
+		case ErrKeyNotFound:
+			errs[idx] = nil
+		default:
+			failed = true
+		}
+	}
+
+	if failed {
+		return &ChainError{Op: OpRemove, Errs: errs}
+	} else if !removed {
+		return ErrKeyNotFound
+	}
+	return nil
+}
+
+// Keys lists the keys from every keyring in the chain, without duplicates
+func (k *chainKeyring) Keys() ([]string, error) {
+	seen := map[string]bool{}
+	var keys = []string{}
+	for _, kr := range k.krs {
+		krKeys, err := kr.Keys()
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range krKeys {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	return keys, nil
+}
@@ -0,0 +1,127 @@
+package keyring
+
+import (
+	"errors"
+	"testing"
+)
+
+type failingKeyring struct {
+	*ArrayKeyring
+	err error
+}
+
+func (k failingKeyring) Set(_ Item) error {
+	return k.err
+}
+
+func TestChainKeyringReadsInOrder(t *testing.T) {
+	primary := NewArrayKeyring([]Item{{Key: "llamas", Data: []byte("new")}})
+	secondary := NewArrayKeyring([]Item{
+		{Key: "llamas", Data: []byte("old")},
+		{Key: "alpacas", Data: []byte("old")},
+	})
+	k := NewChainKeyring(WriteAll, primary, secondary)
+
+	item, err := k.Get("llamas")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(item.Data) != "new" {
+		t.Fatalf("Expected the primary's value, got: %q", item.Data)
+	}
+
+	if _, err = k.Get("alpacas"); err != nil {
+		t.Fatalf("Expected to fall back to the secondary, got: %v", err)
+	}
+	if _, err = k.Get("vicunas"); err != ErrKeyNotFound {
+		t.Fatalf("Expected ErrKeyNotFound, got: %v", err)
+	}
+
+	keys, err := k.Keys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 2 {
+		t.Fatalf("Expected keys without duplicates, got: %v", keys)
+	}
+}
+
+func TestChainKeyringWritePolicies(t *testing.T) {
+	errDenied := errors.New("permission denied")
+
+	for _, tc := range []struct {
+		policy          ChainWritePolicy
+		primaryErr      error
+		expectSecondary bool
+		expectErr       bool
+	}{
+		{WriteAll, nil, true, false},
+		{WriteAll, errDenied, true, true},
+		{WritePrimary, nil, false, false},
+		{WriteOnSuccess, nil, true, false},
+		{WriteOnSuccess, errDenied, false, true},
+	} {
+		var primary Keyring = &ArrayKeyring{}
+		if tc.primaryErr != nil {
+			primary = failingKeyring{&ArrayKeyring{}, tc.primaryErr}
+		}
+		secondary := &ArrayKeyring{}
+		k := NewChainKeyring(tc.policy, primary, secondary)
+
+		err := k.Set(Item{Key: "llamas"})
+		if (err != nil) != tc.expectErr {
+			t.Fatalf("%s: unexpected error: %v", tc.policy, err)
+		}
+		if chainErr, ok := err.(*ChainError); ok && chainErr.Errs[0] != errDenied {
+			t.Fatalf("%s: expected the primary's error, got: %v", tc.policy, chainErr)
+		}
+
+		_, err = secondary.Get("llamas")
+		if (err == nil) != tc.expectSecondary {
+			t.Fatalf("%s: unexpected secondary state: %v", tc.policy, err)
+		}
+	}
+}
+
+func TestOpenChainWrapsOnce(t *testing.T) {
+	cfg := Config{
+		AllowedBackends:  []BackendType{FileBackend},
+		FileDir:          t.TempDir(),
+		FilePasswordFunc: fixedStringPrompt("no more secrets"),
+		KeyPrefix:        "app-",
+	}
+
+	chain, err := OpenChain(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = chain.Set(Item{Key: "llamas", Data: []byte("llamas are great")}); err != nil {
+		t.Fatal(err)
+	}
+
+	kr, err := Open(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	item, err := kr.Get("llamas")
+	if err != nil {
+		t.Fatalf("Expected the chained item to be readable through Open: %v", err)
+	}
+	if string(item.Data) != "llamas are great" {
+		t.Fatalf("Unexpected data %q", item.Data)
+	}
+}
+
+func TestChainKeyringRemoveWithFileBackend(t *testing.T) {
+	file := &fileKeyring{dir: t.TempDir(), passwordFunc: fixedStringPrompt("no more secrets")}
+	array := NewArrayKeyring([]Item{{Key: "llamas", Data: []byte("llamas are great")}})
+	k := NewChainKeyring(WriteAll, file, array)
+
+	// Only the old backend has the item, as when migrating
+	if err := k.Remove("llamas"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := array.Get("llamas"); err != ErrKeyNotFound {
+		t.Fatalf("Expected the item to be removed, got: %v", err)
+	}
+}
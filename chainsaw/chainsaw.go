@@ -0,0 +1,134 @@
+// Package chainsaw prunes keyring items that haven't been accessed for a while, as they
+// are likely to be stale.
+//
+// Accesses are recorded in an AccessLog, keyed by the SHA-256 hash of the item key as in
+// audit.AuditEvent, so that the log doesn't reveal secret names. The log is populated by
+// passing NewAccessLogSink to audit.NewAuditKeyring.
+package chainsaw
+
+import (
+	"context"
+	"time"
+
+	"github.com/99designs/keyring"
+	"github.com/99designs/keyring/audit"
+)
+
+// AccessLog records when items were last accessed, by the hash of their key
+type AccessLog interface {
+	// LastAccess returns when the item was last accessed, or the zero time if it's unknown
+	LastAccess(keyHash string) (time.Time, error)
+	// RecordAccess records that the item was accessed at t
+	RecordAccess(keyHash string, t time.Time) error
+}
+
+// Chainsaw finds and removes items that haven't been accessed within a maximum age
+type Chainsaw struct {
+	kr        keyring.Keyring
+	accessLog AccessLog
+	maxAge    time.Duration
+}
+
+// NewChainsaw returns a Chainsaw that prunes items of kr not accessed for maxAge according
+// to accessLog
+func NewChainsaw(kr keyring.Keyring, accessLog AccessLog, maxAge time.Duration) *Chainsaw {
+	return &Chainsaw{kr: kr, accessLog: accessLog, maxAge: maxAge}
+}
+
+// DryRun returns the keys that Prune would remove. Items that have no recorded access
+// are never returned; the current time is recorded for them instead, so that they become
+// stale maxAge after they were first seen.
+func (c *Chainsaw) DryRun(ctx context.Context) ([]string, error) {
+	keys, err := c.kr.Keys()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	cutoff := now.Add(-c.maxAge)
+	var stale = []string{}
+	for _, key := range keys {
+		if err = ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		keyHash := audit.KeyHash(key)
+		last, err := c.accessLog.LastAccess(keyHash)
+		if err != nil {
+			return nil, err
+		}
+		if last.IsZero() {
+			keyring.Debugf("Recording %q as first seen at %s", key, now)
+			if err = c.accessLog.RecordAccess(keyHash, now); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if last.Before(cutoff) {
+			keyring.Debugf("%q was last accessed at %s", key, last)
+			stale = append(stale, key)
+		}
+	}
+	return stale, nil
+}
+
+// Prune removes the keys returned by DryRun, returning how many were removed. Failures
+// don't stop the remaining keys from being removed, and are returned together as a
+// keyring.MultiError.
+func (c *Chainsaw) Prune(ctx context.Context) (int, error) {
+	stale, err := c.DryRun(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if err = ctx.Err(); err != nil {
+		return 0, err
+	}
+	return keyring.BulkRemove(c.kr, stale, 0)
+}
+
+// KeyringAccessLog is an AccessLog stored in a sidecar keyring, with one item per key
+// hash holding the time of the last access
+type KeyringAccessLog struct {
+	kr keyring.Keyring
+}
+
+// NewKeyringAccessLog returns an AccessLog stored in kr, which must not be the keyring
+// being pruned
+func NewKeyringAccessLog(kr keyring.Keyring) *KeyringAccessLog {
+	return &KeyringAccessLog{kr: kr}
+}
+
+func (l *KeyringAccessLog) LastAccess(keyHash string) (time.Time, error) {
+	item, err := l.kr.Get(keyHash)
+	if err == keyring.ErrKeyNotFound {
+		return time.Time{}, nil
+	} else if err != nil {
+		return time.Time{}, err
+	}
+	return time.Parse(time.RFC3339Nano, string(item.Data))
+}
+
+func (l *KeyringAccessLog) RecordAccess(keyHash string, t time.Time) error {
+	return l.kr.Set(keyring.Item{
+		Key:  keyHash,
+		Data: []byte(t.UTC().Format(time.RFC3339Nano)),
+	})
+}
+
+type accessLogSink struct {
+	log AccessLog
+}
+
+// NewAccessLogSink returns an audit.AuditSink that records successful Get and Set
+// operations in accessLog
+func NewAccessLogSink(accessLog AccessLog) audit.AuditSink {
+	return &accessLogSink{log: accessLog}
+}
+
+func (s *accessLogSink) Emit(event audit.AuditEvent) error {
+	if !event.Success || (event.Operation != keyring.OpGet && event.Operation != keyring.OpSet) {
+		return nil
+	}
+	return s.log.RecordAccess(event.KeyHash, event.Timestamp)
+}
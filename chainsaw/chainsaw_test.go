@@ -0,0 +1,96 @@
+package chainsaw
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/99designs/keyring"
+	"github.com/99designs/keyring/audit"
+)
+
+func TestChainsawPrunesStaleKeys(t *testing.T) {
+	backing := &keyring.ArrayKeyring{}
+	accessLog := NewKeyringAccessLog(&keyring.ArrayKeyring{})
+	kr := audit.NewAuditKeyring(backing, NewAccessLogSink(accessLog), "array")
+
+	for _, key := range []string{"llamas", "alpacas", "guanacos"} {
+		if err := kr.Set(keyring.Item{Key: key, Data: []byte(key)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Pretend alpacas were last used two months ago
+	_ = accessLog.RecordAccess(audit.KeyHash("alpacas"), time.Now().Add(-60*24*time.Hour))
+	// guanacos and vicunas were never recorded
+	_ = backing.Remove("guanacos")
+	_ = backing.Set(keyring.Item{Key: "vicunas"})
+
+	c := NewChainsaw(kr, accessLog, 30*24*time.Hour)
+
+	stale, err := c.DryRun(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(stale, []string{"alpacas"}) {
+		t.Fatalf("Expected only alpacas to be stale, got %v", stale)
+	}
+
+	removed, err := c.Prune(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed != 1 {
+		t.Fatalf("Expected 1 item to be removed, got %d", removed)
+	}
+	if _, err = backing.Get("alpacas"); err != keyring.ErrKeyNotFound {
+		t.Fatal("Expected alpacas to be removed")
+	}
+	if _, err = backing.Get("llamas"); err != nil {
+		t.Fatal("Expected llamas to be kept")
+	}
+}
+
+func TestChainsawCancelled(t *testing.T) {
+	kr := &keyring.ArrayKeyring{}
+	_ = kr.Set(keyring.Item{Key: "llamas"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	c := NewChainsaw(kr, NewKeyringAccessLog(&keyring.ArrayKeyring{}), time.Hour)
+	if _, err := c.Prune(ctx); err != context.Canceled {
+		t.Fatalf("Expected context.Canceled, got: %v", err)
+	}
+}
+
+// oldKeyring reports every item as modified long ago
+type oldKeyring struct {
+	*keyring.ArrayKeyring
+}
+
+func (k oldKeyring) GetMetadata(key string) (keyring.Metadata, error) {
+	return keyring.Metadata{ModificationTime: time.Now().Add(-365 * 24 * time.Hour)}, nil
+}
+
+func TestChainsawRecordsFirstSeen(t *testing.T) {
+	kr := oldKeyring{keyring.NewArrayKeyring([]keyring.Item{{Key: "llamas"}})}
+	accessLog := NewKeyringAccessLog(&keyring.ArrayKeyring{})
+
+	c := NewChainsaw(kr, accessLog, 30*24*time.Hour)
+	stale, err := c.DryRun(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stale) != 0 {
+		t.Fatalf("Expected items with no recorded access to be kept, got %v", stale)
+	}
+
+	last, err := accessLog.LastAccess(audit.KeyHash("llamas"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if time.Since(last) > time.Minute {
+		t.Fatalf("Expected llamas to be recorded as first seen now, got %s", last)
+	}
+}
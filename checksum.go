@@ -0,0 +1,133 @@
+package keyring
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ErrChecksumMismatch is returned by Get when an item's data doesn't match its Checksum.
+// It matches ErrCorrupted with errors.Is.
+type ErrChecksumMismatch struct {
+	Key      string
+	Expected string
+	Got      string
+}
+
+func (e *ErrChecksumMismatch) Error() string {
+	return fmt.Sprintf("Item %q has data with checksum %s, expected %s", e.Key, e.Got, e.Expected)
+}
+
+// Is reports whether target is ErrCorrupted
+func (e *ErrChecksumMismatch) Is(target error) bool {
+	return target == ErrCorrupted
+}
+
+// VerifyChecksum returns an *ErrChecksumMismatch if item has a Checksum that doesn't
+// match its data. Items without a Checksum aren't verified.
+func VerifyChecksum(item Item) error {
+	if item.Checksum == "" {
+		return nil
+	}
+	if got := ETag(item.Data); got != item.Checksum {
+		return &ErrChecksumMismatch{Key: item.Key, Expected: item.Checksum, Got: got}
+	}
+	return nil
+}
+
+// checksumSuffix is appended to an item's key to name the sidecar item holding its checksum
+const checksumSuffix = ".sha256"
+
+type checksumKeyring struct {
+	Keyring
+}
+
+// newChecksumKeyring wraps kr so that Set stores a checksum of each item's data, and Get
+// verifies the checksum of items that have one. The checksum is kept in the item's
+// Checksum, and in a sidecar item under the key with checksumSuffix for backends that only
+// store the data. Items without either aren't verified.
+func newChecksumKeyring(kr Keyring) *checksumKeyring {
+	return &checksumKeyring{Keyring: kr}
+}
+
+// expected returns the checksum stored for item, or "" if it has none
+func (k *checksumKeyring) expected(item Item) (string, error) {
+	if item.Checksum != "" {
+		return item.Checksum, nil
+	}
+
+	sidecar, err := k.Keyring.Get(item.Key + checksumSuffix)
+	if err == ErrKeyNotFound {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+	return string(sidecar.Data), nil
+}
+
+func (k *checksumKeyring) Get(key string) (Item, error) {
+	item, err := k.Keyring.Get(key)
+	if err != nil {
+		return item, err
+	}
+
+	if item.Checksum, err = k.expected(item); err != nil {
+		return Item{}, err
+	}
+	if err = VerifyChecksum(item); err != nil {
+		Debugf("Failed to verify %q: %v", key, err)
+		return Item{}, err
+	}
+	return item, nil
+}
+
+// Set stores the item and then its sidecar. The old sidecar is removed first, so that an
+// item whose sidecar can't be written is returned unverified rather than as corrupted.
+func (k *checksumKeyring) Set(item Item) error {
+	item.Checksum = ETag(item.Data)
+
+	sidecarKey := item.Key + checksumSuffix
+	if err := k.Keyring.Remove(sidecarKey); err != nil && err != ErrKeyNotFound {
+		return err
+	}
+	if err := k.Keyring.Set(item); err != nil {
+		return err
+	}
+	return k.Keyring.Set(Item{
+		Key:   sidecarKey,
+		Data:  []byte(item.Checksum),
+		Label: item.Label + " (checksum)",
+	})
+}
+
+func (k *checksumKeyring) Remove(key string) error {
+	if err := k.Keyring.Remove(key); err != nil {
+		return err
+	}
+	if err := k.Keyring.Remove(key + checksumSuffix); err != nil && err != ErrKeyNotFound {
+		return err
+	}
+	return nil
+}
+
+func (k *checksumKeyring) Keys() ([]string, error) {
+	keys, err := k.Keyring.Keys()
+	if err != nil {
+		return nil, err
+	}
+
+	all := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		all[key] = true
+	}
+
+	// Only sidecars of stored items are hidden, so that user keys which happen to end in
+	// the suffix are still listed
+	filtered := []string{}
+	for _, key := range keys {
+		if base := strings.TrimSuffix(key, checksumSuffix); base != key && all[base] {
+			continue
+		}
+		filtered = append(filtered, key)
+	}
+	return filtered, nil
+}
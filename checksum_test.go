@@ -0,0 +1,108 @@
+package keyring
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestChecksumKeyringDetectsCorruption(t *testing.T) {
+	backing := &ArrayKeyring{}
+	k := wrap(backing, Config{ItemChecksums: true})
+
+	if err := k.Set(Item{Key: "llamas", Data: []byte("llamas are great")}); err != nil {
+		t.Fatal(err)
+	}
+
+	stored, _ := backing.Get("llamas")
+	if stored.Checksum != ETag([]byte("llamas are great")) {
+		t.Fatalf("Expected the checksum to be stored, got %q", stored.Checksum)
+	}
+	if _, err := k.Get("llamas"); err != nil {
+		t.Fatal(err)
+	}
+
+	stored.Data = []byte("llamas are grebt")
+	_ = backing.Set(stored)
+
+	_, err := k.Get("llamas")
+	if !errors.Is(err, ErrCorrupted) {
+		t.Fatalf("Expected ErrCorrupted, got: %v", err)
+	}
+	var mismatch *ErrChecksumMismatch
+	if !errors.As(err, &mismatch) || mismatch.Expected != stored.Checksum {
+		t.Fatalf("Expected ErrChecksumMismatch, got: %v", err)
+	}
+}
+
+func TestChecksumKeyringSkipsItemsWithoutChecksum(t *testing.T) {
+	backing := &ArrayKeyring{}
+	_ = backing.Set(Item{Key: "llamas", Data: []byte("llamas are great")})
+
+	k := wrap(backing, Config{ItemChecksums: true})
+	if _, err := k.Get("llamas"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// dataOnlyKeyring keeps only the key and data of items, like the macOS Keychain
+type dataOnlyKeyring struct {
+	*ArrayKeyring
+}
+
+func (k dataOnlyKeyring) Set(item Item) error {
+	return k.ArrayKeyring.Set(Item{Key: item.Key, Data: item.Data})
+}
+
+func TestChecksumKeyringUsesSidecarForDataOnlyBackends(t *testing.T) {
+	backing := dataOnlyKeyring{&ArrayKeyring{}}
+	k := wrap(backing, Config{ItemChecksums: true})
+
+	if err := k.Set(Item{Key: "llamas", Data: []byte("llamas are great")}); err != nil {
+		t.Fatal(err)
+	}
+
+	sidecar, err := backing.Get("llamas" + checksumSuffix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(sidecar.Data) != ETag([]byte("llamas are great")) {
+		t.Fatalf("Expected the checksum in the sidecar, got %q", sidecar.Data)
+	}
+
+	keys, err := k.Keys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 1 || keys[0] != "llamas" {
+		t.Fatalf("Expected the sidecar to be hidden, got %v", keys)
+	}
+
+	_ = backing.Set(Item{Key: "llamas", Data: []byte("llamas are grebt")})
+	if _, err = k.Get("llamas"); !errors.Is(err, ErrCorrupted) {
+		t.Fatalf("Expected ErrCorrupted, got: %v", err)
+	}
+
+	if err = k.Remove("llamas"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = backing.Get("llamas" + checksumSuffix); err != ErrKeyNotFound {
+		t.Fatalf("Expected the sidecar to be removed, got: %v", err)
+	}
+}
+
+func TestVerifyChecksum(t *testing.T) {
+	if err := VerifyChecksum(Item{Key: "llamas", Data: []byte("llamas are great")}); err != nil {
+		t.Fatalf("Expected an item without a checksum to pass, got: %v", err)
+	}
+
+	item := Item{Key: "llamas", Data: []byte("llamas are great"), Checksum: ETag([]byte("llamas are great"))}
+	if err := VerifyChecksum(item); err != nil {
+		t.Fatal(err)
+	}
+
+	item.Data = []byte("llamas are grebt")
+	var mismatch *ErrChecksumMismatch
+	if err := VerifyChecksum(item); !errors.As(err, &mismatch) || mismatch.Key != "llamas" {
+		t.Fatalf("Expected ErrChecksumMismatch, got: %v", err)
+	}
+}
@@ -0,0 +1,206 @@
+package keyring
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	chunkSeparator = ".chunk."
+	manifestSuffix = ".manifest"
+)
+
+var chunkKeyPattern = regexp.MustCompile(`\.chunk\.[0-9]+$`)
+
+// chunkManifest describes how an item has been split up
+type chunkManifest struct {
+	Chunks int `json:"chunks"`
+	Size   int `json:"size"`
+}
+
+type chunkedKeyring struct {
+	kr        Keyring
+	chunkSize int
+}
+
+// NewChunkedKeyring wraps kr so that items with more than chunkSize bytes of data are split
+// across several items, for backends that limit the size of what they can store. The data
+// is stored under <key>.chunk.0, <key>.chunk.1 and so on, along with a <key>.manifest item
+// that records how many chunks there are. Smaller items, and items stored before chunking
+// was used, are read and written directly. It panics if chunkSize is less than one.
+func NewChunkedKeyring(kr Keyring, chunkSize int) Keyring {
+	if chunkSize < 1 {
+		panic("keyring: non-positive chunkSize for NewChunkedKeyring")
+	}
+	return &chunkedKeyring{kr: kr, chunkSize: chunkSize}
+}
+
+func chunkKey(key string, idx int) string {
+	return fmt.Sprintf("%s%s%d", key, chunkSeparator, idx)
+}
+
+func (k *chunkedKeyring) manifest(key string) (Item, chunkManifest, error) {
+	var m chunkManifest
+	item, err := k.kr.Get(key + manifestSuffix)
+	if err != nil {
+		return item, m, err
+	}
+	if err = json.Unmarshal(item.Data, &m); err != nil {
+		return item, m, fmt.Errorf("Invalid chunk manifest for %q: %v", key, err)
+	}
+	return item, m, nil
+}
+
+func (k *chunkedKeyring) Get(key string) (Item, error) {
+	mItem, m, err := k.manifest(key)
+	if err == ErrKeyNotFound {
+		return k.kr.Get(key)
+	} else if err != nil {
+		return Item{}, err
+	}
+
+	data := make([]byte, 0, m.Size)
+	for idx := 0; idx < m.Chunks; idx++ {
+		chunk, err := k.kr.Get(chunkKey(key, idx))
+		if err != nil {
+			return Item{}, fmt.Errorf("Failed to read chunk %d of %q: %v", idx, key, err)
+		}
+		data = append(data, chunk.Data...)
+	}
+	if len(data) != m.Size {
+		return Item{}, fmt.Errorf("Chunked item %q is %d bytes, expected %d", key, len(data), m.Size)
+	}
+
+	item := mItem
+	item.Key = key
+	item.Data = data
+	return item, nil
+}
+
+func (k *chunkedKeyring) GetMetadata(key string) (Metadata, error) {
+	md, err := k.kr.GetMetadata(key + manifestSuffix)
+	if err == ErrKeyNotFound {
+		return k.kr.GetMetadata(key)
+	} else if err != nil {
+		return md, err
+	}
+	if md.Item != nil {
+		md.Item.Key = key
+	}
+	return md, nil
+}
+
+func (k *chunkedKeyring) Set(item Item) error {
+	_, old, err := k.manifest(item.Key)
+	if err != nil && err != ErrKeyNotFound {
+		return err
+	}
+	wasChunked := err == nil
+
+	if len(item.Data) <= k.chunkSize {
+		if err = k.kr.Set(item); err != nil {
+			return err
+		}
+		if wasChunked {
+			return k.removeChunks(item.Key, old.Chunks)
+		}
+		return nil
+	}
+
+	m := chunkManifest{Size: len(item.Data)}
+	for offset := 0; offset < len(item.Data); offset += k.chunkSize {
+		end := offset + k.chunkSize
+		if end > len(item.Data) {
+			end = len(item.Data)
+		}
+		chunk := item
+		chunk.Key = chunkKey(item.Key, m.Chunks)
+		chunk.Data = item.Data[offset:end]
+		if err = k.kr.Set(chunk); err != nil {
+			return fmt.Errorf("Failed to write chunk %d of %q: %v", m.Chunks, item.Key, err)
+		}
+		m.Chunks++
+	}
+
+	manifest := item
+	manifest.Key = item.Key + manifestSuffix
+	if manifest.Data, err = json.Marshal(m); err != nil {
+		return err
+	}
+	if err = k.kr.Set(manifest); err != nil {
+		return err
+	}
+	Debugf("Stored %q as %d chunks", item.Key, m.Chunks)
+
+	// Clean up anything the new manifest has superseded
+	if !wasChunked {
+		if err = k.kr.Remove(item.Key); err != nil && err != ErrKeyNotFound {
+			return err
+		}
+	}
+	for idx := m.Chunks; idx < old.Chunks; idx++ {
+		if err = k.kr.Remove(chunkKey(item.Key, idx)); err != nil && err != ErrKeyNotFound {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// removeChunks removes all the chunks and the manifest of a chunked item, carrying on past
+// failures so as much as possible is cleaned up, and returns the first error encountered
+func (k *chunkedKeyring) removeChunks(key string, chunks int) error {
+	var firstErr error
+	for idx := 0; idx < chunks; idx++ {
+		if err := k.kr.Remove(chunkKey(key, idx)); err != nil && err != ErrKeyNotFound && firstErr == nil {
+			firstErr = err
+		}
+	}
+	if err := k.kr.Remove(key + manifestSuffix); err != nil && firstErr == nil {
+		firstErr = err
+	}
+	return firstErr
+}
+
+func (k *chunkedKeyring) Remove(key string) error {
+	_, m, err := k.manifest(key)
+	if err == ErrKeyNotFound {
+		return k.kr.Remove(key)
+	} else if err != nil {
+		return err
+	}
+	return k.removeChunks(key, m.Chunks)
+}
+
+func (k *chunkedKeyring) Keys() ([]string, error) {
+	keys, err := k.kr.Keys()
+	if err != nil {
+		return nil, err
+	}
+
+	all := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		all[key] = true
+	}
+
+	// Only keys that belong to a chunked item are hidden, so that user keys which happen to
+	// end in one of the suffixes are still listed
+	seen := map[string]bool{}
+	filtered := []string{}
+	for _, key := range keys {
+		if loc := chunkKeyPattern.FindStringIndex(key); loc != nil && all[key[:loc[0]]+manifestSuffix] {
+			continue
+		}
+		if base := strings.TrimSuffix(key, manifestSuffix); base != key && all[chunkKey(base, 0)] {
+			key = base
+		}
+		if !seen[key] {
+			seen[key] = true
+			filtered = append(filtered, key)
+		}
+	}
+
+	return filtered, nil
+}
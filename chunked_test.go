@@ -0,0 +1,130 @@
+package keyring
+
+import (
+	"bytes"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestChunkedKeyringSplitsLargeItems(t *testing.T) {
+	backing := &ArrayKeyring{}
+	k := NewChunkedKeyring(backing, 4)
+	data := []byte("llamas are great")
+
+	if err := k.Set(Item{Key: "llamas", Data: data, Label: "Llamas"}); err != nil {
+		t.Fatal(err)
+	}
+
+	rawKeys, _ := backing.Keys()
+	if len(rawKeys) != 5 {
+		t.Fatalf("Expected 4 chunks and a manifest, got: %v", rawKeys)
+	}
+
+	foundItem, err := k.Get("llamas")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(foundItem.Data, data) {
+		t.Fatalf("Value stored was not the value retrieved: %q", foundItem.Data)
+	}
+	if foundItem.Key != "llamas" || foundItem.Label != "Llamas" {
+		t.Fatalf("Item details weren't persisted: %+v", foundItem)
+	}
+
+	keys, err := k.Keys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(keys, []string{"llamas"}) {
+		t.Fatalf("Expected chunk keys to be hidden, got: %v", keys)
+	}
+
+	if err = k.Remove("llamas"); err != nil {
+		t.Fatal(err)
+	}
+	if rawKeys, _ = backing.Keys(); len(rawKeys) != 0 {
+		t.Fatalf("Expected all chunks to be removed, got: %v", rawKeys)
+	}
+}
+
+func TestChunkedKeyringSmallItems(t *testing.T) {
+	backing := NewArrayKeyring([]Item{{Key: "alpacas", Data: []byte("pre-existing")}})
+	k := NewChunkedKeyring(backing, 64)
+
+	if err := k.Set(Item{Key: "llamas", Data: []byte("llamas are great")}); err != nil {
+		t.Fatal(err)
+	}
+
+	keys, _ := backing.Keys()
+	sort.Strings(keys)
+	if !reflect.DeepEqual(keys, []string{"alpacas", "llamas"}) {
+		t.Fatalf("Expected small items to be stored directly, got: %v", keys)
+	}
+
+	foundItem, err := k.Get("alpacas")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(foundItem.Data) != "pre-existing" {
+		t.Fatalf("Unexpected value for unchunked item: %q", foundItem.Data)
+	}
+}
+
+func TestChunkedKeyringShrinkingItem(t *testing.T) {
+	backing := &ArrayKeyring{}
+	k := NewChunkedKeyring(backing, 4)
+
+	if err := k.Set(Item{Key: "llamas", Data: []byte("llamas are great")}); err != nil {
+		t.Fatal(err)
+	}
+	if err := k.Set(Item{Key: "llamas", Data: []byte("ok")}); err != nil {
+		t.Fatal(err)
+	}
+
+	keys, _ := backing.Keys()
+	if !reflect.DeepEqual(keys, []string{"llamas"}) {
+		t.Fatalf("Expected old chunks to be removed, got: %v", keys)
+	}
+}
+
+func TestChunkedKeyringOverFileBackend(t *testing.T) {
+	backing := &fileKeyring{dir: t.TempDir(), passwordFunc: fixedStringPrompt("no more secrets")}
+	k := NewChunkedKeyring(backing, 10)
+	data := bytes.Repeat([]byte("a"), 35)
+
+	if err := k.Set(Item{Key: "big", Data: data}); err != nil {
+		t.Fatal(err)
+	}
+	item, err := k.Get("big")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(item.Data, data) {
+		t.Fatalf("Value stored was not the value retrieved: %q", item.Data)
+	}
+
+	if err = k.Remove("big"); err != nil {
+		t.Fatal(err)
+	}
+	if err = k.Remove("big"); err != ErrKeyNotFound {
+		t.Fatalf("Expected ErrKeyNotFound, got: %v", err)
+	}
+}
+
+func TestChunkedKeyringKeysEndingInManifest(t *testing.T) {
+	backing := NewArrayKeyring([]Item{{Key: "deploy.manifest"}, {Key: "release.chunk.1"}})
+	k := NewChunkedKeyring(backing, 4)
+	if err := k.Set(Item{Key: "llamas", Data: []byte("llamas are great")}); err != nil {
+		t.Fatal(err)
+	}
+
+	keys, err := k.Keys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(keys)
+	if !reflect.DeepEqual(keys, []string{"deploy.manifest", "llamas", "release.chunk.1"}) {
+		t.Fatalf("Expected user keys to be listed as they are, got: %v", keys)
+	}
+}
@@ -0,0 +1,142 @@
+package keyring
+
+import (
+	"os"
+	"os/signal"
+	"runtime"
+	"sync"
+	"syscall"
+)
+
+// ClearOnExitKeyring remembers the items created through it so that they can be removed
+// again before the process exits. Keyrings opened with Config.ClearOnExit are wrapped in
+// one, and clear their items on SIGINT or SIGTERM. Neither signals nor finalizers are
+// guaranteed to run, so callers that return normally should also clear explicitly:
+//
+//	kr, err := keyring.Open(keyring.Config{ClearOnExit: true})
+//	...
+//	defer kr.(*keyring.ClearOnExitKeyring).ClearCreated()
+type ClearOnExitKeyring struct {
+	Keyring
+	created *createdKeys
+}
+
+// createdKeys is kept apart from ClearOnExitKeyring so that the signal handler doesn't
+// stop the keyring from being garbage collected and its finalizer from running
+type createdKeys struct {
+	kr   Keyring
+	mu   sync.Mutex
+	keys map[string]struct{}
+}
+
+// NewClearOnExitKeyring wraps kr, recording the keys of items that didn't exist before
+// they were Set so that ClearCreated can remove them. It doesn't install a signal handler.
+func NewClearOnExitKeyring(kr Keyring) *ClearOnExitKeyring {
+	k := &ClearOnExitKeyring{
+		Keyring: kr,
+		created: &createdKeys{
+			kr:   kr,
+			keys: map[string]struct{}{},
+		},
+	}
+	runtime.SetFinalizer(k, func(k *ClearOnExitKeyring) {
+		_ = k.created.clear()
+	})
+	return k
+}
+
+// clearOnExit is used by Open for Config.ClearOnExit
+func clearOnExit(kr Keyring) *ClearOnExitKeyring {
+	k := NewClearOnExitKeyring(kr)
+	k.created.handleSignals()
+	return k
+}
+
+// Set stores item, remembering its key if it wasn't already on the keyring
+func (k *ClearOnExitKeyring) Set(item Item) error {
+	k.created.mu.Lock()
+	defer k.created.mu.Unlock()
+
+	_, tracked := k.created.keys[item.Key]
+	if !tracked {
+		keys, err := k.Keyring.Keys()
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			if key == item.Key {
+				// Pre-existing items are never removed
+				tracked = true
+				Debugf("Not clearing %q on exit as it already exists", item.Key)
+				break
+			}
+		}
+	}
+
+	if err := k.Keyring.Set(item); err != nil {
+		return err
+	}
+	if !tracked {
+		k.created.keys[item.Key] = struct{}{}
+	}
+	return nil
+}
+
+// Remove removes the item with matching key, forgetting that it was created
+func (k *ClearOnExitKeyring) Remove(key string) error {
+	k.created.mu.Lock()
+	defer k.created.mu.Unlock()
+
+	if err := k.Keyring.Remove(key); err != nil {
+		return err
+	}
+	delete(k.created.keys, key)
+	return nil
+}
+
+// ClearCreated removes every item created through k, returning the first error
+// encountered. Items that have already gone are ignored.
+func (k *ClearOnExitKeyring) ClearCreated() error {
+	return k.created.clear()
+}
+
+func (c *createdKeys) clear() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var firstErr error
+	for key := range c.keys {
+		Debugf("Clearing %q", key)
+		if err := c.kr.Remove(key); err != nil && err != ErrKeyNotFound {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		delete(c.keys, key)
+	}
+
+	return firstErr
+}
+
+// handleSignals clears created items on SIGINT or SIGTERM, then delivers the signal
+// again so that the process exits as it would have otherwise
+func (c *createdKeys) handleSignals() {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		sig := <-ch
+		Debugf("Received %s, clearing created items", sig)
+		_ = c.clear()
+		signal.Stop(ch)
+
+		p, err := os.FindProcess(os.Getpid())
+		if err == nil {
+			err = p.Signal(sig)
+		}
+		if err != nil {
+			os.Exit(1)
+		}
+	}()
+}
@@ -0,0 +1,50 @@
+package keyring
+
+import (
+	"testing"
+)
+
+func TestClearOnExitKeyringClearsCreatedItems(t *testing.T) {
+	backing := &ArrayKeyring{}
+	if err := backing.Set(Item{Key: "existing", Data: []byte("keep me")}); err != nil {
+		t.Fatal(err)
+	}
+
+	k := NewClearOnExitKeyring(backing)
+	for _, key := range []string{"existing", "llamas", "alpacas", "removed"} {
+		if err := k.Set(Item{Key: key, Data: []byte("llamas are great")}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := k.Remove("removed"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := k.ClearCreated(); err != nil {
+		t.Fatal(err)
+	}
+
+	keys, err := backing.Keys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 1 || keys[0] != "existing" {
+		t.Fatalf("Expected only the pre-existing item to remain, got: %v", keys)
+	}
+}
+
+func TestClearOnExitKeyringIgnoresItemsAlreadyRemoved(t *testing.T) {
+	backing := &ArrayKeyring{}
+	k := NewClearOnExitKeyring(backing)
+
+	if err := k.Set(Item{Key: "llamas"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := backing.Remove("llamas"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := k.ClearCreated(); err != nil {
+		t.Fatalf("Expected an item that's already gone to be ignored, got: %v", err)
+	}
+}
@@ -19,6 +19,7 @@ func main() {
 	// actions to take
 	actionListKeys := flag.Bool("list-keys", false, "Whether to list backends")
 	actionSetValue := flag.String("set", "", "The value to set")
+	actionConvertTo := flag.String("convert-to", "", "A backend to copy all keys from -backend into")
 
 	// keychain
 	keychainName := flag.String("keychain", "login", "The keychain to search")
@@ -48,11 +49,28 @@ func main() {
 		allowedBackends = keyring.AvailableBackends()
 	}
 
-	ring, err := keyring.Open(keyring.Config{
+	cfg := keyring.Config{
 		ServiceName:     *serviceName,
 		AllowedBackends: allowedBackends,
 		KeychainName:    *keychainName,
-	})
+	}
+
+	// Handle -convert-to
+	if *actionConvertTo != "" {
+		if *backend == "" {
+			log.Fatal("A source backend must be provided with -backend to use -convert-to")
+		}
+		if !hasBackend(*actionConvertTo) {
+			log.Fatalf("Backend %q isn't available. Use -list-backends to see what is.", *actionConvertTo)
+		}
+		err := keyring.ConvertBackend(keyring.BackendType(*backend), keyring.BackendType(*actionConvertTo), cfg)
+		if err != nil {
+			log.Fatal(err)
+		}
+		os.Exit(0)
+	}
+
+	ring, err := keyring.Open(cfg)
 	if err != nil {
 		log.Fatal(err)
 	}
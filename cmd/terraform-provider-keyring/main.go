@@ -0,0 +1,27 @@
+// Command terraform-provider-keyring is a Terraform provider for keyring items
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+
+	"github.com/99designs/keyring/terraform"
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+)
+
+// version is set when building releases, with -ldflags "-X main.version=..."
+var version = "dev"
+
+func main() {
+	debug := flag.Bool("debug", false, "Run the provider with support for debuggers like delve")
+	flag.Parse()
+
+	err := providerserver.Serve(context.Background(), terraform.New(version), providerserver.ServeOpts{
+		Address: "registry.terraform.io/99designs/keyring",
+		Debug:   *debug,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,76 @@
+// Package compare checks that two keyrings hold the same secrets, such as staging and
+// production copies, without revealing the secrets in its results.
+package compare
+
+import (
+	"github.com/99designs/keyring"
+)
+
+// Reasons for a Mismatch
+const (
+	ReasonMissingInA    = "missing in A"
+	ReasonMissingInB    = "missing in B"
+	ReasonMissingInBoth = "missing in A and B"
+	ReasonDataDiffers   = "data differs"
+)
+
+// Mismatch is a key whose item differs between two keyrings. The hashes are the hex
+// encoded SHA-256 of the item data, and are empty when the item is missing.
+type Mismatch struct {
+	Key    string
+	AHash  string
+	BHash  string
+	Reason string
+}
+
+// ContentEqual compares the data of the items under keys in a and b, returning the keys
+// that differ. A nil keys compares every key in a. Only item data is compared; labels and
+// other attributes may differ between backends.
+func ContentEqual(a, b keyring.Keyring, keys []string) ([]Mismatch, error) {
+	if keys == nil {
+		var err error
+		if keys, err = a.Keys(); err != nil {
+			return nil, err
+		}
+	}
+
+	var mismatches = []Mismatch{}
+	for _, key := range keys {
+		aHash, err := dataHash(a, key)
+		if err != nil {
+			return nil, err
+		}
+		bHash, err := dataHash(b, key)
+		if err != nil {
+			return nil, err
+		}
+
+		m := Mismatch{Key: key, AHash: aHash, BHash: bHash}
+		switch {
+		case aHash == "" && bHash == "":
+			m.Reason = ReasonMissingInBoth
+		case aHash == "":
+			m.Reason = ReasonMissingInA
+		case bHash == "":
+			m.Reason = ReasonMissingInB
+		case aHash != bHash:
+			m.Reason = ReasonDataDiffers
+		default:
+			continue
+		}
+		mismatches = append(mismatches, m)
+	}
+	return mismatches, nil
+}
+
+// dataHash returns the hash of the data of the item key in kr, or "" if there isn't one
+func dataHash(kr keyring.Keyring, key string) (string, error) {
+	item, err := kr.Get(key)
+	if err == keyring.ErrKeyNotFound {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+
+	return keyring.ETag(item.Data), nil
+}
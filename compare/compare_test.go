@@ -0,0 +1,59 @@
+package compare
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/99designs/keyring"
+)
+
+func TestContentEqual(t *testing.T) {
+	staging := keyring.NewArrayKeyring([]keyring.Item{
+		{Key: "llamas", Data: []byte("llamas are great"), Label: "Staging llamas"},
+		{Key: "alpacas", Data: []byte("alpacas are great")},
+		{Key: "guanacos", Data: []byte("guanacos")},
+	})
+	production := keyring.NewArrayKeyring([]keyring.Item{
+		{Key: "llamas", Data: []byte("llamas are great"), Label: "Production llamas"},
+		{Key: "alpacas", Data: []byte("alpacas are okay")},
+		{Key: "vicunas", Data: []byte("vicunas")},
+	})
+
+	mismatches, err := ContentEqual(staging, production, []string{"llamas", "alpacas", "guanacos", "vicunas", "camels"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var reasons = map[string]string{}
+	for _, m := range mismatches {
+		reasons[m.Key] = m.Reason
+		if m.Reason == ReasonDataDiffers && (m.AHash == "" || m.AHash == m.BHash) {
+			t.Fatalf("Expected differing hashes, got %+v", m)
+		}
+	}
+	expected := map[string]string{
+		"alpacas":  ReasonDataDiffers,
+		"guanacos": ReasonMissingInB,
+		"vicunas":  ReasonMissingInA,
+		"camels":   ReasonMissingInBoth,
+	}
+	if !reflect.DeepEqual(reasons, expected) {
+		t.Fatalf("Unexpected mismatches: %v", reasons)
+	}
+}
+
+func TestContentEqualAllKeys(t *testing.T) {
+	a := keyring.NewArrayKeyring([]keyring.Item{{Key: "llamas", Data: []byte("llamas")}})
+	b := keyring.NewArrayKeyring([]keyring.Item{
+		{Key: "llamas", Data: []byte("llamas")},
+		{Key: "alpacas", Data: []byte("alpacas")},
+	})
+
+	mismatches, err := ContentEqual(a, b, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mismatches) != 0 {
+		t.Fatalf("Expected keys only in B to be ignored, got %+v", mismatches)
+	}
+}
@@ -0,0 +1,119 @@
+package keyring
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrTimeout is returned by a keyring from NewConcurrentKeyring when an operation can't
+// start before the timeout
+var ErrTimeout = errors.New("Timed out waiting for a free slot for the keyring operation")
+
+// semaphore limits the number of operations in flight. A nil semaphore is unlimited.
+type semaphore chan struct{}
+
+func newSemaphore(n int) semaphore {
+	if n <= 0 {
+		return nil
+	}
+	return make(semaphore, n)
+}
+
+// acquire takes a slot, waiting at most timeout for one to be released. Zero waits forever.
+func (s semaphore) acquire(timeout time.Duration) error {
+	if s == nil {
+		return nil
+	}
+	if timeout <= 0 {
+		s <- struct{}{}
+		return nil
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case s <- struct{}{}:
+		return nil
+	case <-timer.C:
+		return ErrTimeout
+	}
+}
+
+func (s semaphore) release() {
+	if s != nil {
+		<-s
+	}
+}
+
+type concurrentKeyring struct {
+	kr      Keyring
+	reads   semaphore
+	writes  semaphore
+	timeout time.Duration
+}
+
+// NewConcurrentKeyring wraps kr so that at most maxConcurrent operations run at once, for
+// backends such as HSMs that fail under concurrent load. Operations wait for a free slot.
+// Config.MaxReadConcurrent and Config.MaxWriteConcurrent set separate limits for reads
+// and writes.
+func NewConcurrentKeyring(kr Keyring, maxConcurrent int) Keyring {
+	sem := newSemaphore(maxConcurrent)
+	return &concurrentKeyring{kr: kr, reads: sem, writes: sem}
+}
+
+// newConcurrentKeyring limits Get, GetMetadata and Keys to maxRead and Set and Remove to
+// maxWrite operations in flight. Zero means no limit. Operations that can't start within
+// timeout fail with ErrTimeout.
+func newConcurrentKeyring(kr Keyring, maxRead, maxWrite int, timeout time.Duration) *concurrentKeyring {
+	return &concurrentKeyring{
+		kr:      kr,
+		reads:   newSemaphore(maxRead),
+		writes:  newSemaphore(maxWrite),
+		timeout: timeout,
+	}
+}
+
+func (k *concurrentKeyring) run(sem semaphore, op string, key string, fn func() error) error {
+	if err := sem.acquire(k.timeout); err != nil {
+		Debugf("No free slot for %s of %q after %s", op, key, k.timeout)
+		return err
+	}
+	defer sem.release()
+	return fn()
+}
+
+func (k *concurrentKeyring) Get(key string) (item Item, err error) {
+	err = k.run(k.reads, OpGet, key, func() error {
+		item, err = k.kr.Get(key)
+		return err
+	})
+	return item, err
+}
+
+func (k *concurrentKeyring) GetMetadata(key string) (md Metadata, err error) {
+	err = k.run(k.reads, OpGetMetadata, key, func() error {
+		md, err = k.kr.GetMetadata(key)
+		return err
+	})
+	return md, err
+}
+
+func (k *concurrentKeyring) Set(item Item) error {
+	return k.run(k.writes, OpSet, item.Key, func() error {
+		return k.kr.Set(item)
+	})
+}
+
+func (k *concurrentKeyring) Remove(key string) error {
+	return k.run(k.writes, OpRemove, key, func() error {
+		return k.kr.Remove(key)
+	})
+}
+
+func (k *concurrentKeyring) Keys() (keys []string, err error) {
+	err = k.run(k.reads, OpKeys, "", func() error {
+		keys, err = k.kr.Keys()
+		return err
+	})
+	return keys, err
+}
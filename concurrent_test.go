@@ -0,0 +1,88 @@
+package keyring
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// blockingKeyring holds Get and Set until release is closed
+type blockingKeyring struct {
+	ArrayKeyring
+	started chan struct{}
+	release chan struct{}
+}
+
+func (k *blockingKeyring) Get(key string) (Item, error) {
+	k.started <- struct{}{}
+	<-k.release
+	return Item{Key: key}, nil
+}
+
+func (k *blockingKeyring) Set(item Item) error {
+	k.started <- struct{}{}
+	<-k.release
+	return nil
+}
+
+func TestConcurrentKeyringTimesOut(t *testing.T) {
+	backing := &blockingKeyring{started: make(chan struct{}, 10), release: make(chan struct{})}
+	k := wrap(backing, Config{MaxReadConcurrent: 2, MaxWriteConcurrent: 1, ConcurrencyTimeout: 50 * time.Millisecond})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = k.Get("llamas")
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_ = k.Set(Item{Key: "llamas"})
+	}()
+	for i := 0; i < 3; i++ {
+		<-backing.started
+	}
+
+	if _, err := k.Get("alpacas"); err != ErrTimeout {
+		t.Fatalf("Expected ErrTimeout for a third read, got: %v", err)
+	}
+	if err := k.Remove("alpacas"); err != ErrTimeout {
+		t.Fatalf("Expected ErrTimeout for a second write, got: %v", err)
+	}
+
+	close(backing.release)
+	wg.Wait()
+
+	if _, err := k.Get("alpacas"); err != nil {
+		t.Fatalf("Expected a free slot once operations finished, got: %v", err)
+	}
+}
+
+func TestNewConcurrentKeyringWaitsForSlot(t *testing.T) {
+	backing := &blockingKeyring{started: make(chan struct{}, 10), release: make(chan struct{})}
+	k := NewConcurrentKeyring(backing, 1)
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		_, _ = k.Get("llamas")
+	}()
+	<-backing.started
+	go func() {
+		defer wg.Done()
+		_ = k.Set(Item{Key: "alpacas"})
+	}()
+
+	select {
+	case <-backing.started:
+		t.Fatal("Expected the Set to wait for the Get to finish")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(backing.release)
+	wg.Wait()
+}
@@ -1,52 +1,227 @@
 package keyring
 
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"time"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
 // Config contains configuration for keyring
 type Config struct {
 	// AllowedBackends is a whitelist of backend providers that can be used. Nil means all available.
-	AllowedBackends []BackendType
+	AllowedBackends []BackendType `yaml:"allowed_backends"`
+
+	// KeyPrefix is prepended to every key before it reaches the backend, and only keys with it are listed
+	KeyPrefix string `yaml:"key_prefix"`
+
+	// SchemaValidators maps key glob patterns to JSON Schemas that the data of matching items must conform to
+	SchemaValidators map[string]JSONSchema `yaml:"schema_validators"`
+
+	// MaxItemDataSize is the largest item data in bytes that Set accepts. Zero means no limit.
+	MaxItemDataSize int `yaml:"max_item_data_size"`
+
+	// ItemChecksums stores a SHA-256 checksum with each item on Set and verifies it on Get
+	ItemChecksums bool `yaml:"item_checksums"`
+
+	// MaxReadConcurrent is the most Get, GetMetadata and Keys operations that run at once. Zero means no limit.
+	MaxReadConcurrent int `yaml:"max_read_concurrent"`
+
+	// MaxWriteConcurrent is the most Set and Remove operations that run at once. Zero means no limit.
+	MaxWriteConcurrent int `yaml:"max_write_concurrent"`
+
+	// ConcurrencyTimeout is how long an operation waits for one of the slots above before
+	// failing with ErrTimeout. Zero means it waits indefinitely.
+	ConcurrencyTimeout time.Duration `yaml:"concurrency_timeout"`
+
+	// Namespaces stores keys as "<namespace>/<key>", using Item.Namespace or DefaultNamespace,
+	// and only lists keys in DefaultNamespace. See WithNamespace and KeysInNamespace.
+	Namespaces bool `yaml:"namespaces"`
+
+	// ClearOnExit removes items created through the keyring when the process is interrupted or terminated
+	ClearOnExit bool `yaml:"clear_on_exit"`
+
+	// ChainWritePolicy controls which backends OpenChain writes to. Empty means WriteAll.
+	ChainWritePolicy ChainWritePolicy `yaml:"chain_write_policy"`
 
 	// ServiceName is a generic service name that is used by backends that support the concept
-	ServiceName string
+	ServiceName string `yaml:"service_name"`
 
-	// MacOSKeychainNameKeychainName is the name of the macOS keychain that is used
-	KeychainName string
+	// MacOSKeychainNameKeychainName is the name of the macOS keychain that is used, or an absolute path to it
+	KeychainName string `yaml:"keychain_name"`
 
 	// KeychainTrustApplication is whether the calling application should be trusted by default by items
-	KeychainTrustApplication bool
+	KeychainTrustApplication bool `yaml:"keychain_trust_application"`
 
 	// KeychainSynchronizable is whether the item can be synchronized to iCloud
-	KeychainSynchronizable bool
+	KeychainSynchronizable bool `yaml:"keychain_synchronizable"`
 
 	// KeychainAccessibleWhenUnlocked is whether the item is accessible when the device is locked
-	KeychainAccessibleWhenUnlocked bool
+	KeychainAccessibleWhenUnlocked bool `yaml:"keychain_accessible_when_unlocked"`
+
+	// KeychainCompressItems is whether item data is compressed with Snappy, so that larger items fit in the keychain
+	KeychainCompressItems bool `yaml:"keychain_compress_items"`
+
+	// KeychainSearchServices are the services that Get and Keys read items from, instead of
+	// ServiceName. Items of other applications can only be read if the caller is in their ACL.
+	KeychainSearchServices []string `yaml:"keychain_search_services"`
+
+	// KeychainAccessGroups are the keychain access groups that items are read from, in order.
+	// Items are written to the first. The application needs the keychain-access-groups entitlement.
+	KeychainAccessGroups []string `yaml:"keychain_access_groups"`
 
 	// KeychainPasswordFunc is an optional function used to prompt the user for a password
-	KeychainPasswordFunc PromptFunc
+	KeychainPasswordFunc PromptFunc `yaml:"-"`
+
+	// AllowEmptyPassphrase is whether a keychain can be created with an empty passphrase from KeychainPasswordFunc
+	AllowEmptyPassphrase bool `yaml:"allow_empty_passphrase"`
+
+	// MaxPassphraseAttempts is how many times KeychainPasswordFunc is called while it returns an empty
+	// passphrase before giving up with ErrPassphraseRequired. Zero means DefaultMaxPassphraseAttempts.
+	MaxPassphraseAttempts int `yaml:"max_passphrase_attempts"`
 
 	// FilePasswordFunc is a required function used to prompt the user for a password
-	FilePasswordFunc PromptFunc
+	FilePasswordFunc PromptFunc `yaml:"-"`
 
 	// FileDir is the directory that keyring files are stored in, ~ is resolved to home dir
-	FileDir string
+	FileDir string `yaml:"file_dir"`
+
+	// FileCompressionThreshold is the item data size in bytes above which items are compressed
+	// with zstd before being encrypted. Zero means 1024 bytes, and a negative value disables compression.
+	FileCompressionThreshold int `yaml:"file_compression_threshold"`
+
+	// FileCompressionLevel is the zstd compression level used by the file backend. Zero means the default level.
+	FileCompressionLevel int `yaml:"file_compression_level"`
+
+	// HMACKey, when set, makes the file backend sign each item with HMAC-SHA256 and reject items that don't match
+	HMACKey []byte `yaml:"hmac_key"`
 
 	// KWalletAppID is the application id for KWallet
-	KWalletAppID string
+	KWalletAppID string `yaml:"kwallet_app_id"`
 
 	// KWalletFolder is the folder for KWallet
-	KWalletFolder string
+	KWalletFolder string `yaml:"kwallet_folder"`
 
 	// LibSecretCollectionName is the name collection in secret-service
-	LibSecretCollectionName string
+	LibSecretCollectionName string `yaml:"libsecret_collection_name"`
+
+	// AgeDir is the directory that age encrypted item files are stored in, ~ is resolved to home dir
+	AgeDir string `yaml:"age_dir"`
+
+	// AgeRecipients are the X25519 or SSH public keys that age encrypted items are encrypted to
+	AgeRecipients []string `yaml:"age_recipients"`
+
+	// AgeIdentityFile is an age identity file or unencrypted SSH private key used to decrypt items
+	AgeIdentityFile string `yaml:"age_identity_file"`
+
+	// AgeSSHPublicKeyFile is an SSH public key file, such as ~/.ssh/id_ed25519.pub, that items are also encrypted to
+	AgeSSHPublicKeyFile string `yaml:"age_ssh_public_key_file"`
+
+	// AgeSSHPrivateKeyFile is an SSH private key file used to decrypt items, which may be protected by a passphrase
+	AgeSSHPrivateKeyFile string `yaml:"age_ssh_private_key_file"`
+
+	// AgeSSHPassphraseFunc is an optional function used to prompt the user for the SSH private key passphrase
+	AgeSSHPassphraseFunc PromptFunc `yaml:"-"`
+
+	// KeePassFile is the KeePass KDBX database that items are read from, ~ is resolved to home dir
+	KeePassFile string `yaml:"keepass_file"`
+
+	// KeePassPasswordFunc is an optional function used to prompt the user for the database passphrase
+	KeePassPasswordFunc PromptFunc `yaml:"-"`
+
+	// SealedBoxDir is the directory that sealed box item files are stored in, ~ is resolved to home dir
+	SealedBoxDir string `yaml:"sealedbox_dir"`
+
+	// SealedBoxPublicKey is the base64 encoded Curve25519 public key that items are sealed to
+	SealedBoxPublicKey string `yaml:"sealedbox_public_key"`
+
+	// SealedBoxPrivateKey is the path to a file containing the base64 encoded Curve25519 private key used to open items
+	SealedBoxPrivateKey string `yaml:"sealedbox_private_key"`
+
+	// GitCredentialsFile is the file used by git's "store" credential helper, defaulting to ~/.git-credentials
+	GitCredentialsFile string `yaml:"git_credentials_file"`
+
+	// SSHAuthSock is the socket of the ssh-agent to use, defaulting to $SSH_AUTH_SOCK
+	SSHAuthSock string `yaml:"ssh_auth_sock"`
+
+	// WASMDatabaseName is the IndexedDB database that the wasm backend stores items in
+	WASMDatabaseName string `yaml:"wasm_database_name"`
+
+	// WASMPasswordFunc is a required function used to prompt the user for a password in the wasm backend
+	WASMPasswordFunc PromptFunc `yaml:"-"`
 
 	// PassDir is the pass password-store directory
-	PassDir string
+	PassDir string `yaml:"pass_dir"`
 
 	// PassCmd is the name of the pass executable
-	PassCmd string
+	PassCmd string `yaml:"pass_cmd"`
 
 	// PassPrefix is a string prefix to prepend to the item path stored in pass
-	PassPrefix string
+	PassPrefix string `yaml:"pass_prefix"`
 
 	// WinCredPrefix is a string prefix to prepend to the key name
-	WinCredPrefix string
+	WinCredPrefix string `yaml:"wincred_prefix"`
+
+	// WinCredentialType is the type of credential the wincred backend stores. Empty means WinCredTypeGeneric.
+	WinCredentialType WinCredentialType `yaml:"wincred_credential_type"`
+
+	// PKCS11Module is the path to the PKCS#11 module (.so) for the HSM
+	PKCS11Module string `yaml:"pkcs11_module"`
+
+	// PKCS11SlotID is the slot of the token to use, ignored if PKCS11TokenLabel is set
+	PKCS11SlotID uint `yaml:"pkcs11_slot_id"`
+
+	// PKCS11TokenLabel is the label of the token to use
+	PKCS11TokenLabel string `yaml:"pkcs11_token_label"`
+
+	// PKCS11PIN is the user PIN used to log in to the token
+	PKCS11PIN string `yaml:"pkcs11_pin"`
+
+	// AzStorageAccountName is the Azure storage account used by the azblob backend
+	AzStorageAccountName string `yaml:"az_storage_account_name"`
+
+	// AzContainerName is the blob container used by the azblob backend, defaulting to ServiceName
+	AzContainerName string `yaml:"az_container_name"`
+}
+
+// LoadConfig reads a Config from a YAML file, using the field names given by the yaml
+// struct tags, e.g. service_name. $VAR and ${VAR} references in string values are replaced
+// with the value of the corresponding environment variable. Prompt functions can't be
+// represented in YAML and must be set on the returned Config in code.
+func LoadConfig(path string) (Config, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+
+	var cfg Config
+	if err = yaml.Unmarshal(b, &cfg); err != nil {
+		return Config{}, err
+	}
+
+	expandEnv(reflect.ValueOf(&cfg).Elem())
+	Debugf("Loaded config from %s", path)
+
+	return cfg, nil
+}
+
+// expandEnv expands environment variables in the string fields of a struct, including
+// those in slices
+func expandEnv(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.String:
+		if v.CanSet() {
+			v.SetString(os.ExpandEnv(v.String()))
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			expandEnv(v.Index(i))
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			expandEnv(v.Field(i))
+		}
+	}
 }
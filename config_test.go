@@ -0,0 +1,48 @@
+package keyring
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestLoadConfig(t *testing.T) {
+	f, err := ioutil.TempFile("", "keyring-config-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+
+	_, err = f.WriteString(`
+service_name: llamas
+allowed_backends: [file, pass]
+keychain_trust_application: true
+file_dir: $KEYRING_TEST_DIR/keyrings
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	os.Setenv("KEYRING_TEST_DIR", "/tmp/llamas")
+	defer os.Unsetenv("KEYRING_TEST_DIR")
+
+	cfg, err := LoadConfig(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.ServiceName != "llamas" {
+		t.Fatalf("Unexpected service name: %q", cfg.ServiceName)
+	}
+	if !reflect.DeepEqual(cfg.AllowedBackends, []BackendType{FileBackend, PassBackend}) {
+		t.Fatalf("Unexpected allowed backends: %v", cfg.AllowedBackends)
+	}
+	if !cfg.KeychainTrustApplication {
+		t.Fatal("Expected keychain_trust_application to be set")
+	}
+	if cfg.FileDir != "/tmp/llamas/keyrings" {
+		t.Fatalf("Environment variable wasn't expanded: %q", cfg.FileDir)
+	}
+}
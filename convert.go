@@ -0,0 +1,60 @@
+package keyring
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+)
+
+// ConvertBackend copies every item from the src backend into the dst backend, opening both
+// with cfg. Each item is read back from dst to verify it was stored intact. Items are left
+// in place on src, so it can be cleaned up once the caller is happy with the result.
+func ConvertBackend(src BackendType, dst BackendType, cfg Config) error {
+	if src == dst {
+		return fmt.Errorf("Source and destination backends are both %s", src)
+	}
+
+	srcRing, err := openBackend(src, cfg)
+	if err != nil {
+		return fmt.Errorf("Failed to open %s backend: %v", src, err)
+	}
+
+	dstRing, err := openBackend(dst, cfg)
+	if err != nil {
+		return fmt.Errorf("Failed to open %s backend: %v", dst, err)
+	}
+
+	keys, err := srcRing.Keys()
+	if err != nil {
+		return fmt.Errorf("Failed to list keys in %s backend: %v", src, err)
+	}
+
+	for idx, key := range keys {
+		item, err := srcRing.Get(key)
+		if err != nil {
+			return fmt.Errorf("Failed to read %q from %s backend: %v", key, src, err)
+		}
+		item.Key = key
+
+		if err = dstRing.Set(item); err != nil {
+			return fmt.Errorf("Failed to write %q to %s backend: %v", key, dst, err)
+		}
+
+		stored, err := dstRing.Get(key)
+		if err != nil {
+			return fmt.Errorf("Failed to verify %q in %s backend: %v", key, dst, err)
+		}
+		if !bytes.Equal(stored.Data, item.Data) {
+			return fmt.Errorf("Failed to verify %q in %s backend: data doesn't match", key, dst)
+		}
+
+		log.Printf("Migrated %q from %s to %s (%d/%d)", key, src, dst, idx+1, len(keys))
+	}
+
+	return nil
+}
+
+func openBackend(backend BackendType, cfg Config) (Keyring, error) {
+	cfg.AllowedBackends = []BackendType{backend}
+	return Open(cfg)
+}
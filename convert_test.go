@@ -0,0 +1,39 @@
+package keyring
+
+import "testing"
+
+func TestConvertBackend(t *testing.T) {
+	const srcBackend, dstBackend BackendType = "test-src", "test-dst"
+
+	src := NewArrayKeyring([]Item{
+		{Key: "llamas", Data: []byte("llamas are great")},
+		{Key: "alpacas", Data: []byte("alpacas are also great")},
+	})
+	dst := &ArrayKeyring{}
+
+	supportedBackends[srcBackend] = opener(func(_ Config) (Keyring, error) { return src, nil })
+	supportedBackends[dstBackend] = opener(func(_ Config) (Keyring, error) { return dst, nil })
+	defer delete(supportedBackends, srcBackend)
+	defer delete(supportedBackends, dstBackend)
+
+	if err := ConvertBackend(srcBackend, dstBackend, Config{}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{"llamas", "alpacas"} {
+		want, _ := src.Get(key)
+		got, err := dst.Get(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got.Data) != string(want.Data) {
+			t.Fatalf("Value migrated for %q was %q, expected %q", key, got.Data, want.Data)
+		}
+	}
+}
+
+func TestConvertBackendSameBackend(t *testing.T) {
+	if err := ConvertBackend(FileBackend, FileBackend, Config{}); err == nil {
+		t.Fatal("Expected an error converting a backend to itself")
+	}
+}
@@ -0,0 +1,623 @@
+// Package dbus exposes a keyring as a freedesktop.org Secret Service over D-Bus, so that
+// applications using libsecret, such as GNOME applications, store their secrets in it.
+// It's the inverse of the keyring's Secret Service backend.
+//
+// The keyring is served as a single collection, which is the "default" alias. Each item
+// is stored under a random key, with its label in Item.Label and its attributes in
+// Item.Tags, so only backends that store whole items can be searched by attribute.
+// Nothing is ever locked, and secrets can be transferred in plain or
+// dh-ietf1024-sha256-aes128-cbc-pkcs7 sessions.
+package dbus
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/99designs/keyring"
+	"github.com/godbus/dbus"
+)
+
+// BusName is the well-known name that the service is registered under
+const BusName = "org.freedesktop.secrets"
+
+const (
+	servicePath     dbus.ObjectPath = "/org/freedesktop/secrets"
+	collectionsPath dbus.ObjectPath = "/org/freedesktop/secrets/collection"
+	collectionPath  dbus.ObjectPath = "/org/freedesktop/secrets/collection/default"
+	sessionsPath    dbus.ObjectPath = "/org/freedesktop/secrets/session"
+	noPrompt        dbus.ObjectPath = "/"
+
+	serviceInterface    = "org.freedesktop.Secret.Service"
+	collectionInterface = "org.freedesktop.Secret.Collection"
+	itemInterface       = "org.freedesktop.Secret.Item"
+	sessionInterface    = "org.freedesktop.Secret.Session"
+	propertiesInterface = "org.freedesktop.DBus.Properties"
+
+	defaultAlias = "default"
+)
+
+// Errors defined by the Secret Service API, in addition to the standard D-Bus ones
+const (
+	errNoSuchObject = "org.freedesktop.Secret.Error.NoSuchObject"
+	errNoSession    = "org.freedesktop.Secret.Error.NoSession"
+	errNotSupported = "org.freedesktop.DBus.Error.NotSupported"
+	errInvalidArgs  = "org.freedesktop.DBus.Error.InvalidArgs"
+	errUnknownProp  = "org.freedesktop.DBus.Error.UnknownProperty"
+)
+
+// secret is the (oayays) struct that secrets are transferred as
+type secret struct {
+	Session     dbus.ObjectPath
+	Parameters  []byte
+	Value       []byte
+	ContentType string
+}
+
+type server struct {
+	kr   keyring.Keyring
+	conn *dbus.Conn
+
+	mu          sync.Mutex
+	sessions    map[dbus.ObjectPath]*session
+	nextSession int
+}
+
+// lockedKeyring serialises calls to a keyring. Method calls are handled concurrently, and
+// most keyrings aren't safe for concurrent use.
+type lockedKeyring struct {
+	mu sync.Mutex
+	kr keyring.Keyring
+}
+
+func (k *lockedKeyring) Get(key string) (keyring.Item, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.kr.Get(key)
+}
+
+func (k *lockedKeyring) GetMetadata(key string) (keyring.Metadata, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.kr.GetMetadata(key)
+}
+
+func (k *lockedKeyring) Set(item keyring.Item) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.kr.Set(item)
+}
+
+func (k *lockedKeyring) Remove(key string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.kr.Remove(key)
+}
+
+func (k *lockedKeyring) Keys() ([]string, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.kr.Keys()
+}
+
+// Serve exports kr on bus as the Secret Service and claims BusName. It returns once the
+// service is registered, or with an error if BusName already has an owner. Requests are
+// served until bus is closed. Calls to kr are made one at a time, but kr must be safe for
+// concurrent use if it's also used elsewhere while being served.
+func Serve(kr keyring.Keyring, bus *dbus.Conn) error {
+	s := &server{
+		kr:       &lockedKeyring{kr: kr},
+		conn:     bus,
+		sessions: map[dbus.ObjectPath]*session{},
+	}
+
+	exports := []struct {
+		v       interface{}
+		path    dbus.ObjectPath
+		iface   string
+		subtree bool
+	}{
+		{&service{s}, servicePath, serviceInterface, false},
+		{&collection{s}, collectionsPath, collectionInterface, true},
+		{&item{s}, collectionsPath, itemInterface, true},
+		{&sessionObject{s}, sessionsPath, sessionInterface, true},
+		{&properties{s}, servicePath, propertiesInterface, false},
+		{&properties{s}, collectionsPath, propertiesInterface, true},
+	}
+	for _, e := range exports {
+		var err error
+		if e.subtree {
+			err = bus.ExportSubtree(e.v, e.path, e.iface)
+		} else {
+			err = bus.Export(e.v, e.path, e.iface)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	reply, err := bus.RequestName(BusName, dbus.NameFlagDoNotQueue)
+	if err != nil {
+		return err
+	}
+	if reply != dbus.RequestNameReplyPrimaryOwner {
+		return fmt.Errorf("The name %s is already owned", BusName)
+	}
+
+	keyring.Debugf("Serving the Secret Service as %s", BusName)
+	return nil
+}
+
+// itemPath returns the object path of the item with key. Object paths can only contain
+// ASCII letters, digits and underscores, so the key is hex encoded.
+func itemPath(key string) dbus.ObjectPath {
+	return collectionPath + "/" + dbus.ObjectPath(hex.EncodeToString([]byte(key)))
+}
+
+// itemKey returns the key of the item at path
+func itemKey(path dbus.ObjectPath) (string, bool) {
+	encoded := strings.TrimPrefix(string(path), string(collectionPath)+"/")
+	if encoded == string(path) || encoded == "" {
+		return "", false
+	}
+
+	key, err := hex.DecodeString(encoded)
+	if err != nil {
+		return "", false
+	}
+	return string(key), true
+}
+
+func isCollection(path dbus.ObjectPath) bool {
+	return path == collectionPath
+}
+
+func failed(err error) *dbus.Error {
+	return dbus.MakeFailedError(err)
+}
+
+func noSuchObject(path dbus.ObjectPath) *dbus.Error {
+	return dbus.NewError(errNoSuchObject, []interface{}{fmt.Sprintf("No such object %s", path)})
+}
+
+func notSupported(what string) *dbus.Error {
+	return dbus.NewError(errNotSupported, []interface{}{what + " is not supported"})
+}
+
+// session returns the session at path, which must have been opened by sender
+func (s *server) session(sender dbus.Sender, path dbus.ObjectPath) (*session, *dbus.Error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sess, ok := s.sessions[path]
+	if !ok || sess.sender != string(sender) {
+		return nil, dbus.NewError(errNoSession, []interface{}{fmt.Sprintf("No session %s", path)})
+	}
+	return sess, nil
+}
+
+// getItem returns the item at path
+func (s *server) getItem(path dbus.ObjectPath) (keyring.Item, *dbus.Error) {
+	key, ok := itemKey(path)
+	if !ok {
+		return keyring.Item{}, noSuchObject(path)
+	}
+
+	i, err := s.kr.Get(key)
+	if err == keyring.ErrKeyNotFound {
+		return keyring.Item{}, noSuchObject(path)
+	} else if err != nil {
+		return keyring.Item{}, failed(err)
+	}
+	return i, nil
+}
+
+// encryptSecret returns data as a secret for the session at path
+func (s *server) encryptSecret(sender dbus.Sender, path dbus.ObjectPath, data []byte) (secret, *dbus.Error) {
+	sess, dbusErr := s.session(sender, path)
+	if dbusErr != nil {
+		return secret{}, dbusErr
+	}
+
+	params, value, err := sess.encrypt(data)
+	if err != nil {
+		return secret{}, failed(err)
+	}
+	return secret{Session: path, Parameters: params, Value: value, ContentType: "text/plain"}, nil
+}
+
+// decryptSecret returns the data of a secret sent by sender
+func (s *server) decryptSecret(sender dbus.Sender, sec secret) ([]byte, *dbus.Error) {
+	sess, dbusErr := s.session(sender, sec.Session)
+	if dbusErr != nil {
+		return nil, dbusErr
+	}
+
+	data, err := sess.decrypt(sec.Parameters, sec.Value)
+	if err != nil {
+		return nil, dbus.NewError(errInvalidArgs, []interface{}{err.Error()})
+	}
+	return data, nil
+}
+
+// search returns the paths of the items whose attributes include attrs
+func (s *server) search(attrs map[string]string) ([]dbus.ObjectPath, *dbus.Error) {
+	keys, err := s.kr.Keys()
+	if err != nil {
+		return nil, failed(err)
+	}
+
+	paths := []dbus.ObjectPath{}
+	for _, key := range keys {
+		i, err := s.kr.Get(key)
+		if err == keyring.ErrKeyNotFound || err == keyring.ErrNotYetActive {
+			continue
+		} else if err != nil {
+			return nil, failed(err)
+		}
+
+		if hasAttributes(i, attrs) {
+			paths = append(paths, itemPath(key))
+		}
+	}
+	return paths, nil
+}
+
+func hasAttributes(i keyring.Item, attrs map[string]string) bool {
+	for k, v := range attrs {
+		if value, ok := i.Tags[k]; !ok || value != v {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *server) itemPaths() ([]dbus.ObjectPath, *dbus.Error) {
+	keys, err := s.kr.Keys()
+	if err != nil {
+		return nil, failed(err)
+	}
+
+	paths := make([]dbus.ObjectPath, len(keys))
+	for idx, key := range keys {
+		paths[idx] = itemPath(key)
+	}
+	return paths, nil
+}
+
+func (s *server) emit(name string, path dbus.ObjectPath) {
+	if err := s.conn.Emit(collectionPath, collectionInterface+"."+name, path); err != nil {
+		keyring.Debugf("Failed to emit %s for %s: %v", name, path, err)
+	}
+}
+
+// service implements org.freedesktop.Secret.Service
+type service struct {
+	s *server
+}
+
+func (svc *service) OpenSession(sender dbus.Sender, algorithm string, input dbus.Variant) (dbus.Variant, dbus.ObjectPath, *dbus.Error) {
+	sess, output, err := newSession(string(sender), algorithm, input)
+	if err == errUnsupportedAlgorithm {
+		return dbus.Variant{}, "", notSupported("The algorithm " + algorithm)
+	} else if err != nil {
+		return dbus.Variant{}, "", dbus.NewError(errInvalidArgs, []interface{}{err.Error()})
+	}
+
+	svc.s.mu.Lock()
+	defer svc.s.mu.Unlock()
+
+	svc.s.nextSession++
+	path := sessionsPath + dbus.ObjectPath(fmt.Sprintf("/s%d", svc.s.nextSession))
+	svc.s.sessions[path] = sess
+
+	keyring.Debugf("Opened %s session %s for %s", algorithm, path, sender)
+	return output, path, nil
+}
+
+// CreateCollection returns the keyring's collection when asked for the default alias, as
+// it's the only one
+func (svc *service) CreateCollection(props map[string]dbus.Variant, alias string) (dbus.ObjectPath, dbus.ObjectPath, *dbus.Error) {
+	if alias == defaultAlias {
+		return collectionPath, noPrompt, nil
+	}
+	return "", "", notSupported("Creating collections")
+}
+
+func (svc *service) SearchItems(attrs map[string]string) ([]dbus.ObjectPath, []dbus.ObjectPath, *dbus.Error) {
+	unlocked, err := svc.s.search(attrs)
+	return unlocked, []dbus.ObjectPath{}, err
+}
+
+// Unlock reports every object as unlocked, as nothing is ever locked
+func (svc *service) Unlock(objects []dbus.ObjectPath) ([]dbus.ObjectPath, dbus.ObjectPath, *dbus.Error) {
+	return objects, noPrompt, nil
+}
+
+// Lock locks nothing, as the keyring can't be locked
+func (svc *service) Lock(objects []dbus.ObjectPath) ([]dbus.ObjectPath, dbus.ObjectPath, *dbus.Error) {
+	return []dbus.ObjectPath{}, noPrompt, nil
+}
+
+func (svc *service) GetSecrets(sender dbus.Sender, items []dbus.ObjectPath, session dbus.ObjectPath) (map[dbus.ObjectPath]secret, *dbus.Error) {
+	secrets := map[dbus.ObjectPath]secret{}
+	for _, path := range items {
+		i, dbusErr := svc.s.getItem(path)
+		if dbusErr != nil {
+			continue
+		}
+
+		sec, dbusErr := svc.s.encryptSecret(sender, session, i.Data)
+		if dbusErr != nil {
+			return nil, dbusErr
+		}
+		secrets[path] = sec
+	}
+	return secrets, nil
+}
+
+func (svc *service) ReadAlias(name string) (dbus.ObjectPath, *dbus.Error) {
+	if name == defaultAlias {
+		return collectionPath, nil
+	}
+	return noPrompt, nil
+}
+
+func (svc *service) SetAlias(name string, collection dbus.ObjectPath) *dbus.Error {
+	return notSupported("Setting aliases")
+}
+
+// collection implements org.freedesktop.Secret.Collection
+type collection struct {
+	s *server
+}
+
+func (c *collection) Delete(msg dbus.Message) (dbus.ObjectPath, *dbus.Error) {
+	return "", notSupported("Deleting the collection")
+}
+
+func (c *collection) SearchItems(msg dbus.Message, attrs map[string]string) ([]dbus.ObjectPath, *dbus.Error) {
+	if path := msgPath(msg); !isCollection(path) {
+		return nil, noSuchObject(path)
+	}
+	return c.s.search(attrs)
+}
+
+// CreateItem stores an item under a newly generated key. With replace, an item with the
+// same attributes is updated in place instead, and any others with them are removed.
+func (c *collection) CreateItem(msg dbus.Message, sender dbus.Sender, props map[string]dbus.Variant, sec secret, replace bool) (dbus.ObjectPath, dbus.ObjectPath, *dbus.Error) {
+	if path := msgPath(msg); !isCollection(path) {
+		return "", "", noSuchObject(path)
+	}
+
+	label, _ := props[itemInterface+".Label"].Value().(string)
+	attrs, _ := props[itemInterface+".Attributes"].Value().(map[string]string)
+
+	data, dbusErr := c.s.decryptSecret(sender, sec)
+	if dbusErr != nil {
+		return "", "", dbusErr
+	}
+
+	var key string
+	if replace {
+		existing, dbusErr := c.s.search(attrs)
+		if dbusErr != nil {
+			return "", "", dbusErr
+		}
+		for _, path := range existing {
+			i, dbusErr := c.s.getItem(path)
+			if dbusErr != nil || !reflect.DeepEqual(i.Tags, attrs) {
+				continue
+			}
+			if key == "" {
+				key = i.Key
+				continue
+			}
+			keyring.Debugf("Replacing item %q", i.Key)
+			if err := c.s.kr.Remove(i.Key); err != nil {
+				return "", "", failed(err)
+			}
+			c.s.emit("ItemDeleted", path)
+		}
+	}
+
+	created := key == ""
+	if created {
+		var err error
+		if key, err = newItemKey(); err != nil {
+			return "", "", failed(err)
+		}
+	}
+
+	err := c.s.kr.Set(keyring.Item{Key: key, Data: data, Label: label, Tags: attrs})
+	if err != nil {
+		return "", "", failed(err)
+	}
+
+	path := itemPath(key)
+	if created {
+		c.s.emit("ItemCreated", path)
+	} else {
+		c.s.emit("ItemChanged", path)
+	}
+	return path, noPrompt, nil
+}
+
+// newItemKey returns a random key for a new item, so that items with the same label
+// don't overwrite each other
+func newItemKey() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// item implements org.freedesktop.Secret.Item
+type item struct {
+	s *server
+}
+
+func (it *item) Delete(msg dbus.Message) (dbus.ObjectPath, *dbus.Error) {
+	path := msgPath(msg)
+	i, dbusErr := it.s.getItem(path)
+	if dbusErr != nil {
+		return "", dbusErr
+	}
+
+	if err := it.s.kr.Remove(i.Key); err != nil {
+		return "", failed(err)
+	}
+	it.s.emit("ItemDeleted", path)
+	return noPrompt, nil
+}
+
+func (it *item) GetSecret(msg dbus.Message, sender dbus.Sender, session dbus.ObjectPath) (secret, *dbus.Error) {
+	i, dbusErr := it.s.getItem(msgPath(msg))
+	if dbusErr != nil {
+		return secret{}, dbusErr
+	}
+	return it.s.encryptSecret(sender, session, i.Data)
+}
+
+func (it *item) SetSecret(msg dbus.Message, sender dbus.Sender, sec secret) *dbus.Error {
+	path := msgPath(msg)
+	i, dbusErr := it.s.getItem(path)
+	if dbusErr != nil {
+		return dbusErr
+	}
+
+	if i.Data, dbusErr = it.s.decryptSecret(sender, sec); dbusErr != nil {
+		return dbusErr
+	}
+	if err := it.s.kr.Set(i); err != nil {
+		return failed(err)
+	}
+	it.s.emit("ItemChanged", path)
+	return nil
+}
+
+// sessionObject implements org.freedesktop.Secret.Session
+type sessionObject struct {
+	s *server
+}
+
+func (so *sessionObject) Close(msg dbus.Message, sender dbus.Sender) *dbus.Error {
+	path := msgPath(msg)
+	if _, dbusErr := so.s.session(sender, path); dbusErr != nil {
+		return dbusErr
+	}
+
+	so.s.mu.Lock()
+	delete(so.s.sessions, path)
+	so.s.mu.Unlock()
+
+	keyring.Debugf("Closed session %s", path)
+	return nil
+}
+
+// properties implements org.freedesktop.DBus.Properties for every object
+type properties struct {
+	s *server
+}
+
+func (p *properties) Get(msg dbus.Message, iface, name string) (dbus.Variant, *dbus.Error) {
+	all, dbusErr := p.GetAll(msg, iface)
+	if dbusErr != nil {
+		return dbus.Variant{}, dbusErr
+	}
+
+	v, ok := all[name]
+	if !ok {
+		return dbus.Variant{}, dbus.NewError(errUnknownProp, []interface{}{fmt.Sprintf("No property %s.%s", iface, name)})
+	}
+	return v, nil
+}
+
+func (p *properties) GetAll(msg dbus.Message, iface string) (map[string]dbus.Variant, *dbus.Error) {
+	path := msgPath(msg)
+	switch {
+	case path == servicePath && iface == serviceInterface:
+		return map[string]dbus.Variant{
+			"Collections": dbus.MakeVariant([]dbus.ObjectPath{collectionPath}),
+		}, nil
+
+	case isCollection(path) && iface == collectionInterface:
+		items, dbusErr := p.s.itemPaths()
+		if dbusErr != nil {
+			return nil, dbusErr
+		}
+		return map[string]dbus.Variant{
+			"Items":    dbus.MakeVariant(items),
+			"Label":    dbus.MakeVariant(defaultAlias),
+			"Locked":   dbus.MakeVariant(false),
+			"Created":  dbus.MakeVariant(uint64(0)),
+			"Modified": dbus.MakeVariant(uint64(0)),
+		}, nil
+
+	case iface == itemInterface:
+		i, dbusErr := p.s.getItem(path)
+		if dbusErr != nil {
+			return nil, dbusErr
+		}
+
+		var modified uint64
+		if md, err := p.s.kr.GetMetadata(i.Key); err == nil && !md.ModificationTime.IsZero() {
+			modified = uint64(md.ModificationTime.Unix())
+		}
+
+		attrs := i.Tags
+		if attrs == nil {
+			attrs = map[string]string{}
+		}
+		return map[string]dbus.Variant{
+			"Locked":     dbus.MakeVariant(false),
+			"Attributes": dbus.MakeVariant(attrs),
+			"Label":      dbus.MakeVariant(i.Label),
+			"Created":    dbus.MakeVariant(modified),
+			"Modified":   dbus.MakeVariant(modified),
+		}, nil
+	}
+
+	return nil, noSuchObject(path)
+}
+
+// Set changes the label or attributes of an item. Nothing else can be changed.
+func (p *properties) Set(msg dbus.Message, iface, name string, value dbus.Variant) *dbus.Error {
+	path := msgPath(msg)
+	if iface != itemInterface {
+		return notSupported("Setting " + iface + "." + name)
+	}
+
+	i, dbusErr := p.s.getItem(path)
+	if dbusErr != nil {
+		return dbusErr
+	}
+
+	var ok bool
+	switch name {
+	case "Label":
+		i.Label, ok = value.Value().(string)
+	case "Attributes":
+		i.Tags, ok = value.Value().(map[string]string)
+	default:
+		return notSupported("Setting " + iface + "." + name)
+	}
+	if !ok {
+		return dbus.NewError(errInvalidArgs, []interface{}{fmt.Sprintf("Invalid value for %s", name)})
+	}
+
+	if err := p.s.kr.Set(i); err != nil {
+		return failed(err)
+	}
+	p.s.emit("ItemChanged", path)
+	return nil
+}
+
+func msgPath(msg dbus.Message) dbus.ObjectPath {
+	path, _ := msg.Headers[dbus.FieldPath].Value().(dbus.ObjectPath)
+	return path
+}
@@ -0,0 +1,267 @@
+package dbus
+
+import (
+	"bufio"
+	"math/big"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/99designs/keyring"
+	"github.com/godbus/dbus"
+)
+
+// startBus runs a private session bus with dbus-daemon, returning its address
+func startBus(t *testing.T) string {
+	if _, err := exec.LookPath("dbus-daemon"); err != nil {
+		t.Skip("dbus-daemon is not installed")
+	}
+
+	cmd := exec.Command("dbus-daemon", "--session", "--nofork", "--print-address=1")
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	})
+
+	addr, err := bufio.NewReader(stdout).ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.TrimSpace(addr)
+}
+
+func connect(t *testing.T, addr string) *dbus.Conn {
+	conn, err := dbus.Dial(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	if err = conn.Auth(nil); err != nil {
+		t.Fatal(err)
+	}
+	if err = conn.Hello(); err != nil {
+		t.Fatal(err)
+	}
+	return conn
+}
+
+func serve(t *testing.T, kr keyring.Keyring) *dbus.Conn {
+	addr := startBus(t)
+	if err := Serve(kr, connect(t, addr)); err != nil {
+		t.Fatal(err)
+	}
+	return connect(t, addr)
+}
+
+func TestServe(t *testing.T) {
+	// The test reads the keyring while the server is using it
+	kr := &lockedKeyring{kr: keyring.NewArrayKeyring(nil)}
+	client := serve(t, kr)
+	svc := client.Object(BusName, servicePath)
+
+	var output dbus.Variant
+	var session dbus.ObjectPath
+	if err := svc.Call(serviceInterface+".OpenSession", 0, "plain", dbus.MakeVariant("")).Store(&output, &session); err != nil {
+		t.Fatal(err)
+	}
+
+	var coll dbus.ObjectPath
+	if err := svc.Call(serviceInterface+".ReadAlias", 0, "default").Store(&coll); err != nil {
+		t.Fatal(err)
+	}
+
+	props := map[string]dbus.Variant{
+		itemInterface + ".Label":      dbus.MakeVariant("llamas"),
+		itemInterface + ".Attributes": dbus.MakeVariant(map[string]string{"service": "farm", "user": "llama"}),
+	}
+	sec := secret{Session: session, Parameters: []byte{}, Value: []byte("llamas are great"), ContentType: "text/plain"}
+
+	var path, prompt dbus.ObjectPath
+	if err := client.Object(BusName, coll).Call(collectionInterface+".CreateItem", 0, props, sec, true).Store(&path, &prompt); err != nil {
+		t.Fatal(err)
+	}
+
+	key, ok := itemKey(path)
+	if !ok {
+		t.Fatalf("Unexpected item path %s", path)
+	}
+	i, err := kr.Get(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(i.Data) != "llamas are great" || i.Label != "llamas" || i.Tags["service"] != "farm" {
+		t.Fatalf("Unexpected item in the keyring: %+v", i)
+	}
+
+	var unlocked, locked []dbus.ObjectPath
+	if err = svc.Call(serviceInterface+".SearchItems", 0, map[string]string{"service": "farm"}).Store(&unlocked, &locked); err != nil {
+		t.Fatal(err)
+	}
+	if len(unlocked) != 1 || unlocked[0] != path {
+		t.Fatalf("Expected to find %s, got %v", path, unlocked)
+	}
+	var none []dbus.ObjectPath
+	if err = svc.Call(serviceInterface+".SearchItems", 0, map[string]string{"service": "zoo"}).Store(&none, &locked); err != nil {
+		t.Fatal(err)
+	}
+	if len(none) != 0 {
+		t.Fatalf("Expected no items, got %v", none)
+	}
+
+	obj := client.Object(BusName, path)
+	label, err := obj.GetProperty(itemInterface + ".Label")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if label.Value() != "llamas" {
+		t.Fatalf("Unexpected label %v", label)
+	}
+
+	var got secret
+	if err = obj.Call(itemInterface+".GetSecret", 0, session).Store(&got); err != nil {
+		t.Fatal(err)
+	}
+	if string(got.Value) != "llamas are great" {
+		t.Fatalf("Unexpected secret %q", got.Value)
+	}
+
+	if err = obj.Call(itemInterface+".Delete", 0).Store(&prompt); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = kr.Get(key); err != keyring.ErrKeyNotFound {
+		t.Fatalf("Expected ErrKeyNotFound, got: %v", err)
+	}
+
+	if err = client.Object(BusName, session).Call(sessionInterface+".Close", 0).Store(); err != nil {
+		t.Fatal(err)
+	}
+	if err = obj.Call(itemInterface+".GetSecret", 0, session).Store(&got); err == nil {
+		t.Fatal("Expected an error using a closed session")
+	}
+}
+
+func TestServeCreateItemWithSameLabel(t *testing.T) {
+	kr := &lockedKeyring{kr: keyring.NewArrayKeyring(nil)}
+	client := serve(t, kr)
+	svc := client.Object(BusName, servicePath)
+
+	var output dbus.Variant
+	var session dbus.ObjectPath
+	if err := svc.Call(serviceInterface+".OpenSession", 0, "plain", dbus.MakeVariant("")).Store(&output, &session); err != nil {
+		t.Fatal(err)
+	}
+
+	create := func(user, value string, replace bool) dbus.ObjectPath {
+		props := map[string]dbus.Variant{
+			itemInterface + ".Label":      dbus.MakeVariant("Password for farm"),
+			itemInterface + ".Attributes": dbus.MakeVariant(map[string]string{"service": "farm", "user": user}),
+		}
+		sec := secret{Session: session, Parameters: []byte{}, Value: []byte(value), ContentType: "text/plain"}
+
+		var path, prompt dbus.ObjectPath
+		if err := client.Object(BusName, collectionPath).Call(collectionInterface+".CreateItem", 0, props, sec, replace).Store(&path, &prompt); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	llama := create("llama", "llamas are great", true)
+	alpaca := create("alpaca", "alpacas are great", true)
+	if llama == alpaca {
+		t.Fatalf("Expected items with the same label to have different paths, got %s", llama)
+	}
+
+	if replaced := create("llama", "llamas are the best", true); replaced != llama {
+		t.Fatalf("Expected replacing to update %s, got %s", llama, replaced)
+	}
+
+	keys, err := kr.Keys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 2 {
+		t.Fatalf("Expected 2 items, got %v", keys)
+	}
+
+	key, _ := itemKey(llama)
+	i, err := kr.Get(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(i.Data) != "llamas are the best" {
+		t.Fatalf("Unexpected secret %q", i.Data)
+	}
+}
+
+func TestServeEncryptedSession(t *testing.T) {
+	kr := keyring.NewArrayKeyring([]keyring.Item{{Key: "llamas", Data: []byte("llamas are great")}})
+	client := serve(t, kr)
+
+	// The client's half of the key agreement, with a fixed private key
+	private := big.NewInt(0xdeadbeef)
+	public := new(big.Int).Exp(big.NewInt(2), private, dhPrime)
+
+	var output dbus.Variant
+	var path dbus.ObjectPath
+	if err := client.Object(BusName, servicePath).Call(serviceInterface+".OpenSession", 0, algorithmDH, dbus.MakeVariant(public.Bytes())).Store(&output, &path); err != nil {
+		t.Fatal(err)
+	}
+
+	serverPublic, ok := output.Value().([]byte)
+	if !ok {
+		t.Fatalf("Unexpected session output %v", output)
+	}
+	shared := make([]byte, len(dhPrime.Bytes()))
+	new(big.Int).Exp(new(big.Int).SetBytes(serverPublic), private, dhPrime).FillBytes(shared)
+	key, err := deriveKey(shared)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientSession := &session{key: key}
+
+	var got secret
+	if err = client.Object(BusName, itemPath("llamas")).Call(itemInterface+".GetSecret", 0, path).Store(&got); err != nil {
+		t.Fatal(err)
+	}
+	if string(got.Value) == "llamas are great" {
+		t.Fatal("Expected the secret to be encrypted")
+	}
+
+	data, err := clientSession.decrypt(got.Parameters, got.Value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "llamas are great" {
+		t.Fatalf("Unexpected secret %q", data)
+	}
+}
+
+func TestServeRejectsSecondServer(t *testing.T) {
+	addr := startBus(t)
+	if err := Serve(keyring.NewArrayKeyring(nil), connect(t, addr)); err != nil {
+		t.Fatal(err)
+	}
+	if err := Serve(keyring.NewArrayKeyring(nil), connect(t, addr)); err == nil {
+		t.Fatal("Expected an error when the name is already owned")
+	}
+}
+
+func TestItemPath(t *testing.T) {
+	for _, key := range []string{"llamas", "llamas/alpacas", "ünïcödé"} {
+		got, ok := itemKey(itemPath(key))
+		if !ok || got != key {
+			t.Fatalf("Expected %q back from %s, got %q", key, itemPath(key), got)
+		}
+	}
+	if _, ok := itemKey(collectionPath); ok {
+		t.Fatal("Expected the collection not to be an item")
+	}
+}
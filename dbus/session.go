@@ -0,0 +1,142 @@
+package dbus
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"math/big"
+
+	"github.com/godbus/dbus"
+	"golang.org/x/crypto/hkdf"
+)
+
+// Algorithms that sessions can be opened with
+const (
+	algorithmPlain = "plain"
+	algorithmDH    = "dh-ietf1024-sha256-aes128-cbc-pkcs7"
+)
+
+// dhPrime is the 1024-bit MODP group from RFC 2409, with a generator of 2
+var dhPrime, _ = new(big.Int).SetString(
+	"FFFFFFFFFFFFFFFFC90FDAA22168C234C4C6628B80DC1CD1"+
+		"29024E088A67CC74020BBEA63B139B22514A08798E3404DD"+
+		"EF9519B3CD3A431B302B0A6DF25F14374FE1356D6D51C245"+
+		"E485B576625E7EC6F44C42E9A637ED6B0BFF5CB6F406B7ED"+
+		"EE386BFB5A899FA5AE9F24117C4B1FE649286651ECE65381"+
+		"FFFFFFFFFFFFFFFF", 16)
+
+var errUnsupportedAlgorithm = errors.New("Unsupported session algorithm")
+
+// session is how secrets are transferred to a client. Secrets are sent as is in plain
+// sessions, and encrypted with AES-128-CBC using a key agreed with Diffie-Hellman otherwise.
+type session struct {
+	sender string
+	key    []byte
+}
+
+// newSession returns a session for algorithm and the output to send back to the client
+func newSession(sender, algorithm string, input dbus.Variant) (*session, dbus.Variant, error) {
+	switch algorithm {
+	case algorithmPlain:
+		return &session{sender: sender}, dbus.MakeVariant(""), nil
+	case algorithmDH:
+		peer, ok := input.Value().([]byte)
+		if !ok {
+			return nil, dbus.Variant{}, errors.New("Session input must be a byte array")
+		}
+
+		public, key, err := agreeKey(new(big.Int).SetBytes(peer))
+		if err != nil {
+			return nil, dbus.Variant{}, err
+		}
+		return &session{sender: sender, key: key}, dbus.MakeVariant(public), nil
+	default:
+		return nil, dbus.Variant{}, errUnsupportedAlgorithm
+	}
+}
+
+// agreeKey generates a Diffie-Hellman key pair, returning the public key and the AES key
+// derived from the secret shared with peer
+func agreeKey(peer *big.Int) ([]byte, []byte, error) {
+	max := new(big.Int).Sub(dhPrime, big.NewInt(1))
+	if peer.Cmp(big.NewInt(1)) <= 0 || peer.Cmp(max) >= 0 {
+		return nil, nil, errors.New("Invalid Diffie-Hellman public key")
+	}
+
+	private, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return nil, nil, err
+	}
+	public := new(big.Int).Exp(big.NewInt(2), private, dhPrime)
+
+	shared := make([]byte, len(dhPrime.Bytes()))
+	new(big.Int).Exp(peer, private, dhPrime).FillBytes(shared)
+
+	key, err := deriveKey(shared)
+	if err != nil {
+		return nil, nil, err
+	}
+	return public.Bytes(), key, nil
+}
+
+// deriveKey returns the AES-128 key for a shared secret, using HKDF-SHA256 without a
+// salt or info
+func deriveKey(shared []byte) ([]byte, error) {
+	key := make([]byte, 16)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, shared, nil, nil), key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// encrypt returns the parameters and value of a secret holding data
+func (s *session) encrypt(data []byte) ([]byte, []byte, error) {
+	if s.key == nil {
+		return []byte{}, data, nil
+	}
+
+	block, err := aes.NewCipher(s.key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	iv := make([]byte, aes.BlockSize)
+	if _, err = rand.Read(iv); err != nil {
+		return nil, nil, err
+	}
+
+	padding := aes.BlockSize - len(data)%aes.BlockSize
+	value := append(append([]byte{}, data...), bytes.Repeat([]byte{byte(padding)}, padding)...)
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(value, value)
+
+	return iv, value, nil
+}
+
+// decrypt returns the data of a secret with parameters and value
+func (s *session) decrypt(parameters, value []byte) ([]byte, error) {
+	if s.key == nil {
+		return value, nil
+	}
+
+	if len(parameters) != aes.BlockSize || len(value) == 0 || len(value)%aes.BlockSize != 0 {
+		return nil, errors.New("Invalid encrypted secret")
+	}
+
+	block, err := aes.NewCipher(s.key)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]byte, len(value))
+	cipher.NewCBCDecrypter(block, parameters).CryptBlocks(data, value)
+
+	padding := int(data[len(data)-1])
+	if padding == 0 || padding > aes.BlockSize || !bytes.Equal(data[len(data)-padding:], bytes.Repeat([]byte{byte(padding)}, padding)) {
+		return nil, errors.New("Invalid encrypted secret padding")
+	}
+	return data[:len(data)-padding], nil
+}
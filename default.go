@@ -0,0 +1,78 @@
+package keyring
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+var (
+	defaultMu       sync.Mutex
+	defaultKeyring  Keyring
+	defaultOnce     sync.Once
+	defaultOpenErr  error
+	defaultDetected Keyring
+)
+
+// SetDefault sets the keyring used by Default and the package level functions such as Get
+func SetDefault(kr Keyring) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultKeyring = kr
+}
+
+// Default returns the keyring set with SetDefault. If none was set and $KEYRING_SERVICE is,
+// the keyring is opened once with NewAutoKeyring for that service, restricted to the
+// backend in $KEYRING_BACKEND if that is set too. Otherwise, or if opening fails, it panics.
+func Default() Keyring {
+	defaultMu.Lock()
+	kr := defaultKeyring
+	defaultMu.Unlock()
+	if kr != nil {
+		return kr
+	}
+
+	defaultOnce.Do(func() {
+		service := os.Getenv("KEYRING_SERVICE")
+		if service == "" {
+			defaultOpenErr = fmt.Errorf("no default keyring, call SetDefault or set $KEYRING_SERVICE")
+			return
+		}
+
+		cfg := Config{ServiceName: service}
+		if backend := os.Getenv("KEYRING_BACKEND"); backend != "" {
+			cfg.AllowedBackends = []BackendType{BackendType(backend)}
+		}
+		Debugf("Opening default keyring for service %q", service)
+		defaultDetected, defaultOpenErr = NewAutoKeyring(cfg)
+	})
+	if defaultOpenErr != nil {
+		panic("keyring: " + defaultOpenErr.Error())
+	}
+	return defaultDetected
+}
+
+// Get returns the item matching key from the Default keyring
+func Get(key string) (Item, error) {
+	return Default().Get(key)
+}
+
+// GetMetadata returns the metadata of key from the Default keyring
+func GetMetadata(key string) (Metadata, error) {
+	return Default().GetMetadata(key)
+}
+
+// Set stores item on the Default keyring
+func Set(item Item) error {
+	return Default().Set(item)
+}
+
+// Remove removes key from the Default keyring
+func Remove(key string) error {
+	return Default().Remove(key)
+}
+
+// Keys lists the keys on the Default keyring
+func Keys() ([]string, error) {
+	return Default().Keys()
+}
@@ -0,0 +1,31 @@
+package keyring
+
+import "testing"
+
+func TestDefaultKeyring(t *testing.T) {
+	defer SetDefault(nil)
+	SetDefault(&ArrayKeyring{})
+
+	if err := Set(Item{Key: "llamas", Data: []byte("llamas are great")}); err != nil {
+		t.Fatal(err)
+	}
+
+	item, err := Get("llamas")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(item.Data) != "llamas are great" {
+		t.Fatalf("Value stored was not the value retrieved: %q", item.Data)
+	}
+
+	if err = Remove("llamas"); err != nil {
+		t.Fatal(err)
+	}
+	keys, err := Keys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 0 {
+		t.Fatalf("Expected no keys, got: %v", keys)
+	}
+}
@@ -0,0 +1,218 @@
+package keyring
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const diskCacheExt = ".cache"
+
+// diskCachedKeyring keeps items from another Keyring in memory, and in encrypted files in a
+// directory so that they survive restarts of the process
+type diskCachedKeyring struct {
+	kr      Keyring
+	service string
+	dir     string
+	ttl     time.Duration
+	gcm     cipher.AEAD
+
+	mu      sync.Mutex
+	entries map[string]diskCacheEntry
+}
+
+type diskCacheEntry struct {
+	Service string    `json:"service"`
+	Item    Item      `json:"item"`
+	Expires time.Time `json:"expires"`
+}
+
+func (e diskCacheEntry) expired() bool {
+	return !e.Expires.IsZero() && time.Now().After(e.Expires)
+}
+
+// NewDiskCachedKeyring returns a Keyring that caches items read from or written to kr in
+// cacheDir for ttl, a ttl of zero keeps them until they are removed. Unexpired entries are
+// loaded when it is created, so a new process can serve items without going to kr.
+//
+// Entries are namespaced by service, usually Config.ServiceName, so keyrings for different
+// services can share a cache directory without serving each other's items.
+//
+// Cache files are encrypted with a key derived from the hostname and user ID. This stops
+// one user's cache from being planted in another's, but anyone who can read the files on
+// the same host as the same user can decrypt them.
+func NewDiskCachedKeyring(kr Keyring, service, cacheDir string, ttl time.Duration) (Keyring, error) {
+	dir, err := ensureDir(cacheDir)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := diskCacheCipher()
+	if err != nil {
+		return nil, err
+	}
+
+	k := &diskCachedKeyring{
+		kr:      kr,
+		service: service,
+		dir:     dir,
+		ttl:     ttl,
+		gcm:     gcm,
+		entries: map[string]diskCacheEntry{},
+	}
+	k.load()
+
+	return k, nil
+}
+
+func diskCacheCipher() (cipher.AEAD, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return nil, err
+	}
+
+	key := sha256.Sum256([]byte("keyring disk cache\x00" + hostname + "\x00" + strconv.Itoa(os.Getuid())))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func (k *diskCachedKeyring) filename(key string) string {
+	return filepath.Join(k.dir, ETag([]byte(k.service+"\x00"+key))+diskCacheExt)
+}
+
+// load reads the service's unexpired entries in the cache directory, removing expired or
+// unreadable ones
+func (k *diskCachedKeyring) load() {
+	files, err := os.ReadDir(k.dir)
+	if err != nil {
+		Debugf("Failed to read cache directory %s: %v", k.dir, err)
+		return
+	}
+
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), diskCacheExt) {
+			continue
+		}
+
+		path := filepath.Join(k.dir, f.Name())
+		entry, err := k.readFile(path)
+		if err == nil && entry.Service != k.service {
+			continue
+		}
+		if err != nil || entry.expired() || path != k.filename(entry.Item.Key) {
+			Debugf("Discarding cache file %s", path)
+			_ = os.Remove(path)
+			continue
+		}
+		k.entries[entry.Item.Key] = entry
+	}
+
+	Debugf("Loaded %d items from cache directory %s", len(k.entries), k.dir)
+}
+
+func (k *diskCachedKeyring) readFile(path string) (diskCacheEntry, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return diskCacheEntry{}, err
+	}
+	if len(b) < k.gcm.NonceSize() {
+		return diskCacheEntry{}, ErrCorrupted
+	}
+
+	plaintext, err := k.gcm.Open(nil, b[:k.gcm.NonceSize()], b[k.gcm.NonceSize():], nil)
+	if err != nil {
+		return diskCacheEntry{}, ErrCorrupted
+	}
+
+	var entry diskCacheEntry
+	err = json.Unmarshal(plaintext, &entry)
+	return entry, err
+}
+
+func (k *diskCachedKeyring) add(item Item) {
+	entry := diskCacheEntry{Service: k.service, Item: item}
+	if k.ttl > 0 {
+		entry.Expires = time.Now().Add(k.ttl)
+	}
+
+	k.mu.Lock()
+	k.entries[item.Key] = entry
+	k.mu.Unlock()
+
+	plaintext, err := json.Marshal(entry)
+	if err == nil {
+		nonce := make([]byte, k.gcm.NonceSize())
+		if _, err = rand.Read(nonce); err == nil {
+			err = os.WriteFile(k.filename(item.Key), k.gcm.Seal(nonce, nonce, plaintext, nil), 0600)
+		}
+	}
+	if err != nil {
+		Debugf("Failed to write %q to the cache: %v", item.Key, err)
+	}
+}
+
+func (k *diskCachedKeyring) forget(key string) {
+	k.mu.Lock()
+	delete(k.entries, key)
+	k.mu.Unlock()
+
+	if err := os.Remove(k.filename(key)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		Debugf("Failed to remove %q from the cache: %v", key, err)
+	}
+}
+
+func (k *diskCachedKeyring) Get(key string) (Item, error) {
+	k.mu.Lock()
+	entry, ok := k.entries[key]
+	k.mu.Unlock()
+
+	if ok {
+		if !entry.expired() {
+			return checkActive(entry.Item)
+		}
+		Debugf("Cached item %q has expired", key)
+		k.forget(key)
+	}
+
+	item, err := k.kr.Get(key)
+	if err != nil {
+		return Item{}, err
+	}
+	k.add(item)
+
+	return item, nil
+}
+
+func (k *diskCachedKeyring) GetMetadata(key string) (Metadata, error) {
+	return k.kr.GetMetadata(key)
+}
+
+func (k *diskCachedKeyring) Set(item Item) error {
+	if err := k.kr.Set(item); err != nil {
+		k.forget(item.Key)
+		return err
+	}
+	k.add(item)
+	return nil
+}
+
+func (k *diskCachedKeyring) Remove(key string) error {
+	k.forget(key)
+	return k.kr.Remove(key)
+}
+
+func (k *diskCachedKeyring) Keys() ([]string, error) {
+	return k.kr.Keys()
+}
@@ -0,0 +1,118 @@
+package keyring
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDiskCachedKeyringSurvivesRestart(t *testing.T) {
+	dir := t.TempDir()
+
+	k, err := NewDiskCachedKeyring(&ArrayKeyring{}, "test", dir, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = k.Set(Item{Key: "llamas", Data: []byte("llamas are great")}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A new process with an empty backing keyring is served from the cache
+	k, err = NewDiskCachedKeyring(&ArrayKeyring{}, "test", dir, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	foundItem, err := k.Get("llamas")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(foundItem.Data) != "llamas are great" {
+		t.Fatalf("Value stored was not the value retrieved: %q", foundItem.Data)
+	}
+}
+
+func TestDiskCachedKeyringDiscardsExpiredItems(t *testing.T) {
+	dir := t.TempDir()
+
+	k, err := NewDiskCachedKeyring(&ArrayKeyring{}, "test", dir, time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = k.Set(Item{Key: "llamas", Data: []byte("llamas are great")}); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	k, err = NewDiskCachedKeyring(&ArrayKeyring{}, "test", dir, time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = k.Get("llamas"); err != ErrKeyNotFound {
+		t.Fatalf("Expected ErrKeyNotFound, got: %v", err)
+	}
+
+	files, _ := os.ReadDir(dir)
+	if len(files) != 0 {
+		t.Fatalf("Expected expired cache file to be removed, found %d files", len(files))
+	}
+}
+
+func TestDiskCachedKeyringIgnoresTamperedFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	k, err := NewDiskCachedKeyring(&ArrayKeyring{}, "test", dir, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = k.Set(Item{Key: "llamas", Data: []byte("llamas are great")}); err != nil {
+		t.Fatal(err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*"+diskCacheExt))
+	if len(files) != 1 {
+		t.Fatalf("Expected 1 cache file, found %d", len(files))
+	}
+	b, _ := os.ReadFile(files[0])
+	b[len(b)-1] ^= 0xff
+	if err = os.WriteFile(files[0], b, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	k, err = NewDiskCachedKeyring(&ArrayKeyring{}, "test", dir, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = k.Get("llamas"); err != ErrKeyNotFound {
+		t.Fatalf("Expected ErrKeyNotFound, got: %v", err)
+	}
+}
+
+func TestDiskCachedKeyringSeparatesServices(t *testing.T) {
+	dir := t.TempDir()
+
+	k, err := NewDiskCachedKeyring(&ArrayKeyring{}, "llamas", dir, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = k.Set(Item{Key: "password", Data: []byte("llamas are great")}); err != nil {
+		t.Fatal(err)
+	}
+
+	other, err := NewDiskCachedKeyring(&ArrayKeyring{}, "alpacas", dir, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = other.Get("password"); err != ErrKeyNotFound {
+		t.Fatalf("Expected ErrKeyNotFound, got: %v", err)
+	}
+
+	// The other service's entries are left in place
+	k, err = NewDiskCachedKeyring(&ArrayKeyring{}, "llamas", dir, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = k.Get("password"); err != nil {
+		t.Fatal(err)
+	}
+}
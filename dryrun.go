@@ -0,0 +1,91 @@
+package keyring
+
+import (
+	"errors"
+	"fmt"
+)
+
+// DryRunError is returned by a keyring from DryRunKeyring when an operation would fail
+type DryRunError struct {
+	Op  string
+	Key string
+	Err error
+}
+
+func (e *DryRunError) Error() string {
+	return fmt.Sprintf("dry run of %s %q failed: %v", e.Op, e.Key, e.Err)
+}
+
+// Unwrap returns the underlying reason for the failure
+func (e *DryRunError) Unwrap() error {
+	return e.Err
+}
+
+type dryRunKeyring struct {
+	kr Keyring
+}
+
+// DryRunKeyring wraps kr so that Set and Remove only check whether they would succeed,
+// without changing anything. Set validates the item, including against the
+// Config.MaxItemDataSize and Config.SchemaValidators that kr was opened with, and Remove
+// checks that the item exists. Failures are returned as a *DryRunError. Get, GetMetadata
+// and Keys are passed through unchanged.
+func DryRunKeyring(kr Keyring) Keyring {
+	return &dryRunKeyring{kr: kr}
+}
+
+func (k *dryRunKeyring) Get(key string) (Item, error) {
+	return k.kr.Get(key)
+}
+
+func (k *dryRunKeyring) GetMetadata(key string) (Metadata, error) {
+	return k.kr.GetMetadata(key)
+}
+
+func (k *dryRunKeyring) Set(item Item) error {
+	if item.Key == "" {
+		return &DryRunError{Op: OpSet, Key: item.Key, Err: errors.New("item has no key")}
+	}
+	if err := validateSet(k.kr, item); err != nil {
+		return &DryRunError{Op: OpSet, Key: item.Key, Err: err}
+	}
+
+	Debugf("Dry run: would set %q", item.Key)
+	return nil
+}
+
+// validateSet applies the checks that the wrappers around kr make on Set, without passing
+// item on to the backend
+func validateSet(kr Keyring, item Item) error {
+	for {
+		switch k := kr.(type) {
+		case *ClearOnExitKeyring:
+			kr = k.Keyring
+		case *sizeLimitKeyring:
+			if err := k.validate(item); err != nil {
+				return err
+			}
+			kr = k.Keyring
+		case *schemaKeyring:
+			if err := k.validate(item); err != nil {
+				return err
+			}
+			kr = k.Keyring
+		default:
+			return nil
+		}
+	}
+}
+
+func (k *dryRunKeyring) Remove(key string) error {
+	if _, err := k.kr.Get(key); err != nil {
+		return &DryRunError{Op: OpRemove, Key: key, Err: err}
+	}
+
+	Debugf("Dry run: would remove %q", key)
+	return nil
+}
+
+func (k *dryRunKeyring) Keys() ([]string, error) {
+	return k.kr.Keys()
+}
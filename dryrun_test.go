@@ -0,0 +1,59 @@
+package keyring
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDryRunKeyringSetDoesNotWrite(t *testing.T) {
+	backing := &ArrayKeyring{}
+	k := DryRunKeyring(backing)
+
+	if err := k.Set(Item{Key: "llamas", Data: []byte("llamas are great")}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := backing.Get("llamas"); err != ErrKeyNotFound {
+		t.Fatal("Expected dry run Set not to write the item")
+	}
+
+	var dryRunErr *DryRunError
+	if err := k.Set(Item{Data: []byte("llamas are great")}); !errors.As(err, &dryRunErr) {
+		t.Fatalf("Expected a DryRunError for an item without a key, got: %v", err)
+	}
+}
+
+func TestDryRunKeyringRemove(t *testing.T) {
+	backing := NewArrayKeyring([]Item{{Key: "llamas", Data: []byte("llamas are great")}})
+	k := DryRunKeyring(backing)
+
+	if err := k.Remove("llamas"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := backing.Get("llamas"); err != nil {
+		t.Fatal("Expected dry run Remove not to delete the item")
+	}
+
+	if err := k.Remove("alpacas"); !errors.Is(err, ErrKeyNotFound) {
+		t.Fatalf("Expected ErrKeyNotFound for a missing item, got: %v", err)
+	}
+}
+
+func TestDryRunKeyringSetValidates(t *testing.T) {
+	k := DryRunKeyring(wrap(&ArrayKeyring{}, Config{
+		MaxItemDataSize:  64,
+		SchemaValidators: map[string]JSONSchema{"db": dbCredentialSchema},
+		ClearOnExit:      true,
+	}))
+
+	var tooLarge *ErrItemTooLarge
+	if err := k.Set(Item{Key: "llamas", Data: make([]byte, 65)}); !errors.As(err, &tooLarge) {
+		t.Fatalf("Expected ErrItemTooLarge, got: %v", err)
+	}
+	var ve *ValidationError
+	if err := k.Set(Item{Key: "db", Data: []byte(`{"username": "llama"}`)}); !errors.As(err, &ve) {
+		t.Fatalf("Expected a ValidationError, got: %v", err)
+	}
+	if err := k.Set(Item{Key: "llamas", Data: []byte("llamas are great")}); err != nil {
+		t.Fatal(err)
+	}
+}
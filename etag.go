@@ -0,0 +1,103 @@
+package keyring
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+)
+
+// ErrConflict is returned by EtagKeyring's conditional operations when the item has
+// changed since the caller read it
+type ErrConflict struct {
+	Key string
+	// CurrentETag is the ETag of the item now, empty if it doesn't exist
+	CurrentETag string
+}
+
+func (e *ErrConflict) Error() string {
+	if e.CurrentETag == "" {
+		return fmt.Sprintf("Item %q has been removed", e.Key)
+	}
+	return fmt.Sprintf("Item %q has changed, its ETag is now %s", e.Key, e.CurrentETag)
+}
+
+// EtagKeyring adds optimistic concurrency control to another Keyring. Items returned by
+// Get carry an ETag, a hash of their data, in Tags["etag"], which can be passed to
+// ConditionalSet or ConditionalRemove to only change the item if nobody else has since.
+//
+// Conditional operations are serialized within the process, but the check and the write
+// are separate calls to the backend, so writers in other processes can still interleave.
+type EtagKeyring struct {
+	Keyring
+	mu sync.Mutex
+}
+
+// NewEtagKeyring returns an EtagKeyring wrapping kr
+func NewEtagKeyring(kr Keyring) *EtagKeyring {
+	return &EtagKeyring{Keyring: kr}
+}
+
+// ETag returns the ETag of data
+func ETag(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Get returns the item with its ETag in Tags["etag"]
+func (k *EtagKeyring) Get(key string) (Item, error) {
+	item, err := k.Keyring.Get(key)
+	if err != nil {
+		return Item{}, err
+	}
+
+	tags := map[string]string{}
+	for name, value := range item.Tags {
+		tags[name] = value
+	}
+	tags["etag"] = ETag(item.Data)
+	item.Tags = tags
+
+	return item, nil
+}
+
+// check returns ErrConflict unless the ETag of key is expectedEtag. An empty expectedEtag
+// means the item must not exist.
+func (k *EtagKeyring) check(key, expectedEtag string) error {
+	var current string
+	item, err := k.Keyring.Get(key)
+	if err == nil {
+		current = ETag(item.Data)
+	} else if err != ErrKeyNotFound {
+		return err
+	}
+
+	if current != expectedEtag {
+		Debugf("ETag of %q is %q, expected %q", key, current, expectedEtag)
+		return &ErrConflict{Key: key, CurrentETag: current}
+	}
+	return nil
+}
+
+// ConditionalSet stores item if the ETag of the existing item is expectedEtag, or if there
+// is no existing item and expectedEtag is empty. Otherwise it returns ErrConflict.
+func (k *EtagKeyring) ConditionalSet(item Item, expectedEtag string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if err := k.check(item.Key, expectedEtag); err != nil {
+		return err
+	}
+	return k.Keyring.Set(item)
+}
+
+// ConditionalRemove removes key if its ETag is expectedEtag, otherwise it returns ErrConflict
+func (k *EtagKeyring) ConditionalRemove(key, expectedEtag string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if err := k.check(key, expectedEtag); err != nil {
+		return err
+	}
+	return k.Keyring.Remove(key)
+}
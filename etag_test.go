@@ -0,0 +1,58 @@
+package keyring
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEtagKeyringConditionalSet(t *testing.T) {
+	k := NewEtagKeyring(&ArrayKeyring{})
+
+	if err := k.ConditionalSet(Item{Key: "llamas", Data: []byte("llamas are great")}, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	item, err := k.Get("llamas")
+	if err != nil {
+		t.Fatal(err)
+	}
+	etag := item.Tags["etag"]
+	if etag != ETag([]byte("llamas are great")) {
+		t.Fatalf("Unexpected ETag: %q", etag)
+	}
+
+	if err = k.ConditionalSet(Item{Key: "llamas", Data: []byte("llamas are grand")}, etag); err != nil {
+		t.Fatal(err)
+	}
+
+	// A second writer holding the old ETag loses
+	err = k.ConditionalSet(Item{Key: "llamas", Data: []byte("llamas are ok")}, etag)
+	var conflict *ErrConflict
+	if !errors.As(err, &conflict) {
+		t.Fatalf("Expected ErrConflict, got: %v", err)
+	}
+	if conflict.CurrentETag != ETag([]byte("llamas are grand")) {
+		t.Fatalf("Unexpected current ETag: %q", conflict.CurrentETag)
+	}
+
+	// Creating an item that already exists conflicts too
+	if err = k.ConditionalSet(Item{Key: "llamas"}, ""); !errors.As(err, &conflict) {
+		t.Fatalf("Expected ErrConflict, got: %v", err)
+	}
+}
+
+func TestEtagKeyringConditionalRemove(t *testing.T) {
+	k := NewEtagKeyring(NewArrayKeyring([]Item{{Key: "llamas", Data: []byte("llamas are great")}}))
+
+	var conflict *ErrConflict
+	if err := k.ConditionalRemove("llamas", ETag([]byte("alpacas"))); !errors.As(err, &conflict) {
+		t.Fatalf("Expected ErrConflict, got: %v", err)
+	}
+
+	if err := k.ConditionalRemove("llamas", ETag([]byte("llamas are great"))); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := k.Get("llamas"); err != ErrKeyNotFound {
+		t.Fatalf("Expected ErrKeyNotFound, got: %v", err)
+	}
+}
@@ -1,6 +1,10 @@
 package keyring
 
 import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -10,15 +14,38 @@ import (
 	"time"
 
 	jose "github.com/dvsekhvalnov/jose2go"
+	"github.com/klauspost/compress/zstd"
 	homedir "github.com/mitchellh/go-homedir"
 )
 
+// defaultFileCompressionThreshold is the item data size above which items are compressed
+// when Config.FileCompressionThreshold isn't set
+const defaultFileCompressionThreshold = 1024
+
+// Encrypted payloads may start with one of these bytes to describe how the serialised item
+// is encoded. Payloads written before compression was supported are plain JSON, starting
+// with '{', and are still written that way when not compressed so older versions can read them.
+const (
+	payloadRaw  byte = 0x00
+	payloadZstd byte = 0x01
+)
+
 func init() {
 	supportedBackends[FileBackend] = opener(func(cfg Config) (Keyring, error) {
-		return &fileKeyring{
-			dir:          cfg.FileDir,
-			passwordFunc: cfg.FilePasswordFunc,
-		}, nil
+		k := &fileKeyring{
+			dir:                  cfg.FileDir,
+			passwordFunc:         cfg.FilePasswordFunc,
+			compressionThreshold: cfg.FileCompressionThreshold,
+			compressionLevel:     zstd.SpeedDefault,
+			hmacKey:              cfg.HMACKey,
+		}
+		if k.compressionThreshold == 0 {
+			k.compressionThreshold = defaultFileCompressionThreshold
+		}
+		if cfg.FileCompressionLevel != 0 {
+			k.compressionLevel = zstd.EncoderLevelFromZstd(cfg.FileCompressionLevel)
+		}
+		return k, nil
 	})
 }
 
@@ -26,6 +53,11 @@ type fileKeyring struct {
 	dir          string
 	passwordFunc PromptFunc
 	password     string
+
+	compressionThreshold int
+	compressionLevel     zstd.EncoderLevel
+
+	hmacKey []byte
 }
 
 func (k *fileKeyring) resolveDir() (string, error) {
@@ -33,16 +65,55 @@ func (k *fileKeyring) resolveDir() (string, error) {
 		return "", fmt.Errorf("No directory provided for file keyring")
 	}
 
-	dir := k.dir
+	return ensureDir(k.dir)
+}
 
-	// expand tilde for home directory
-	if strings.HasPrefix(dir, "~") {
-		home, err := homedir.Dir()
+// itemPath returns the file in dir that key is stored in. Keys containing a /, such as
+// namespaced keys, are stored in subdirectories.
+func itemPath(dir, key string) string {
+	return filepath.Join(dir, filepath.FromSlash(key))
+}
+
+// walkItems calls fn with the key and info of every item file in dir and its subdirectories
+func walkItems(dir string, fn func(key string, info os.FileInfo)) error {
+	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
-			return "", err
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
 		}
-		dir = strings.Replace(dir, "~", home, 1)
-		debugf("Expanded file dir to %s", dir)
+		fn(filepath.ToSlash(rel), info)
+		return nil
+	})
+}
+
+// expandTilde replaces a leading ~ in path with the home directory
+func expandTilde(path string) (string, error) {
+	if !strings.HasPrefix(path, "~") {
+		return path, nil
+	}
+
+	home, err := homedir.Dir()
+	if err != nil {
+		return "", err
+	}
+	path = strings.Replace(path, "~", home, 1)
+	Debugf("Expanded path to %s", path)
+
+	return path, nil
+}
+
+// ensureDir expands a leading ~ in dir to the home directory and creates it if needed
+func ensureDir(dir string) (string, error) {
+	dir, err := expandTilde(dir)
+	if err != nil {
+		return "", err
 	}
 
 	stat, err := os.Stat(dir)
@@ -75,42 +146,54 @@ func (k *fileKeyring) unlock() error {
 func (k *fileKeyring) Get(key string) (Item, error) {
 	dir, err := k.resolveDir()
 	if err != nil {
-		return Item{}, err
+		return Item{}, WrapError(err, FileBackend, k.dir, key)
 	}
 
-	bytes, err := ioutil.ReadFile(filepath.Join(dir, key))
+	bytes, err := ioutil.ReadFile(itemPath(dir, key))
 	if os.IsNotExist(err) {
 		return Item{}, ErrKeyNotFound
 	} else if err != nil {
-		return Item{}, err
+		return Item{}, WrapError(err, FileBackend, k.dir, key)
 	}
 
 	if err = k.unlock(); err != nil {
-		return Item{}, err
+		return Item{}, WrapError(err, FileBackend, k.dir, key)
+	}
+
+	payload, _, err := jose.DecodeBytes(string(bytes), k.password)
+	if err != nil {
+		return Item{}, WrapError(err, FileBackend, k.dir, key)
 	}
 
-	payload, _, err := jose.Decode(string(bytes), k.password)
+	payload, err = decodePayload(payload)
 	if err != nil {
-		return Item{}, err
+		return Item{}, WrapError(err, FileBackend, k.dir, key)
+	}
+
+	if k.hmacKey != nil && !verifyHMAC(payload, k.hmacKey) {
+		Debugf("HMAC of %q doesn't match", key)
+		return Item{}, ErrCorrupted
 	}
 
 	var decoded Item
-	err = json.Unmarshal([]byte(payload), &decoded)
+	if err = json.Unmarshal(payload, &decoded); err != nil {
+		return Item{}, WrapError(err, FileBackend, k.dir, key)
+	}
 
-	return decoded, err
+	return checkActive(decoded)
 }
 
 func (k *fileKeyring) GetMetadata(key string) (Metadata, error) {
 	dir, err := k.resolveDir()
 	if err != nil {
-		return Metadata{}, err
+		return Metadata{}, WrapError(err, FileBackend, k.dir, key)
 	}
 
-	stat, err := os.Stat(filepath.Join(dir, key))
+	stat, err := os.Stat(itemPath(dir, key))
 	if os.IsNotExist(err) {
 		return Metadata{}, ErrKeyNotFound
 	} else if err != nil {
-		return Metadata{}, err
+		return Metadata{}, WrapError(err, FileBackend, k.dir, key)
 	}
 
 	// For the File provider, all internal data is encrypted, not just the
@@ -125,51 +208,243 @@ func (k *fileKeyring) GetMetadata(key string) (Metadata, error) {
 	}, nil
 }
 
-func (k *fileKeyring) Set(i Item) error {
+func (k *fileKeyring) encrypt(i Item) (string, error) {
+	if err := ValidateRotationSchedule(i); err != nil {
+		return "", err
+	}
+
 	bytes, err := json.Marshal(i)
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	dir, err := k.resolveDir()
-	if err != nil {
-		return err
+	if k.hmacKey != nil {
+		bytes = appendHMAC(bytes, k.hmacKey)
+	}
+
+	if k.compressionThreshold > 0 && len(i.Data) > k.compressionThreshold {
+		if bytes, err = compressPayload(bytes, k.compressionLevel); err != nil {
+			return "", err
+		}
+		Debugf("Compressed %q to %d bytes", i.Key, len(bytes))
 	}
 
 	if err = k.unlock(); err != nil {
-		return err
+		return "", err
 	}
 
-	token, err := jose.Encrypt(string(bytes), jose.PBES2_HS256_A128KW, jose.A256GCM, k.password,
+	return jose.EncryptBytes(bytes, jose.PBES2_HS256_A128KW, jose.A256GCM, k.password,
 		jose.Headers(map[string]interface{}{
 			"created": time.Now().String(),
 		}))
+}
+
+// hmacField is appended as the last field of a serialised item when it is signed. Readers
+// without the key ignore it, as Item has no such field.
+const hmacField = `,"hmac":"`
+
+// appendHMAC adds the HMAC-SHA256 of the serialised item as a trailing "hmac" field
+func appendHMAC(payload []byte, key []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(payload)
+
+	signed := append([]byte{}, payload[:len(payload)-1]...)
+	signed = append(signed, hmacField...)
+	signed = append(signed, hex.EncodeToString(mac.Sum(nil))...)
+	return append(signed, `"}`...)
+}
+
+// verifyHMAC checks the trailing "hmac" field against the rest of the serialised item
+func verifyHMAC(payload []byte, key []byte) bool {
+	idx := bytes.LastIndex(payload, []byte(hmacField))
+	if idx < 0 || !bytes.HasSuffix(payload, []byte(`"}`)) {
+		return false
+	}
+
+	expected, err := hex.DecodeString(string(payload[idx+len(hmacField) : len(payload)-2]))
 	if err != nil {
-		return err
+		return false
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write(payload[:idx])
+	mac.Write([]byte("}"))
+	return hmac.Equal(mac.Sum(nil), expected)
+}
+
+func compressPayload(payload []byte, level zstd.EncoderLevel) ([]byte, error) {
+	enc, err := zstd.NewWriter(nil, zstd.WithEncoderLevel(level))
+	if err != nil {
+		return nil, err
 	}
+	defer enc.Close()
 
-	return ioutil.WriteFile(filepath.Join(dir, i.Key), []byte(token), 0600)
+	return enc.EncodeAll(payload, []byte{payloadZstd}), nil
+}
+
+func decodePayload(payload []byte) ([]byte, error) {
+	if len(payload) == 0 {
+		return payload, nil
+	}
+
+	switch payload[0] {
+	case payloadRaw:
+		return payload[1:], nil
+	case payloadZstd:
+		dec, err := zstd.NewReader(nil)
+		if err != nil {
+			return nil, err
+		}
+		defer dec.Close()
+		return dec.DecodeAll(payload[1:], nil)
+	default:
+		return payload, nil
+	}
+}
+
+func (k *fileKeyring) Set(i Item) error {
+	dir, err := k.resolveDir()
+	if err != nil {
+		return WrapError(err, FileBackend, k.dir, i.Key)
+	}
+
+	token, err := k.encrypt(i)
+	if err != nil {
+		return WrapError(err, FileBackend, k.dir, i.Key)
+	}
+
+	path := itemPath(dir, i.Key)
+	if err = os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return WrapError(err, FileBackend, k.dir, i.Key)
+	}
+	return WrapError(ioutil.WriteFile(path, []byte(token), 0600), FileBackend, k.dir, i.Key)
+}
+
+// SetIfNotExists stores the item only if there isn't already a file for its key, relying
+// on O_EXCL so that concurrent writers can't both succeed
+func (k *fileKeyring) SetIfNotExists(i Item) (bool, error) {
+	dir, err := k.resolveDir()
+	if err != nil {
+		return false, WrapError(err, FileBackend, k.dir, i.Key)
+	}
+
+	token, err := k.encrypt(i)
+	if err != nil {
+		return false, WrapError(err, FileBackend, k.dir, i.Key)
+	}
+
+	path := itemPath(dir, i.Key)
+	if err = os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return false, WrapError(err, FileBackend, k.dir, i.Key)
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if os.IsExist(err) {
+		return false, nil
+	} else if err != nil {
+		return false, WrapError(err, FileBackend, k.dir, i.Key)
+	}
+
+	if _, err = f.WriteString(token); err != nil {
+		f.Close()
+		return false, WrapError(err, FileBackend, k.dir, i.Key)
+	}
+
+	return true, WrapError(f.Close(), FileBackend, k.dir, i.Key)
 }
 
 func (k *fileKeyring) Remove(key string) error {
 	dir, err := k.resolveDir()
 	if err != nil {
-		return err
+		return WrapError(err, FileBackend, k.dir, key)
 	}
 
-	return os.Remove(filepath.Join(dir, key))
+	path := itemPath(dir, key)
+	if err = os.Remove(path); os.IsNotExist(err) {
+		return ErrKeyNotFound
+	} else if err != nil {
+		return WrapError(err, FileBackend, k.dir, key)
+	}
+
+	// Tidy up subdirectories left empty, which os.Remove refuses to remove otherwise
+	for parent := filepath.Dir(path); parent != dir && strings.HasPrefix(parent, dir); parent = filepath.Dir(parent) {
+		if os.Remove(parent) != nil {
+			break
+		}
+	}
+	return nil
 }
 
 func (k *fileKeyring) Keys() ([]string, error) {
 	dir, err := k.resolveDir()
 	if err != nil {
-		return nil, err
+		return nil, WrapError(err, FileBackend, k.dir, "")
 	}
 
 	var keys = []string{}
-	files, _ := ioutil.ReadDir(dir)
-	for _, f := range files {
-		keys = append(keys, f.Name())
+	err = walkItems(dir, func(key string, _ os.FileInfo) {
+		keys = append(keys, key)
+	})
+	if err != nil {
+		return nil, WrapError(err, FileBackend, k.dir, "")
+	}
+
+	return keys, nil
+}
+
+// indexMetadata reads the modification times of all items with a single directory walk
+func (k *fileKeyring) indexMetadata() (map[string]Metadata, error) {
+	dir, err := k.resolveDir()
+	if err != nil {
+		return nil, WrapError(err, FileBackend, k.dir, "")
+	}
+
+	index := map[string]Metadata{}
+	err = walkItems(dir, func(key string, info os.FileInfo) {
+		index[key] = Metadata{ModificationTime: info.ModTime()}
+	})
+	if err != nil {
+		return nil, WrapError(err, FileBackend, k.dir, "")
+	}
+	return index, nil
+}
+
+// searchKeys uses filepath.Glob to avoid listing every file in the directory. Globs are
+// case sensitive and treat some characters specially, and a * doesn't match the / in keys
+// stored in subdirectories, so other searches list everything.
+func (k *fileKeyring) searchKeys(query string, opts SearchOptions) ([]string, error) {
+	if !opts.CaseSensitive || strings.ContainsAny(query, `*?[\`) || !opts.Prefix {
+		return k.Keys()
+	}
+
+	dir, err := k.resolveDir()
+	if err != nil {
+		return nil, WrapError(err, FileBackend, k.dir, "")
+	}
+
+	matches, err := filepath.Glob(itemPath(dir, query+"*"))
+	if err != nil {
+		return nil, WrapError(err, FileBackend, k.dir, "")
+	}
+
+	var keys = []string{}
+	for _, m := range matches {
+		rel, err := filepath.Rel(dir, m)
+		if err != nil {
+			return nil, WrapError(err, FileBackend, k.dir, "")
+		}
+		prefix := filepath.ToSlash(rel)
+
+		// A matching subdirectory holds keys that start with the query too
+		err = walkItems(m, func(key string, _ os.FileInfo) {
+			if key == "." {
+				keys = append(keys, prefix)
+			} else {
+				keys = append(keys, prefix+"/"+key)
+			}
+		})
+		if err != nil {
+			return nil, WrapError(err, FileBackend, k.dir, "")
+		}
 	}
 
 	return keys, nil
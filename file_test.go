@@ -1,8 +1,16 @@
 package keyring
 
 import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
+
+	jose "github.com/dvsekhvalnov/jose2go"
+	"github.com/klauspost/compress/zstd"
 )
 
 func TestFileKeyringSetWhenEmpty(t *testing.T) {
@@ -29,3 +37,186 @@ func TestFileKeyringSetWhenEmpty(t *testing.T) {
 		t.Fatalf("Key wasn't persisted: %q", foundItem.Key)
 	}
 }
+
+func TestFileKeyringCompressesLargeItems(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keyring-file-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	k := &fileKeyring{
+		dir:                  dir,
+		passwordFunc:         fixedStringPrompt("no more secrets"),
+		compressionThreshold: 16,
+		compressionLevel:     zstd.SpeedDefault,
+	}
+	data := bytes.Repeat([]byte("llamas are great "), 100)
+
+	if err = k.Set(Item{Key: "llamas", Data: data}); err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := ioutil.ReadFile(filepath.Join(dir, "llamas"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, _, err := jose.DecodeBytes(string(token), "no more secrets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if payload[0] != payloadZstd || len(payload) >= len(data) {
+		t.Fatalf("Expected payload to be compressed, got %d bytes starting with %#x", len(payload), payload[0])
+	}
+
+	foundItem, err := k.Get("llamas")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(foundItem.Data, data) {
+		t.Fatal("Value stored was not the value retrieved")
+	}
+}
+
+func TestFileKeyringReadsRawPayloads(t *testing.T) {
+	for _, payload := range [][]byte{
+		[]byte(`{"Key":"llamas","Data":"bGxhbWFzIGFyZSBncmVhdA=="}`),
+		append([]byte{payloadRaw}, `{"Key":"llamas","Data":"bGxhbWFzIGFyZSBncmVhdA=="}`...),
+	} {
+		decoded, err := decodePayload(payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(decoded) != `{"Key":"llamas","Data":"bGxhbWFzIGFyZSBncmVhdA=="}` {
+			t.Fatalf("Unexpected decoded payload: %q", decoded)
+		}
+	}
+}
+
+func TestFileKeyringPersistsNotBefore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keyring-file-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	k := &fileKeyring{
+		dir:          dir,
+		passwordFunc: fixedStringPrompt("no more secrets"),
+	}
+	if err = k.Set(Item{Key: "llamas", NotBefore: time.Now().Add(time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = k.Get("llamas"); err != ErrNotYetActive {
+		t.Fatalf("Expected ErrNotYetActive, got: %v", err)
+	}
+}
+
+func TestFileKeyringDetectsTamperingWithHMAC(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keyring-file-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	k := &fileKeyring{
+		dir:          dir,
+		passwordFunc: fixedStringPrompt("no more secrets"),
+		hmacKey:      []byte("llama secrets"),
+	}
+	if err = k.Set(Item{Key: "llamas", Data: []byte("llamas are great")}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = k.Get("llamas"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Someone who knows the password, but not the HMAC key, replaces the item
+	token, err := jose.EncryptBytes([]byte(`{"Key":"llamas","Data":"YWxwYWNhcw=="}`),
+		jose.PBES2_HS256_A128KW, jose.A256GCM, "no more secrets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = ioutil.WriteFile(filepath.Join(dir, "llamas"), []byte(token), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = k.Get("llamas"); err != ErrCorrupted {
+		t.Fatalf("Expected ErrCorrupted, got: %v", err)
+	}
+}
+
+func TestFileKeyringHMACField(t *testing.T) {
+	signed := appendHMAC([]byte(`{"Key":"llamas"}`), []byte("llama secrets"))
+	if !verifyHMAC(signed, []byte("llama secrets")) {
+		t.Fatalf("Expected HMAC to verify: %s", signed)
+	}
+	if verifyHMAC(signed, []byte("alpaca secrets")) {
+		t.Fatal("Expected HMAC with a different key not to verify")
+	}
+
+	var item Item
+	if err := json.Unmarshal(signed, &item); err != nil || item.Key != "llamas" {
+		t.Fatalf("Expected signed item to decode without the key: %v", err)
+	}
+}
+
+func TestFileKeyringNamespaces(t *testing.T) {
+	dir := t.TempDir()
+	k, err := Open(Config{
+		AllowedBackends:  []BackendType{FileBackend},
+		FileDir:          dir,
+		FilePasswordFunc: fixedStringPrompt("no more secrets"),
+		Namespaces:       true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = k.Set(Item{Key: "llamas", Data: []byte("llamas are great")}); err != nil {
+		t.Fatal(err)
+	}
+	if err = k.Set(Item{Key: "alpacas", Namespace: "farm", Data: []byte("alpacas are too")}); err != nil {
+		t.Fatal(err)
+	}
+
+	item, err := k.Get("llamas")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(item.Data) != "llamas are great" {
+		t.Fatalf("Unexpected data %q", item.Data)
+	}
+
+	keys, err := k.Keys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 1 || keys[0] != "llamas" {
+		t.Fatalf("Expected only keys in the default namespace, got: %v", keys)
+	}
+
+	namespaces, err := ListNamespaces(k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(namespaces) != 2 || namespaces[0] != DefaultNamespace || namespaces[1] != "farm" {
+		t.Fatalf("Unexpected namespaces: %v", namespaces)
+	}
+
+	found, err := SearchKeys(namespaced(k), "farm/al", SearchOptions{Prefix: true, CaseSensitive: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 1 || found[0] != "farm/alpacas" {
+		t.Fatalf("Unexpected search results: %v", found)
+	}
+
+	if err = k.Remove("llamas"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = os.Stat(filepath.Join(dir, DefaultNamespace)); !os.IsNotExist(err) {
+		t.Fatalf("Expected the empty namespace directory to be removed, got: %v", err)
+	}
+}
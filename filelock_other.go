@@ -0,0 +1,15 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !windows
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!windows
+
+package keyring
+
+import "os"
+
+// lockFile does nothing on platforms without file locking
+func lockFile(_ *os.File, _ bool) error {
+	return nil
+}
+
+func unlockFile(_ *os.File) error {
+	return nil
+}
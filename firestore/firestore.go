@@ -0,0 +1,209 @@
+// Package firestore stores keyring items as documents in a Google Cloud Firestore
+// collection, for applications that keep the rest of their data there.
+//
+// Each item is a document whose ID is the item's key, with the fields data (base64
+// encoded), label, description and updated_at. Importing the package registers it with
+// keyring.Open as the "firestore" backend, which uses a collection named after
+// Config.ServiceName in the project detected from the environment. Set
+// FIRESTORE_EMULATOR_HOST to use the Firestore emulator.
+package firestore
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"time"
+
+	gcfirestore "cloud.google.com/go/firestore"
+	"github.com/99designs/keyring"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Backend is the name that the backend is registered with keyring.Open under
+const Backend keyring.BackendType = "firestore"
+
+func init() {
+	keyring.RegisterBackend(Backend, func(cfg keyring.Config) (keyring.Keyring, error) {
+		return New(context.Background(), Config{Collection: cfg.ServiceName})
+	})
+}
+
+// Config configures access to Firestore
+type Config struct {
+	// ProjectID is the Google Cloud project, detected from the credentials if empty
+	ProjectID string
+	// Collection is the collection that items are stored in
+	Collection string
+	// Client is the Firestore client to use. If nil a client is created with the
+	// application default credentials.
+	Client *gcfirestore.Client
+	// Timeout bounds each operation. Zero means 30 seconds.
+	Timeout time.Duration
+}
+
+// FirestoreBackend is a keyring stored in a Firestore collection
+type FirestoreBackend struct {
+	client     *gcfirestore.Client
+	collection *gcfirestore.CollectionRef
+	timeout    time.Duration
+}
+
+// document is how an item is stored in Firestore
+type document struct {
+	Data        string    `firestore:"data"`
+	Label       string    `firestore:"label"`
+	Description string    `firestore:"description"`
+	UpdatedAt   time.Time `firestore:"updated_at"`
+}
+
+// New returns a FirestoreBackend for the collection described by cfg
+func New(ctx context.Context, cfg Config) (*FirestoreBackend, error) {
+	if cfg.Collection == "" {
+		return nil, errors.New("No Firestore collection provided")
+	}
+
+	client := cfg.Client
+	if client == nil {
+		projectID := cfg.ProjectID
+		if projectID == "" {
+			projectID = gcfirestore.DetectProjectID
+		}
+
+		var err error
+		client, err = gcfirestore.NewClient(ctx, projectID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	b := &FirestoreBackend{
+		client:     client,
+		collection: client.Collection(cfg.Collection),
+		timeout:    cfg.Timeout,
+	}
+	if b.timeout == 0 {
+		b.timeout = 30 * time.Second
+	}
+	return b, nil
+}
+
+func (b *FirestoreBackend) context() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), b.timeout)
+}
+
+// doc returns the document for key. Keys are escaped, as document IDs can't contain a /.
+func (b *FirestoreBackend) doc(key string) *gcfirestore.DocumentRef {
+	return b.collection.Doc(url.PathEscape(key))
+}
+
+func (b *FirestoreBackend) get(key string) (document, time.Time, error) {
+	ctx, cancel := b.context()
+	defer cancel()
+
+	snap, err := b.doc(key).Get(ctx)
+	if status.Code(err) == codes.NotFound {
+		return document{}, time.Time{}, keyring.ErrKeyNotFound
+	} else if err != nil {
+		return document{}, time.Time{}, err
+	}
+
+	var d document
+	if err = snap.DataTo(&d); err != nil {
+		return document{}, time.Time{}, err
+	}
+	return d, snap.UpdateTime, nil
+}
+
+func (b *FirestoreBackend) Get(key string) (keyring.Item, error) {
+	d, _, err := b.get(key)
+	if err != nil {
+		return keyring.Item{}, err
+	}
+
+	data, err := base64.StdEncoding.DecodeString(d.Data)
+	if err != nil {
+		return keyring.Item{}, err
+	}
+
+	return keyring.Item{
+		Key:         key,
+		Data:        data,
+		Label:       d.Label,
+		Description: d.Description,
+	}, nil
+}
+
+func (b *FirestoreBackend) GetMetadata(key string) (keyring.Metadata, error) {
+	d, updated, err := b.get(key)
+	if err != nil {
+		return keyring.Metadata{}, err
+	}
+
+	return keyring.Metadata{
+		Item: &keyring.Item{
+			Key:         key,
+			Label:       d.Label,
+			Description: d.Description,
+		},
+		ModificationTime: updated,
+	}, nil
+}
+
+func (b *FirestoreBackend) Set(item keyring.Item) error {
+	ctx, cancel := b.context()
+	defer cancel()
+
+	_, err := b.doc(item.Key).Set(ctx, document{
+		Data:        base64.StdEncoding.EncodeToString(item.Data),
+		Label:       item.Label,
+		Description: item.Description,
+		UpdatedAt:   time.Now(),
+	})
+	return err
+}
+
+func (b *FirestoreBackend) Remove(key string) error {
+	ctx, cancel := b.context()
+	defer cancel()
+
+	_, err := b.doc(key).Delete(ctx, gcfirestore.Exists)
+	if status.Code(err) == codes.NotFound {
+		return keyring.ErrKeyNotFound
+	}
+	return err
+}
+
+func (b *FirestoreBackend) Keys() ([]string, error) {
+	ctx, cancel := b.context()
+	defer cancel()
+
+	// Selecting no fields lists the documents without reading their data
+	iter := b.collection.Select().Documents(ctx)
+	defer iter.Stop()
+
+	var keys = []string{}
+	for {
+		snap, err := iter.Next()
+		if err == iterator.Done {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		key, err := url.PathUnescape(snap.Ref.ID)
+		if err != nil {
+			keyring.Debugf("Skipping document %q: %v", snap.Ref.ID, err)
+			continue
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// Close closes the Firestore client
+func (b *FirestoreBackend) Close() error {
+	return b.client.Close()
+}
@@ -0,0 +1,58 @@
+package firestore
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/99designs/keyring"
+)
+
+// TestFirestoreBackend runs against the Firestore emulator, started with
+// gcloud emulators firestore start, when FIRESTORE_EMULATOR_HOST is set
+func TestFirestoreBackend(t *testing.T) {
+	if os.Getenv("FIRESTORE_EMULATOR_HOST") == "" {
+		t.Skip("FIRESTORE_EMULATOR_HOST is not set")
+	}
+
+	b, err := New(context.Background(), Config{ProjectID: "keyring-test", Collection: "keyring-test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+
+	item := keyring.Item{Key: "llamas/alpacas", Data: []byte("llamas are great"), Label: "Llamas"}
+	if err = b.Set(item); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := b.Get(item.Key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got.Data) != string(item.Data) || got.Label != item.Label {
+		t.Fatalf("Unexpected item: %+v", got)
+	}
+
+	keys, err := b.Keys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, k := range keys {
+		found = found || k == item.Key
+	}
+	if !found {
+		t.Fatalf("Expected %q in keys %v", item.Key, keys)
+	}
+
+	if err = b.Remove(item.Key); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = b.Get(item.Key); err != keyring.ErrKeyNotFound {
+		t.Fatalf("Expected ErrKeyNotFound, got: %v", err)
+	}
+	if err = b.Remove(item.Key); err != keyring.ErrKeyNotFound {
+		t.Fatalf("Expected ErrKeyNotFound, got: %v", err)
+	}
+}
@@ -0,0 +1,82 @@
+// Package flagvar provides flag.Value implementations whose values are kept on a keyring,
+// so that command line flags can default to a stored secret and be overridden for a
+// single invocation.
+//
+//	flag.Var(flagvar.NewString(kr, "api-token"), "api-token", "API token")
+package flagvar
+
+import (
+	"github.com/99designs/keyring"
+)
+
+const (
+	// NotSet is what a StringVar's String method returns when the flag wasn't given on the
+	// command line
+	NotSet = "<not set>"
+
+	// Masked is what a StringVar's String method returns in place of a value given on the
+	// command line, so that it isn't printed by flag.PrintDefaults
+	Masked = "<masked>"
+)
+
+// StringVar is a flag.Value backed by an item on a keyring
+type StringVar struct {
+	kr  keyring.Keyring
+	key string
+
+	override *string
+}
+
+// NewString returns a StringVar for the item stored on kr under key
+func NewString(kr keyring.Keyring, key string) *StringVar {
+	return &StringVar{kr: kr, key: key}
+}
+
+// String returns Masked if the flag was given on the command line, and NotSet otherwise.
+// It never returns the secret, and doesn't read the keyring as that may prompt the user.
+func (v *StringVar) String() string {
+	// The flag package calls String on a zero value to find out the default
+	if v == nil || v.kr == nil {
+		return ""
+	}
+
+	if v.override != nil {
+		return Masked
+	}
+	return NotSet
+}
+
+// Set overrides the stored value with s for this invocation. It's only held in memory,
+// and isn't written to the keyring.
+func (v *StringVar) Set(s string) error {
+	v.override = &s
+	return nil
+}
+
+// Value returns the value given on the command line, or else the value stored on the
+// keyring. It returns an empty string if neither is set, and the keyring's error if the
+// stored value can't be read.
+func (v *StringVar) Value() (string, error) {
+	if v.override != nil {
+		return *v.override, nil
+	}
+
+	item, err := v.kr.Get(v.key)
+	if err == keyring.ErrKeyNotFound {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+	return string(item.Data), nil
+}
+
+// Get returns Value as a string, so that StringVar implements flag.Getter. As Get can't
+// return an error, it returns the error itself if the stored value can't be read; use
+// Value to handle it.
+func (v *StringVar) Get() interface{} {
+	s, err := v.Value()
+	if err != nil {
+		return err
+	}
+	return s
+}
@@ -0,0 +1,78 @@
+package flagvar
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/99designs/keyring"
+)
+
+func TestStringVar(t *testing.T) {
+	kr := keyring.NewArrayKeyring([]keyring.Item{{Key: "api-token", Data: []byte("stored-secret")}})
+	v := NewString(kr, "api-token")
+
+	if v.String() != NotSet {
+		t.Fatalf("Expected %q before the flag is given, got %q", NotSet, v.String())
+	}
+	if v.Get() != "stored-secret" {
+		t.Fatalf("Expected the stored value by default, got %q", v.Get())
+	}
+
+	fs := flag.NewFlagSet("llamas", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	fs.Var(v, "api-token", "API token")
+	if err := fs.Parse([]string{"-api-token=llamas-are-great"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if v.Get() != "llamas-are-great" {
+		t.Fatalf("Expected the command line value, got %q", v.Get())
+	}
+	if v.String() != Masked {
+		t.Fatalf("Expected the command line value to be masked, got %q", v.String())
+	}
+
+	item, err := kr.Get("api-token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(item.Data) != "stored-secret" {
+		t.Fatalf("Expected the override not to be stored, got %q", item.Data)
+	}
+
+	var out bytes.Buffer
+	fs.SetOutput(&out)
+	fs.PrintDefaults()
+	if strings.Contains(out.String(), "secret") || strings.Contains(out.String(), "llamas-are-great") {
+		t.Fatalf("Expected the defaults not to include a secret, got %q", out.String())
+	}
+}
+
+type failingKeyring struct {
+	*keyring.ArrayKeyring
+	err error
+}
+
+func (k failingKeyring) Get(_ string) (keyring.Item, error) {
+	return keyring.Item{}, k.err
+}
+
+func TestStringVarValue(t *testing.T) {
+	v := NewString(keyring.NewArrayKeyring(nil), "api-token")
+	if s, err := v.Value(); err != nil || s != "" {
+		t.Fatalf("Expected an empty value when nothing is stored, got %q, %v", s, err)
+	}
+
+	locked := errors.New("keyring is locked")
+	v = NewString(failingKeyring{keyring.NewArrayKeyring(nil), locked}, "api-token")
+	if _, err := v.Value(); err != locked {
+		t.Fatalf("Expected the keyring's error, got: %v", err)
+	}
+	if v.Get() != locked {
+		t.Fatalf("Expected Get to return the keyring's error, got %v", v.Get())
+	}
+}
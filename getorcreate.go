@@ -0,0 +1,51 @@
+package keyring
+
+import "sync"
+
+// conditionalSetter is implemented by backends that can atomically store an item only
+// when its key isn't already present. It reports whether the item was stored.
+type conditionalSetter interface {
+	SetIfNotExists(item Item) (bool, error)
+}
+
+var getOrCreateMu sync.Mutex
+
+// GetOrCreate returns the item matching key. If it doesn't exist, creator is called and the
+// item it returns is stored under key. If creator fails, its error is returned and nothing
+// is stored.
+//
+// Calls are serialised within the process, so creator runs at most once for a missing key.
+// Backends that support it also store the new item atomically, so that if another process
+// created the key in the meantime, its item is returned instead.
+func GetOrCreate(kr Keyring, key string, creator func() (Item, error)) (Item, error) {
+	getOrCreateMu.Lock()
+	defer getOrCreateMu.Unlock()
+
+	item, err := kr.Get(key)
+	if err != ErrKeyNotFound {
+		return item, err
+	}
+
+	item, err = creator()
+	if err != nil {
+		return Item{}, err
+	}
+	item.Key = key
+
+	if cs, ok := kr.(conditionalSetter); ok {
+		stored, err := cs.SetIfNotExists(item)
+		if err != nil {
+			return Item{}, err
+		}
+		if !stored {
+			Debugf("Item %q was created concurrently, using the stored one", key)
+			return kr.Get(key)
+		}
+		return item, nil
+	}
+
+	if err = kr.Set(item); err != nil {
+		return Item{}, err
+	}
+	return item, nil
+}
@@ -0,0 +1,61 @@
+package keyring
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestGetOrCreateCallsCreatorOnce(t *testing.T) {
+	k := &ArrayKeyring{}
+	var calls int32
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			item, err := GetOrCreate(k, "device-id", func() (Item, error) {
+				atomic.AddInt32(&calls, 1)
+				return Item{Data: []byte("llama-1")}, nil
+			})
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if string(item.Data) != "llama-1" {
+				t.Errorf("Unexpected item data: %q", item.Data)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if calls != 1 {
+		t.Fatalf("Expected creator to be called once, was called %d times", calls)
+	}
+
+	foundItem, err := k.Get("device-id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if foundItem.Key != "device-id" {
+		t.Fatalf("Key wasn't persisted: %q", foundItem.Key)
+	}
+}
+
+func TestGetOrCreateCreatorError(t *testing.T) {
+	k := &ArrayKeyring{}
+	errLlamas := errors.New("no llamas available")
+
+	_, err := GetOrCreate(k, "device-id", func() (Item, error) {
+		return Item{}, errLlamas
+	})
+	if err != errLlamas {
+		t.Fatalf("Expected creator error, got: %v", err)
+	}
+
+	if _, err := k.Get("device-id"); err != ErrKeyNotFound {
+		t.Fatalf("Expected nothing to be stored, got: %v", err)
+	}
+}
@@ -0,0 +1,167 @@
+// Package github gives GitHub Actions workflows access to secrets in HashiCorp Vault without
+// long-lived credentials. The workflow's OIDC token is exchanged for a Vault token using
+// Vault's JWT auth method, which is then used to read dynamic secrets.
+//
+// The workflow needs the "id-token: write" permission for GitHub to provide the token.
+package github
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/99designs/keyring"
+	"github.com/99designs/keyring/vaultdynamic"
+)
+
+// Config configures the exchange of the workflow's OIDC token for a Vault token
+type Config struct {
+	// VaultAddress is the Vault server address, defaulting to $VAULT_ADDR
+	VaultAddress string
+	// VaultRole is the role of the JWT auth method to log in with
+	VaultRole string
+	// VaultAuthMount is the path the JWT auth method is mounted at, defaulting to "jwt"
+	VaultAuthMount string
+	// Audience is the audience requested for the OIDC token, defaulting to GitHub's default
+	Audience string
+	// Client is the HTTP client used for requests, defaulting to http.DefaultClient
+	Client *http.Client
+}
+
+// GitHubActionsBackend is a keyring of Vault dynamic secrets, authenticated with the
+// workflow's OIDC token
+type GitHubActionsBackend struct {
+	*vaultdynamic.VaultDynamicBackend
+}
+
+// NewGitHubActionsBackend logs in to Vault with the workflow's OIDC token and returns a
+// backend reading dynamic secrets with the resulting Vault token
+func NewGitHubActionsBackend(cfg Config) (*GitHubActionsBackend, error) {
+	if cfg.VaultAddress == "" {
+		cfg.VaultAddress = os.Getenv("VAULT_ADDR")
+	}
+	if cfg.VaultAuthMount == "" {
+		cfg.VaultAuthMount = "jwt"
+	}
+	if cfg.Client == nil {
+		cfg.Client = http.DefaultClient
+	}
+	if cfg.VaultAddress == "" {
+		return nil, errors.New("No Vault address provided")
+	}
+	if cfg.VaultRole == "" {
+		return nil, errors.New("No Vault role provided")
+	}
+
+	jwt, err := IDToken(cfg.Client, cfg.Audience)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := vaultLogin(cfg, jwt)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := vaultdynamic.New(vaultdynamic.Config{
+		Address: cfg.VaultAddress,
+		Token:   token,
+		Client:  cfg.Client,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &GitHubActionsBackend{b}, nil
+}
+
+// IDToken requests an OIDC token for the running workflow from GitHub, using the
+// ACTIONS_ID_TOKEN_REQUEST_URL and ACTIONS_ID_TOKEN_REQUEST_TOKEN environment variables
+func IDToken(client *http.Client, audience string) (string, error) {
+	requestURL := os.Getenv("ACTIONS_ID_TOKEN_REQUEST_URL")
+	requestToken := os.Getenv("ACTIONS_ID_TOKEN_REQUEST_TOKEN")
+	if requestURL == "" || requestToken == "" {
+		return "", errors.New("No GitHub Actions OIDC token available, does the workflow have the id-token: write permission?")
+	}
+
+	if audience != "" {
+		sep := "?"
+		if strings.Contains(requestURL, "?") {
+			sep = "&"
+		}
+		requestURL += sep + "audience=" + url.QueryEscape(audience)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, requestURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+requestToken)
+
+	var body struct {
+		Value string `json:"value"`
+	}
+	if err = doJSON(client, req, &body); err != nil {
+		return "", fmt.Errorf("Failed to request GitHub Actions OIDC token: %v", err)
+	}
+	if body.Value == "" {
+		return "", errors.New("GitHub returned an empty OIDC token")
+	}
+
+	keyring.Debugf("Received GitHub Actions OIDC token")
+	return body.Value, nil
+}
+
+// vaultLogin exchanges jwt for a Vault token with the JWT auth method
+func vaultLogin(cfg Config, jwt string) (string, error) {
+	buf, err := json.Marshal(map[string]string{
+		"role": cfg.VaultRole,
+		"jwt":  jwt,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	loginURL := strings.TrimRight(cfg.VaultAddress, "/") + "/v1/auth/" + strings.Trim(cfg.VaultAuthMount, "/") + "/login"
+	req, err := http.NewRequest(http.MethodPost, loginURL, bytes.NewReader(buf))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	var body struct {
+		Auth struct {
+			ClientToken string `json:"client_token"`
+		} `json:"auth"`
+	}
+	if err = doJSON(cfg.Client, req, &body); err != nil {
+		return "", fmt.Errorf("Failed to log in to Vault as role %s: %v", cfg.VaultRole, err)
+	}
+	if body.Auth.ClientToken == "" {
+		return "", errors.New("Vault returned an empty token")
+	}
+
+	keyring.Debugf("Logged in to Vault as role %s", cfg.VaultRole)
+	return body.Auth.ClientToken, nil
+}
+
+func doJSON(client *http.Client, req *http.Request, v interface{}) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,92 @@
+package github
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newFakeServer(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer llamas" {
+			http.Error(w, "bad request token", http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"value": "jwt-for-" + r.URL.Query().Get("audience")})
+	})
+	mux.HandleFunc("/v1/auth/jwt/login", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body["role"] != "ci" || body["jwt"] != "jwt-for-vault" {
+			http.Error(w, "permission denied", http.StatusForbidden)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"auth": map[string]string{"client_token": "s.alpacas"},
+		})
+	})
+	mux.HandleFunc("/v1/database/creds/readonly", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "s.alpacas" {
+			http.Error(w, "permission denied", http.StatusForbidden)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"lease_id": "database/creds/readonly/abc123",
+			"data":     map[string]string{"username": "llama"},
+		})
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestNewGitHubActionsBackend(t *testing.T) {
+	srv := newFakeServer(t)
+	t.Setenv("ACTIONS_ID_TOKEN_REQUEST_URL", srv.URL+"/token?api-version=2.0")
+	t.Setenv("ACTIONS_ID_TOKEN_REQUEST_TOKEN", "llamas")
+
+	b, err := NewGitHubActionsBackend(Config{
+		VaultAddress: srv.URL,
+		VaultRole:    "ci",
+		Audience:     "vault",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+
+	item, err := b.Get("database/readonly")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(item.Data) != `{"username":"llama"}` {
+		t.Fatalf("Unexpected data: %s", item.Data)
+	}
+}
+
+func TestIDTokenWithoutPermission(t *testing.T) {
+	t.Setenv("ACTIONS_ID_TOKEN_REQUEST_URL", "")
+	t.Setenv("ACTIONS_ID_TOKEN_REQUEST_TOKEN", "")
+
+	if _, err := IDToken(http.DefaultClient, ""); err == nil {
+		t.Fatal("Expected an error without the request environment variables")
+	}
+}
+
+func TestVaultLoginRejected(t *testing.T) {
+	srv := newFakeServer(t)
+	t.Setenv("ACTIONS_ID_TOKEN_REQUEST_URL", srv.URL+"/token?api-version=2.0")
+	t.Setenv("ACTIONS_ID_TOKEN_REQUEST_TOKEN", "llamas")
+
+	_, err := NewGitHubActionsBackend(Config{
+		VaultAddress: srv.URL,
+		VaultRole:    "admin",
+		Audience:     "vault",
+	})
+	if err == nil {
+		t.Fatal("Expected login with the wrong role to fail")
+	}
+}
@@ -0,0 +1,29 @@
+package keyring
+
+import (
+	"bytes"
+	"encoding/gob"
+)
+
+// gobItem has the fields of Item without its methods, so encoding it doesn't recurse
+type gobItem Item
+
+// GobEncode encodes the item with encoding/gob, which keeps Data as raw bytes rather than
+// base64 as JSON does
+func (i Item) GobEncode() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(gobItem(i)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// GobDecode decodes an item encoded by GobEncode
+func (i *Item) GobDecode(b []byte) error {
+	var decoded gobItem
+	if err := gob.NewDecoder(bytes.NewReader(b)).Decode(&decoded); err != nil {
+		return err
+	}
+	*i = Item(decoded)
+	return nil
+}
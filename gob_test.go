@@ -0,0 +1,51 @@
+package keyring
+
+import (
+	"bytes"
+	"encoding/gob"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestItemGobRoundTrip(t *testing.T) {
+	item := Item{
+		Key:                         "llamas",
+		Data:                        []byte{0x00, 0xff, 'l', 'l', 'a', 'm', 'a', 's'},
+		Label:                       "Llamas",
+		Description:                 "A secret about llamas",
+		Namespace:                   "farm",
+		NotBefore:                   time.Date(2030, 1, 2, 3, 4, 5, 6, time.UTC),
+		Checksum:                    ETag([]byte("llamas")),
+		RotationSchedule:            "0 0 1 * *",
+		Tags:                        map[string]string{"service": "test", "env": "prod"},
+		KeychainNotTrustApplication: true,
+		KeychainNotSynchronizable:   true,
+		AgeRecipients:               []string{"age1llamas"},
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(item); err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded Item
+	if err := gob.NewDecoder(&buf).Decode(&decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	if !decoded.NotBefore.Equal(item.NotBefore) {
+		t.Fatalf("Expected NotBefore %s, got %s", item.NotBefore, decoded.NotBefore)
+	}
+	decoded.NotBefore = item.NotBefore
+	if !reflect.DeepEqual(decoded, item) {
+		t.Fatalf("Expected %#v, got %#v", item, decoded)
+	}
+}
+
+func TestItemGobDecodeInvalid(t *testing.T) {
+	var item Item
+	if err := item.GobDecode([]byte("not gob")); err == nil {
+		t.Fatal("Expected an error decoding invalid data")
+	}
+}
@@ -0,0 +1,148 @@
+package gpgagent
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// maxLineLength is the longest line, including the newline, allowed by the Assuan protocol
+const maxLineLength = 1000
+
+// gpgErrNoData is the libgpg-error code returned when a passphrase isn't cached
+const gpgErrNoData = 58
+
+// AgentError is an ERR response from gpg-agent
+type AgentError struct {
+	// Code is the libgpg-error code, with the error source in the upper 8 bits
+	Code        uint32
+	Description string
+}
+
+func (e *AgentError) Error() string {
+	return fmt.Sprintf("gpg-agent returned error %d: %s", e.Code, e.Description)
+}
+
+// assuanConn is a client connection to an Assuan server
+type assuanConn struct {
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// dialAssuan connects to the Assuan server listening on the Unix socket and reads its greeting
+func dialAssuan(socket string) (*assuanConn, error) {
+	conn, err := net.Dial("unix", socket)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &assuanConn{conn: conn, r: bufio.NewReaderSize(conn, maxLineLength)}
+	if _, err = c.response(); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("Failed to connect to gpg-agent at %s: %v", socket, err)
+	}
+	return c, nil
+}
+
+// transact sends the command line and returns the data sent by the server before its OK
+func (c *assuanConn) transact(line string) ([]byte, error) {
+	if len(line)+1 > maxLineLength {
+		return nil, errors.New("Assuan command is too long")
+	}
+	if _, err := c.conn.Write([]byte(line + "\n")); err != nil {
+		return nil, err
+	}
+	return c.response()
+}
+
+// response reads lines until the server completes its response with OK or ERR. Status
+// lines and comments are skipped, and inquiries are cancelled as there is nothing to send.
+func (c *assuanConn) response() ([]byte, error) {
+	var data []byte
+	for {
+		line, err := c.r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimSuffix(line, "\n")
+
+		verb, rest := line, ""
+		if idx := strings.IndexByte(line, ' '); idx >= 0 {
+			verb, rest = line[:idx], line[idx+1:]
+		}
+
+		switch verb {
+		case "OK":
+			return data, nil
+		case "ERR":
+			return nil, parseAgentError(rest)
+		case "D":
+			data = append(data, unescapeData(rest)...)
+		case "INQUIRE":
+			if _, err = c.conn.Write([]byte("CAN\n")); err != nil {
+				return nil, err
+			}
+		case "S", "#":
+		default:
+			return nil, fmt.Errorf("Unexpected Assuan response %q", line)
+		}
+	}
+}
+
+func (c *assuanConn) Close() error {
+	return c.conn.Close()
+}
+
+func parseAgentError(s string) error {
+	codeStr, desc := s, ""
+	if idx := strings.IndexByte(s, ' '); idx >= 0 {
+		codeStr, desc = s[:idx], s[idx+1:]
+	}
+	code, err := strconv.ParseUint(codeStr, 10, 32)
+	if err != nil {
+		return fmt.Errorf("Malformed Assuan error %q", s)
+	}
+	return &AgentError{Code: uint32(code), Description: desc}
+}
+
+// isNoData reports whether err is gpg-agent's GPG_ERR_NO_DATA, from any source
+func isNoData(err error) bool {
+	var agentErr *AgentError
+	return errors.As(err, &agentErr) && agentErr.Code&0xffff == gpgErrNoData
+}
+
+// unescapeData decodes the %XX escapes used for CR, LF and % in data lines
+func unescapeData(s string) []byte {
+	var b bytes.Buffer
+	for i := 0; i < len(s); i++ {
+		if s[i] == '%' && i+2 < len(s) {
+			if v, err := strconv.ParseUint(s[i+1:i+3], 16, 8); err == nil {
+				b.WriteByte(byte(v))
+				i += 2
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.Bytes()
+}
+
+// escapeArg percent-encodes everything in s except unreserved characters, so that it can
+// be passed as a single command argument
+func escapeArg(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '/' || c == ':' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
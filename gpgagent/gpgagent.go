@@ -0,0 +1,154 @@
+// Package gpgagent stores keyring items as passphrases cached by a running gpg-agent,
+// talking to it directly over its Assuan socket rather than spawning gpg.
+//
+// Items are written with PRESET_PASSPHRASE, so gpg-agent must be started with
+// allow-preset-passphrase in gpg-agent.conf, and are read with GET_PASSPHRASE. They live
+// only as long as gpg-agent's cache does. Importing the package registers it with
+// keyring.Open as the "gpg-agent" backend.
+package gpgagent
+
+import (
+	"encoding/hex"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/99designs/keyring"
+)
+
+// Backend is the name that the backend is registered with keyring.Open under
+const Backend keyring.BackendType = "gpg-agent"
+
+// ErrNoListing is returned by Keys, as gpg-agent can't enumerate its cached passphrases
+var ErrNoListing = errors.New("gpg-agent can't list cached passphrases")
+
+func init() {
+	keyring.RegisterBackend(Backend, func(cfg keyring.Config) (keyring.Keyring, error) {
+		return New(Config{ServiceName: cfg.ServiceName})
+	})
+}
+
+// Config configures access to gpg-agent
+type Config struct {
+	// Socket is the agent's Assuan socket, defaulting to the output of
+	// gpgconf --list-dirs agent-socket
+	Socket string
+	// ServiceName is prepended to keys to form gpg-agent cache IDs
+	ServiceName string
+	// CacheTTL is how long gpg-agent keeps items. Zero means its max-cache-ttl setting.
+	CacheTTL time.Duration
+}
+
+// GPGAgentBackend is a keyring of passphrases cached by gpg-agent
+type GPGAgentBackend struct {
+	socket  string
+	service string
+	ttl     time.Duration
+}
+
+// New returns a GPGAgentBackend after checking that gpg-agent is reachable
+func New(cfg Config) (*GPGAgentBackend, error) {
+	b := &GPGAgentBackend{
+		socket:  cfg.Socket,
+		service: cfg.ServiceName,
+		ttl:     cfg.CacheTTL,
+	}
+	if b.socket == "" {
+		b.socket = agentSocket()
+	}
+
+	c, err := dialAssuan(b.socket)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	version, err := c.transact("GETINFO version")
+	if err != nil {
+		return nil, err
+	}
+	keyring.Debugf("Connected to gpg-agent %s at %s", version, b.socket)
+
+	return b, nil
+}
+
+// agentSocket asks gpgconf for the agent socket, falling back to the default location in
+// $GNUPGHOME or ~/.gnupg if gpgconf isn't installed
+func agentSocket() string {
+	out, err := exec.Command("gpgconf", "--list-dirs", "agent-socket").Output()
+	if err == nil {
+		return strings.TrimSpace(string(out))
+	}
+	keyring.Debugf("Failed to run gpgconf: %v", err)
+
+	home := os.Getenv("GNUPGHOME")
+	if home == "" {
+		dir, _ := os.UserHomeDir()
+		home = filepath.Join(dir, ".gnupg")
+	}
+	return filepath.Join(home, "S.gpg-agent")
+}
+
+func (b *GPGAgentBackend) cacheID(key string) string {
+	return escapeArg("keyring:" + b.service + "/" + key)
+}
+
+func (b *GPGAgentBackend) transact(line string) ([]byte, error) {
+	c, err := dialAssuan(b.socket)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	return c.transact(line)
+}
+
+// Get returns the cached passphrase for key, without ever prompting for it
+func (b *GPGAgentBackend) Get(key string) (keyring.Item, error) {
+	data, err := b.transact("GET_PASSPHRASE --data --no-ask " + b.cacheID(key) + " X X X")
+	if isNoData(err) {
+		return keyring.Item{}, keyring.ErrKeyNotFound
+	} else if err != nil {
+		return keyring.Item{}, err
+	}
+
+	return keyring.Item{Key: key, Data: data}, nil
+}
+
+// GetMetadata returns only the key, as gpg-agent keeps nothing else about its cache entries
+func (b *GPGAgentBackend) GetMetadata(key string) (keyring.Metadata, error) {
+	if _, err := b.Get(key); err != nil {
+		return keyring.Metadata{}, err
+	}
+	return keyring.Metadata{Item: &keyring.Item{Key: key}}, nil
+}
+
+// Set caches item's data in gpg-agent. Only the data is kept.
+func (b *GPGAgentBackend) Set(item keyring.Item) error {
+	ttl := -1
+	if b.ttl > 0 {
+		ttl = int(b.ttl / time.Second)
+	}
+
+	_, err := b.transact("PRESET_PASSPHRASE " + b.cacheID(item.Key) + " " + strconv.Itoa(ttl) + " " +
+		strings.ToUpper(hex.EncodeToString(item.Data)))
+	return err
+}
+
+// Remove clears the cached passphrase for key
+func (b *GPGAgentBackend) Remove(key string) error {
+	if _, err := b.Get(key); err != nil {
+		return err
+	}
+	_, err := b.transact("CLEAR_PASSPHRASE " + b.cacheID(key))
+	return err
+}
+
+// Keys returns ErrNoListing
+func (b *GPGAgentBackend) Keys() ([]string, error) {
+	return nil, ErrNoListing
+}
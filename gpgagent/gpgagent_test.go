@@ -0,0 +1,112 @@
+package gpgagent
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/99designs/keyring"
+)
+
+// fakeAgent implements the subset of gpg-agent's Assuan commands used by the backend
+type fakeAgent struct {
+	mu    sync.Mutex
+	cache map[string]string
+}
+
+func startFakeAgent(t *testing.T) string {
+	socket := filepath.Join(t.TempDir(), "S.gpg-agent")
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+
+	agent := &fakeAgent{cache: map[string]string{}}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go agent.serve(conn)
+		}
+	}()
+	return socket
+}
+
+func (a *fakeAgent) serve(conn net.Conn) {
+	defer conn.Close()
+	fmt.Fprint(conn, "OK Pleased to meet you\n")
+
+	s := bufio.NewScanner(conn)
+	for s.Scan() {
+		args := strings.Split(s.Text(), " ")
+		a.mu.Lock()
+		switch args[0] {
+		case "GETINFO":
+			fmt.Fprint(conn, "D 2.2.40\nOK\n")
+		case "PRESET_PASSPHRASE":
+			data, _ := hex.DecodeString(args[3])
+			a.cache[args[1]] = string(data)
+			fmt.Fprint(conn, "OK\n")
+		case "GET_PASSPHRASE":
+			if data, ok := a.cache[args[3]]; ok {
+				data = strings.NewReplacer("%", "%25", "\n", "%0A", "\r", "%0D").Replace(data)
+				fmt.Fprintf(conn, "S PROGRESS\nD %s\nOK\n", data)
+			} else {
+				fmt.Fprint(conn, "ERR 67108922 No data <GPG Agent>\n")
+			}
+		case "CLEAR_PASSPHRASE":
+			delete(a.cache, args[1])
+			fmt.Fprint(conn, "OK\n")
+		default:
+			fmt.Fprint(conn, "ERR 67109139 Unknown IPC command <GPG Agent>\n")
+		}
+		a.mu.Unlock()
+	}
+}
+
+func TestGPGAgentBackend(t *testing.T) {
+	b, err := New(Config{Socket: startFakeAgent(t), ServiceName: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = b.Get("llamas"); err != keyring.ErrKeyNotFound {
+		t.Fatalf("Expected ErrKeyNotFound, got: %v", err)
+	}
+
+	data := "llamas\nare 100% great"
+	if err = b.Set(keyring.Item{Key: "llamas", Data: []byte(data)}); err != nil {
+		t.Fatal(err)
+	}
+	item, err := b.Get("llamas")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(item.Data) != data {
+		t.Fatalf("Expected %q, got %q", data, item.Data)
+	}
+
+	if err = b.Remove("llamas"); err != nil {
+		t.Fatal(err)
+	}
+	if err = b.Remove("llamas"); err != keyring.ErrKeyNotFound {
+		t.Fatalf("Expected ErrKeyNotFound, got: %v", err)
+	}
+	if _, err = b.Keys(); err != ErrNoListing {
+		t.Fatalf("Expected ErrNoListing, got: %v", err)
+	}
+}
+
+func TestEscapeArg(t *testing.T) {
+	if id := escapeArg("keyring:my app/a+b%"); id != "keyring:my%20app/a%2Bb%25" {
+		t.Fatalf("Unexpected cache ID: %s", id)
+	}
+}
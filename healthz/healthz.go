@@ -0,0 +1,87 @@
+// Package healthz serves the health of a keyring over HTTP, for use as a Kubernetes
+// liveness or readiness probe.
+package healthz
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/99designs/keyring"
+)
+
+// HealthzOptions configures Handler
+type HealthzOptions struct {
+	// CacheFor is how long a check result is reused for, so that frequent probes don't
+	// hammer the backend. Zero checks on every request.
+	CacheFor time.Duration
+}
+
+// response is the JSON body served by Handler
+type response struct {
+	Status    string `json:"status"`
+	LatencyMS *int64 `json:"latency_ms,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// CheckBackend checks that the backend of kr is reachable by listing its keys, which
+// doesn't change the keyring or need item data to be decrypted
+func CheckBackend(kr keyring.Keyring) error {
+	_, err := kr.Keys()
+	return err
+}
+
+type handler struct {
+	kr   keyring.Keyring
+	opts HealthzOptions
+
+	mu      sync.Mutex
+	checked time.Time
+	status  int
+	body    []byte
+}
+
+// Handler returns an http.Handler that runs CheckBackend against kr, responding with
+// 200 and {"status": "ok", "latency_ms": 12} if it succeeds, or 503 and
+// {"status": "error", "error": "<message>"} if it fails
+func Handler(kr keyring.Keyring, opts HealthzOptions) http.Handler {
+	return &handler{kr: kr, opts: opts}
+}
+
+// check returns the cached result if it's recent enough, or runs the check. Requests that
+// arrive during a check wait for its result rather than starting their own.
+func (h *handler) check() (int, []byte) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.body != nil && time.Since(h.checked) < h.opts.CacheFor {
+		return h.status, h.body
+	}
+
+	start := time.Now()
+	err := CheckBackend(h.kr)
+	latency := time.Since(start)
+
+	ms := latency.Milliseconds()
+	resp := response{Status: "ok", LatencyMS: &ms}
+	h.status = http.StatusOK
+	if err != nil {
+		keyring.Debugf("Health check failed after %s: %v", latency, err)
+		resp = response{Status: "error", Error: err.Error()}
+		h.status = http.StatusServiceUnavailable
+	}
+
+	h.body, _ = json.Marshal(resp)
+	h.checked = time.Now()
+	return h.status, h.body
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	status, body := h.check()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_, _ = w.Write(body)
+}
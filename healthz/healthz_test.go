@@ -0,0 +1,67 @@
+package healthz
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/99designs/keyring"
+)
+
+// flakyKeyring fails Keys while down is set, and counts calls to it
+type flakyKeyring struct {
+	keyring.ArrayKeyring
+	down  bool
+	calls int
+}
+
+func (k *flakyKeyring) Keys() ([]string, error) {
+	k.calls++
+	if k.down {
+		return nil, errors.New("backend unreachable")
+	}
+	return k.ArrayKeyring.Keys()
+}
+
+func probe(t *testing.T, h http.Handler) (int, response) {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	var resp response
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	return rec.Code, resp
+}
+
+func TestHandler(t *testing.T) {
+	kr := &flakyKeyring{}
+	h := Handler(kr, HealthzOptions{})
+
+	if code, resp := probe(t, h); code != http.StatusOK || resp.Status != "ok" || resp.LatencyMS == nil {
+		t.Fatalf("Expected 200 ok, got %d %+v", code, resp)
+	}
+
+	kr.down = true
+	code, resp := probe(t, h)
+	if code != http.StatusServiceUnavailable || resp.Status != "error" || resp.Error != "backend unreachable" {
+		t.Fatalf("Expected 503 error, got %d %+v", code, resp)
+	}
+}
+
+func TestHandlerCachesResult(t *testing.T) {
+	kr := &flakyKeyring{}
+	h := Handler(kr, HealthzOptions{CacheFor: time.Hour})
+
+	probe(t, h)
+	kr.down = true
+	if code, _ := probe(t, h); code != http.StatusOK {
+		t.Fatalf("Expected the cached result, got %d", code)
+	}
+	if kr.calls != 1 {
+		t.Fatalf("Expected the backend to be checked once, got %d", kr.calls)
+	}
+}
@@ -0,0 +1,31 @@
+package keyring
+
+// metadataIndexer is implemented by backends that can read the metadata of every item at
+// once, more efficiently than calling GetMetadata for each key
+type metadataIndexer interface {
+	indexMetadata() (map[string]Metadata, error)
+}
+
+// IndexMetadata returns the metadata of every item on kr, keyed by item key. Backends that
+// support it read it all at once, for others it calls GetMetadata for each key, failing if
+// any of those calls fail.
+func IndexMetadata(kr Keyring) (map[string]Metadata, error) {
+	if mi, ok := kr.(metadataIndexer); ok {
+		return mi.indexMetadata()
+	}
+
+	keys, err := kr.Keys()
+	if err != nil {
+		return nil, err
+	}
+
+	index := make(map[string]Metadata, len(keys))
+	for _, key := range keys {
+		md, err := kr.GetMetadata(key)
+		if err != nil {
+			return nil, err
+		}
+		index[key] = md
+	}
+	return index, nil
+}
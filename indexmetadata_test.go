@@ -0,0 +1,38 @@
+package keyring
+
+import (
+	"testing"
+)
+
+func TestIndexMetadataFileKeyring(t *testing.T) {
+	k := &fileKeyring{
+		dir:          t.TempDir(),
+		passwordFunc: fixedStringPrompt("no more secrets"),
+	}
+	for _, key := range []string{"llamas", "alpacas"} {
+		if err := k.Set(Item{Key: key, Data: []byte(key)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	index, err := IndexMetadata(k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(index) != 2 {
+		t.Fatalf("Expected 2 entries, got %v", index)
+	}
+	for _, key := range []string{"llamas", "alpacas"} {
+		if index[key].ModificationTime.IsZero() {
+			t.Fatalf("Expected a modification time for %q", key)
+		}
+	}
+}
+
+func TestIndexMetadataFallsBackToGetMetadata(t *testing.T) {
+	k := NewArrayKeyring([]Item{{Key: "llamas"}})
+
+	if _, err := IndexMetadata(k); err != ErrMetadataNeedsCredentials {
+		t.Fatalf("Expected the GetMetadata error, got: %v", err)
+	}
+}
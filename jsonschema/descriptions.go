@@ -0,0 +1,63 @@
+// Code generated by gen.go; DO NOT EDIT.
+
+package jsonschema
+
+// descriptions are the doc comments of the fields of keyring.Config
+var descriptions = map[string]string{
+	"AgeDir":                         "AgeDir is the directory that age encrypted item files are stored in, ~ is resolved to home dir",
+	"AgeIdentityFile":                "AgeIdentityFile is an age identity file or unencrypted SSH private key used to decrypt items",
+	"AgeRecipients":                  "AgeRecipients are the X25519 or SSH public keys that age encrypted items are encrypted to",
+	"AgeSSHPassphraseFunc":           "AgeSSHPassphraseFunc is an optional function used to prompt the user for the SSH private key passphrase",
+	"AgeSSHPrivateKeyFile":           "AgeSSHPrivateKeyFile is an SSH private key file used to decrypt items, which may be protected by a passphrase",
+	"AgeSSHPublicKeyFile":            "AgeSSHPublicKeyFile is an SSH public key file, such as ~/.ssh/id_ed25519.pub, that items are also encrypted to",
+	"AllowEmptyPassphrase":           "AllowEmptyPassphrase is whether a keychain can be created with an empty passphrase from KeychainPasswordFunc",
+	"AllowedBackends":                "AllowedBackends is a whitelist of backend providers that can be used. Nil means all available.",
+	"AzContainerName":                "AzContainerName is the blob container used by the azblob backend, defaulting to ServiceName",
+	"AzStorageAccountName":           "AzStorageAccountName is the Azure storage account used by the azblob backend",
+	"ChainWritePolicy":               "ChainWritePolicy controls which backends OpenChain writes to. Empty means WriteAll.",
+	"ClearOnExit":                    "ClearOnExit removes items created through the keyring when the process is interrupted or terminated",
+	"ConcurrencyTimeout":             "ConcurrencyTimeout is how long an operation waits for one of the slots above before failing with ErrTimeout. Zero means it waits indefinitely.",
+	"FileCompressionLevel":           "FileCompressionLevel is the zstd compression level used by the file backend. Zero means the default level.",
+	"FileCompressionThreshold":       "FileCompressionThreshold is the item data size in bytes above which items are compressed with zstd before being encrypted. Zero means 1024 bytes, and a negative value disables compression.",
+	"FileDir":                        "FileDir is the directory that keyring files are stored in, ~ is resolved to home dir",
+	"FilePasswordFunc":               "FilePasswordFunc is a required function used to prompt the user for a password",
+	"GitCredentialsFile":             "GitCredentialsFile is the file used by git's \"store\" credential helper, defaulting to ~/.git-credentials",
+	"HMACKey":                        "HMACKey, when set, makes the file backend sign each item with HMAC-SHA256 and reject items that don't match",
+	"ItemChecksums":                  "ItemChecksums stores a SHA-256 checksum with each item on Set and verifies it on Get",
+	"KWalletAppID":                   "KWalletAppID is the application id for KWallet",
+	"KWalletFolder":                  "KWalletFolder is the folder for KWallet",
+	"KeePassFile":                    "KeePassFile is the KeePass KDBX database that items are read from, ~ is resolved to home dir",
+	"KeePassPasswordFunc":            "KeePassPasswordFunc is an optional function used to prompt the user for the database passphrase",
+	"KeyPrefix":                      "KeyPrefix is prepended to every key before it reaches the backend, and only keys with it are listed",
+	"KeychainAccessGroups":           "KeychainAccessGroups are the keychain access groups that items are read from, in order. Items are written to the first. The application needs the keychain-access-groups entitlement.",
+	"KeychainAccessibleWhenUnlocked": "KeychainAccessibleWhenUnlocked is whether the item is accessible when the device is locked",
+	"KeychainCompressItems":          "KeychainCompressItems is whether item data is compressed with Snappy, so that larger items fit in the keychain",
+	"KeychainName":                   "MacOSKeychainNameKeychainName is the name of the macOS keychain that is used, or an absolute path to it",
+	"KeychainPasswordFunc":           "KeychainPasswordFunc is an optional function used to prompt the user for a password",
+	"KeychainSearchServices":         "KeychainSearchServices are the services that Get and Keys read items from, instead of ServiceName. Items of other applications can only be read if the caller is in their ACL.",
+	"KeychainSynchronizable":         "KeychainSynchronizable is whether the item can be synchronized to iCloud",
+	"KeychainTrustApplication":       "KeychainTrustApplication is whether the calling application should be trusted by default by items",
+	"LibSecretCollectionName":        "LibSecretCollectionName is the name collection in secret-service",
+	"MaxItemDataSize":                "MaxItemDataSize is the largest item data in bytes that Set accepts. Zero means no limit.",
+	"MaxPassphraseAttempts":          "MaxPassphraseAttempts is how many times KeychainPasswordFunc is called while it returns an empty passphrase before giving up with ErrPassphraseRequired. Zero means DefaultMaxPassphraseAttempts.",
+	"MaxReadConcurrent":              "MaxReadConcurrent is the most Get, GetMetadata and Keys operations that run at once. Zero means no limit.",
+	"MaxWriteConcurrent":             "MaxWriteConcurrent is the most Set and Remove operations that run at once. Zero means no limit.",
+	"Namespaces":                     "Namespaces stores keys as \"<namespace>/<key>\", using Item.Namespace or DefaultNamespace, and only lists keys in DefaultNamespace. See WithNamespace and KeysInNamespace.",
+	"PKCS11Module":                   "PKCS11Module is the path to the PKCS#11 module (.so) for the HSM",
+	"PKCS11PIN":                      "PKCS11PIN is the user PIN used to log in to the token",
+	"PKCS11SlotID":                   "PKCS11SlotID is the slot of the token to use, ignored if PKCS11TokenLabel is set",
+	"PKCS11TokenLabel":               "PKCS11TokenLabel is the label of the token to use",
+	"PassCmd":                        "PassCmd is the name of the pass executable",
+	"PassDir":                        "PassDir is the pass password-store directory",
+	"PassPrefix":                     "PassPrefix is a string prefix to prepend to the item path stored in pass",
+	"SSHAuthSock":                    "SSHAuthSock is the socket of the ssh-agent to use, defaulting to $SSH_AUTH_SOCK",
+	"SchemaValidators":               "SchemaValidators maps key glob patterns to JSON Schemas that the data of matching items must conform to",
+	"SealedBoxDir":                   "SealedBoxDir is the directory that sealed box item files are stored in, ~ is resolved to home dir",
+	"SealedBoxPrivateKey":            "SealedBoxPrivateKey is the path to a file containing the base64 encoded Curve25519 private key used to open items",
+	"SealedBoxPublicKey":             "SealedBoxPublicKey is the base64 encoded Curve25519 public key that items are sealed to",
+	"ServiceName":                    "ServiceName is a generic service name that is used by backends that support the concept",
+	"WASMDatabaseName":               "WASMDatabaseName is the IndexedDB database that the wasm backend stores items in",
+	"WASMPasswordFunc":               "WASMPasswordFunc is a required function used to prompt the user for a password in the wasm backend",
+	"WinCredPrefix":                  "WinCredPrefix is a string prefix to prepend to the key name",
+	"WinCredentialType":              "WinCredentialType is the type of credential the wincred backend stores. Empty means WinCredTypeGeneric.",
+}
@@ -0,0 +1,63 @@
+//go:build ignore
+// +build ignore
+
+// gen writes descriptions.go with the doc comments of the fields of keyring.Config
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/doc"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"log"
+	"sort"
+	"strings"
+)
+
+func main() {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, "..", nil, parser.ParseComments)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	descriptions := map[string]string{}
+	for _, t := range doc.New(pkgs["keyring"], "github.com/99designs/keyring", 0).Types {
+		if t.Name != "Config" {
+			continue
+		}
+		st := t.Decl.Specs[0].(*ast.TypeSpec).Type.(*ast.StructType)
+		for _, field := range st.Fields.List {
+			for _, name := range field.Names {
+				descriptions[name.Name] = strings.Join(strings.Fields(field.Doc.Text()), " ")
+			}
+		}
+	}
+
+	names := make([]string, 0, len(descriptions))
+	for name := range descriptions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by gen.go; DO NOT EDIT.\n\npackage jsonschema\n\n")
+	buf.WriteString("// descriptions are the doc comments of the fields of keyring.Config\n")
+	buf.WriteString("var descriptions = map[string]string{\n")
+	for _, name := range names {
+		fmt.Fprintf(&buf, "\t%q: %q,\n", name, descriptions[name])
+	}
+	buf.WriteString("}\n")
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err = ioutil.WriteFile("descriptions.go", src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,115 @@
+// Package jsonschema generates a JSON Schema for the keyring configuration files read by
+// keyring.LoadConfig, so that editors can validate and autocomplete them.
+package jsonschema
+
+//go:generate go run gen.go
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/99designs/keyring"
+)
+
+// SchemaVersion is the JSON Schema dialect of the generated schema
+const SchemaVersion = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is a JSON Schema, or the subset of it that Generate uses
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 interface{}        `json:"type,omitempty"`
+	Minimum              *int               `json:"minimum,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties interface{}        `json:"additionalProperties,omitempty"`
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// Generate returns the JSON Schema for keyring.Config, with a property for each field that
+// can be set from YAML, named by its yaml tag and described by its doc comment. Unknown
+// properties are rejected so that typos are caught.
+func Generate() ([]byte, error) {
+	s, err := ConfigSchema()
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(s, "", "  ")
+}
+
+// ConfigSchema returns the schema that Generate encodes
+func ConfigSchema() (*Schema, error) {
+	t := reflect.TypeOf(keyring.Config{})
+
+	s := &Schema{
+		Schema:               SchemaVersion,
+		Title:                "keyring configuration",
+		Description:          "Configuration for github.com/99designs/keyring, as read by keyring.LoadConfig",
+		Type:                 "object",
+		Properties:           map[string]*Schema{},
+		AdditionalProperties: false,
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if name == "-" || field.Type.Kind() == reflect.Func {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+
+		prop, err := typeSchema(field.Type)
+		if err != nil {
+			return nil, fmt.Errorf("Config.%s: %w", field.Name, err)
+		}
+		prop.Description = descriptions[field.Name]
+		s.Properties[name] = prop
+	}
+	return s, nil
+}
+
+// typeSchema returns the schema for values of t as they're written in YAML
+func typeSchema(t reflect.Type) (*Schema, error) {
+	zero := 0
+
+	switch {
+	case t == durationType:
+		// yaml.v3 reads durations like "1m30s", as well as nanoseconds
+		return &Schema{Type: []string{"string", "integer"}}, nil
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		return &Schema{Type: "string"}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}, nil
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &Schema{Type: "integer"}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer", Minimum: &zero}, nil
+	case reflect.Slice:
+		items, err := typeSchema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "array", Items: items}, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("Unsupported map key type %s", t.Key())
+		}
+		values, err := typeSchema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "object", AdditionalProperties: values}, nil
+	}
+	return nil, fmt.Errorf("Unsupported type %s", t)
+}
@@ -0,0 +1,86 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"go/ast"
+	"go/doc"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	b, err := Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var s struct {
+		Schema               string `json:"$schema"`
+		AdditionalProperties bool   `json:"additionalProperties"`
+		Properties           map[string]struct {
+			Type        interface{} `json:"type"`
+			Description string      `json:"description"`
+			Items       *struct {
+				Type string `json:"type"`
+			} `json:"items"`
+		} `json:"properties"`
+	}
+	if err = json.Unmarshal(b, &s); err != nil {
+		t.Fatal(err)
+	}
+
+	if s.Schema != SchemaVersion || s.AdditionalProperties {
+		t.Fatalf("Unexpected schema header: %s", b)
+	}
+
+	backends, ok := s.Properties["allowed_backends"]
+	if !ok || backends.Type != "array" || backends.Items == nil || backends.Items.Type != "string" {
+		t.Fatalf("Unexpected allowed_backends: %+v", backends)
+	}
+	if !strings.HasPrefix(backends.Description, "AllowedBackends is a whitelist") {
+		t.Fatalf("Expected the doc comment as the description, got %q", backends.Description)
+	}
+
+	if p := s.Properties["max_item_data_size"]; p.Type != "integer" {
+		t.Fatalf("Expected an integer, got %v", p.Type)
+	}
+	if p := s.Properties["clear_on_exit"]; p.Type != "boolean" {
+		t.Fatalf("Expected a boolean, got %v", p.Type)
+	}
+	if _, ok = s.Properties["file_password_func"]; ok {
+		t.Fatal("Expected prompt functions to be left out")
+	}
+	for name, p := range s.Properties {
+		if p.Description == "" {
+			t.Fatalf("Expected %s to have a description", name)
+		}
+	}
+}
+
+// TestDescriptionsUpToDate checks that go generate has been run since Config last changed
+func TestDescriptionsUpToDate(t *testing.T) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, "..", nil, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	docs := map[string]string{}
+	for _, typ := range doc.New(pkgs["keyring"], "github.com/99designs/keyring", 0).Types {
+		if typ.Name != "Config" {
+			continue
+		}
+		for _, field := range typ.Decl.Specs[0].(*ast.TypeSpec).Type.(*ast.StructType).Fields.List {
+			for _, name := range field.Names {
+				docs[name.Name] = strings.Join(strings.Fields(field.Doc.Text()), " ")
+			}
+		}
+	}
+
+	if !reflect.DeepEqual(docs, descriptions) {
+		t.Fatal("descriptions.go is out of date with keyring.Config, run go generate")
+	}
+}
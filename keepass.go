@@ -0,0 +1,178 @@
+package keyring
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tobischo/gokeepasslib/v3"
+)
+
+func init() {
+	supportedBackends[KeePassBackend] = opener(func(cfg Config) (Keyring, error) {
+		if cfg.KeePassFile == "" {
+			return nil, errors.New("No KeePass database file provided")
+		}
+
+		k := &keepassKeyring{
+			file:         cfg.KeePassFile,
+			service:      cfg.ServiceName,
+			passwordFunc: cfg.KeePassPasswordFunc,
+		}
+		if k.passwordFunc == nil {
+			k.passwordFunc = terminalPrompt
+		}
+
+		return k, nil
+	})
+}
+
+// keepassKeyring reads items from the entries of a KeePass KDBX database. Entries are
+// matched by a title of service/key, and the item data is the entry's password. Writing
+// KDBX files isn't supported, so the keyring is read-only.
+type keepassKeyring struct {
+	file         string
+	service      string
+	passwordFunc PromptFunc
+
+	mu       sync.Mutex
+	password string
+	modTime  time.Time
+	entries  map[string]gokeepasslib.Entry
+}
+
+// load decodes the database, unless it hasn't changed since it was last loaded. Decoding is
+// slow as the key derivation function is deliberately expensive.
+func (k *keepassKeyring) load() (map[string]gokeepasslib.Entry, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	path, err := expandTilde(k.file)
+	if err != nil {
+		return nil, err
+	}
+
+	stat, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if k.entries != nil && stat.ModTime().Equal(k.modTime) {
+		return k.entries, nil
+	}
+
+	if k.password == "" {
+		if k.password, err = k.passwordFunc(fmt.Sprintf("Enter passphrase to unlock %s", path)); err != nil {
+			return nil, err
+		}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	db := gokeepasslib.NewDatabase()
+	db.Credentials = gokeepasslib.NewPasswordCredentials(k.password)
+	if err = gokeepasslib.NewDecoder(f).Decode(db); err != nil {
+		// Ask again next time, in case the passphrase was wrong
+		k.password = ""
+		return nil, fmt.Errorf("Failed to open KeePass database %s: %v", path, err)
+	}
+	if err = db.UnlockProtectedEntries(); err != nil {
+		return nil, err
+	}
+
+	var recycleBin *gokeepasslib.UUID
+	if db.Content.Meta != nil && db.Content.Meta.RecycleBinEnabled.Bool {
+		recycleBin = &db.Content.Meta.RecycleBinUUID
+	}
+
+	entries := map[string]gokeepasslib.Entry{}
+	if db.Content.Root != nil {
+		collectKeePassEntries(db.Content.Root.Groups, recycleBin, entries)
+	}
+	Debugf("Loaded %d entries from KeePass database %s", len(entries), path)
+
+	k.entries = entries
+	k.modTime = stat.ModTime()
+	return entries, nil
+}
+
+// collectKeePassEntries adds the entries in groups and their subgroups to entries by title,
+// skipping the recycle bin. The first entry found wins when titles are duplicated.
+func collectKeePassEntries(groups []gokeepasslib.Group, recycleBin *gokeepasslib.UUID, entries map[string]gokeepasslib.Entry) {
+	for _, g := range groups {
+		if recycleBin != nil && g.UUID.Compare(*recycleBin) {
+			continue
+		}
+		for _, e := range g.Entries {
+			if _, ok := entries[e.GetTitle()]; !ok {
+				entries[e.GetTitle()] = e
+			}
+		}
+		collectKeePassEntries(g.Groups, recycleBin, entries)
+	}
+}
+
+func (k *keepassKeyring) title(key string) string {
+	if k.service == "" {
+		return key
+	}
+	return k.service + "/" + key
+}
+
+func (k *keepassKeyring) Get(key string) (Item, error) {
+	entries, err := k.load()
+	if err != nil {
+		return Item{}, WrapError(err, KeePassBackend, k.service, key)
+	}
+
+	e, ok := entries[k.title(key)]
+	if !ok {
+		return Item{}, ErrKeyNotFound
+	}
+
+	return Item{
+		Key:         key,
+		Data:        []byte(e.GetPassword()),
+		Label:       e.GetTitle(),
+		Description: e.GetContent("Notes"),
+	}, nil
+}
+
+// GetMetadata for KeePass returns an error indicating that it's unsupported
+// for this backend.
+//
+// The whole database is encrypted, so nothing can be read without the passphrase.
+func (k *keepassKeyring) GetMetadata(_ string) (Metadata, error) {
+	return Metadata{}, ErrMetadataNeedsCredentials
+}
+
+func (k *keepassKeyring) Set(_ Item) error {
+	return ErrReadOnly
+}
+
+func (k *keepassKeyring) Remove(_ string) error {
+	return ErrReadOnly
+}
+
+func (k *keepassKeyring) Keys() ([]string, error) {
+	entries, err := k.load()
+	if err != nil {
+		return nil, WrapError(err, KeePassBackend, k.service, "")
+	}
+
+	var keys = []string{}
+	prefix := k.title("")
+	for title := range entries {
+		if strings.HasPrefix(title, prefix) {
+			keys = append(keys, strings.TrimPrefix(title, prefix))
+		}
+	}
+
+	return keys, nil
+}
@@ -0,0 +1,104 @@
+package keyring
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/tobischo/gokeepasslib/v3"
+	w "github.com/tobischo/gokeepasslib/v3/wrappers"
+)
+
+func newKeePassEntry(title, password string) gokeepasslib.Entry {
+	e := gokeepasslib.NewEntry()
+	e.Values = append(e.Values,
+		gokeepasslib.ValueData{Key: "Title", Value: gokeepasslib.V{Content: title}},
+		gokeepasslib.ValueData{Key: "Password", Value: gokeepasslib.V{Content: password, Protected: w.NewBoolWrapper(true)}},
+	)
+	return e
+}
+
+func writeKeePassDatabase(t *testing.T, path, password string) {
+	t.Helper()
+
+	root := gokeepasslib.NewGroup()
+	root.Name = "root"
+	root.Entries = append(root.Entries, newKeePassEntry("test/llamas", "llamas are great"))
+
+	sub := gokeepasslib.NewGroup()
+	sub.Name = "sub"
+	sub.Entries = append(sub.Entries,
+		newKeePassEntry("test/alpacas", "alpacas are also great"),
+		newKeePassEntry("other/vicunas", "vicunas are elsewhere"),
+	)
+	root.Groups = append(root.Groups, sub)
+
+	db := &gokeepasslib.Database{
+		Header:      gokeepasslib.NewHeader(),
+		Credentials: gokeepasslib.NewPasswordCredentials(password),
+		Content: &gokeepasslib.DBContent{
+			Meta: gokeepasslib.NewMetaData(),
+			Root: &gokeepasslib.RootData{Groups: []gokeepasslib.Group{root}},
+		},
+	}
+	if err := db.LockProtectedEntries(); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if err = gokeepasslib.NewEncoder(f).Encode(db); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestKeePassKeyringReadsEntries(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keyring-keepass-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "test.kdbx")
+	writeKeePassDatabase(t, path, "no more secrets")
+
+	k, err := supportedBackends[KeePassBackend](Config{
+		ServiceName:         "test",
+		KeePassFile:         path,
+		KeePassPasswordFunc: fixedStringPrompt("no more secrets"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	item, err := k.Get("alpacas")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(item.Data) != "alpacas are also great" {
+		t.Fatalf("Unexpected password: %q", item.Data)
+	}
+
+	if _, err = k.Get("vicunas"); err != ErrKeyNotFound {
+		t.Fatalf("Expected entries for other services to be ignored, got: %v", err)
+	}
+
+	keys, err := k.Keys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(keys)
+	if len(keys) != 2 || keys[0] != "alpacas" || keys[1] != "llamas" {
+		t.Fatalf("Unexpected keys: %v", keys)
+	}
+
+	if err = k.Set(Item{Key: "llamas"}); err != ErrReadOnly {
+		t.Fatalf("Expected ErrReadOnly, got: %v", err)
+	}
+}
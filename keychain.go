@@ -1,3 +1,4 @@
+//go:build darwin && cgo
 // +build darwin,cgo
 
 package keyring
@@ -7,6 +8,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"os/exec"
 
 	gokeychain "github.com/keybase/go-keychain"
 	touchid "github.com/lox/go-touchid"
@@ -20,31 +22,42 @@ const (
 )
 
 type keychain struct {
-	path          string
-	service       string
-	passphrase    string
-	authenticated bool
+	path           string
+	service        string
+	searchServices []string
+	accessGroups   []string
+	passphrase     string
+	authenticated  bool
 
-	passwordFunc PromptFunc
+	passwordFunc          PromptFunc
+	allowEmptyPassphrase  bool
+	maxPassphraseAttempts int
 
 	isSynchronizable         bool
 	isAccessibleWhenUnlocked bool
 	isTrusted                bool
+	compressItems            bool
 }
 
 func init() {
 	supportedBackends[KeychainBackend] = opener(func(cfg Config) (Keyring, error) {
 		kc := &keychain{
-			service:      cfg.ServiceName,
-			passwordFunc: cfg.KeychainPasswordFunc,
+			service:        cfg.ServiceName,
+			searchServices: cfg.KeychainSearchServices,
+			accessGroups:   cfg.KeychainAccessGroups,
+			passwordFunc:   cfg.KeychainPasswordFunc,
+
+			allowEmptyPassphrase:  cfg.AllowEmptyPassphrase,
+			maxPassphraseAttempts: cfg.MaxPassphraseAttempts,
 
 			// Set the isAccessibleWhenUnlocked to the boolean value of
 			// KeychainAccessibleWhenUnlocked is a shorthand for setting the accessibility value.
 			// See: https://developer.apple.com/documentation/security/ksecattraccessiblewhenunlocked
 			isAccessibleWhenUnlocked: cfg.KeychainAccessibleWhenUnlocked,
+			compressItems:            cfg.KeychainCompressItems,
 		}
 		if cfg.KeychainName != "" {
-			kc.path = cfg.KeychainName + ".keychain"
+			kc.path = keychainPath(cfg.KeychainName)
 		}
 		if cfg.KeychainTrustApplication {
 			kc.isTrusted = true
@@ -53,10 +66,43 @@ func init() {
 	})
 }
 
+// services returns the services that items are read from
+func (k *keychain) services() []string {
+	if len(k.searchServices) > 0 {
+		return k.searchServices
+	}
+	return []string{k.service}
+}
+
+// searchAccessGroups returns the access groups that items are read from, where an empty
+// group means any that the application has access to
+func (k *keychain) searchAccessGroups() []string {
+	if len(k.accessGroups) > 0 {
+		return k.accessGroups
+	}
+	return []string{""}
+}
+
 func (k *keychain) Get(key string) (Item, error) {
+	for _, service := range k.services() {
+		for _, group := range k.searchAccessGroups() {
+			item, err := k.get(service, group, key)
+			if err == ErrKeyNotFound {
+				continue
+			}
+			return item, err
+		}
+	}
+	return Item{}, ErrKeyNotFound
+}
+
+func (k *keychain) get(service, group, key string) (Item, error) {
 	query := gokeychain.NewItem()
 	query.SetSecClass(gokeychain.SecClassGenericPassword)
-	query.SetService(k.service)
+	query.SetService(service)
+	if group != "" {
+		query.SetAccessGroup(group)
+	}
 	query.SetAccount(key)
 	query.SetMatchLimit(gokeychain.MatchLimitOne)
 	query.SetReturnAttributes(true)
@@ -67,16 +113,16 @@ func (k *keychain) Get(key string) (Item, error) {
 		query.SetMatchSearchList(gokeychain.NewWithPath(k.path))
 	}
 
-	debugf("Querying keychain for service=%q, account=%q, keychain=%q", k.service, key, k.path)
+	Debugf("Querying keychain for service=%q, account=%q, access group=%q, keychain=%q", service, key, group, k.path)
 	results, err := gokeychain.QueryItem(query)
 	if err == gokeychain.ErrorItemNotFound || len(results) == 0 {
-		debugf("No results found")
+		Debugf("No results found")
 		return Item{}, ErrKeyNotFound
 	}
 
 	if err != nil {
-		debugf("Error: %#v", err)
-		return Item{}, err
+		Debugf("Error: %#v", err)
+		return Item{}, WrapError(err, KeychainBackend, service, key)
 	}
 
 	item := Item{
@@ -84,9 +130,17 @@ func (k *keychain) Get(key string) (Item, error) {
 		Data:        results[0].Data,
 		Label:       results[0].Label,
 		Description: results[0].Description,
+		Tags:        map[string]string{"service": service},
+	}
+	if results[0].AccessGroup != "" {
+		item.Tags["access_group"] = results[0].AccessGroup
 	}
 
-	debugf("Found item %q", results[0].Label)
+	if k.compressItems {
+		item.Data = decompressKeychainData(item.Data)
+	}
+
+	Debugf("Found item %q", results[0].Label)
 	return item, nil
 }
 
@@ -100,14 +154,14 @@ func (k *keychain) GetMetadata(key string) (Metadata, error) {
 	query.SetReturnData(false)
 	query.SetReturnRef(true)
 
-	debugf("Querying keychain for metadata of service=%q, account=%q, keychain=%q", k.service, key, k.path)
+	Debugf("Querying keychain for metadata of service=%q, account=%q, keychain=%q", k.service, key, k.path)
 	results, err := gokeychain.QueryItem(query)
 	if err == gokeychain.ErrorItemNotFound || len(results) == 0 {
-		debugf("No results found")
+		Debugf("No results found")
 		return Metadata{}, ErrKeyNotFound
 	} else if err != nil {
-		debugf("Error: %#v", err)
-		return Metadata{}, err
+		Debugf("Error: %#v", err)
+		return Metadata{}, WrapError(err, KeychainBackend, k.service, key)
 	}
 
 	md := Metadata{
@@ -119,7 +173,7 @@ func (k *keychain) GetMetadata(key string) (Metadata, error) {
 		ModificationTime: results[0].ModificationDate,
 	}
 
-	debugf("Found metadata for %q", md.Item.Label)
+	Debugf("Found metadata for %q", md.Item.Label)
 
 	return md, nil
 }
@@ -132,17 +186,25 @@ func (k *keychain) Set(item Item) error {
 		var err error
 		kc, err = k.createOrOpen()
 		if err != nil {
-			return err
+			return WrapError(err, KeychainBackend, k.service, item.Key)
 		}
 	}
 
 	kcItem := gokeychain.NewItem()
 	kcItem.SetSecClass(gokeychain.SecClassGenericPassword)
 	kcItem.SetService(k.service)
+	if len(k.accessGroups) > 0 {
+		kcItem.SetAccessGroup(k.accessGroups[0])
+	}
 	kcItem.SetAccount(item.Key)
 	kcItem.SetLabel(item.Label)
 	kcItem.SetDescription(item.Description)
-	kcItem.SetData(item.Data)
+
+	if k.compressItems {
+		kcItem.SetData(compressKeychainData(item.Data))
+	} else {
+		kcItem.SetData(item.Data)
+	}
 
 	if k.path != "" {
 		kcItem.UseKeychain(kc)
@@ -159,26 +221,29 @@ func (k *keychain) Set(item Item) error {
 	isTrusted := k.isTrusted && !item.KeychainNotTrustApplication
 
 	if isTrusted {
-		debugf("Keychain item trusts keyring")
+		Debugf("Keychain item trusts keyring")
 		kcItem.SetAccess(&gokeychain.Access{
 			Label:               item.Label,
 			TrustedApplications: nil,
 		})
 	} else {
-		debugf("Keychain item doesn't trust keyring")
+		Debugf("Keychain item doesn't trust keyring")
 		kcItem.SetAccess(&gokeychain.Access{
 			Label:               item.Label,
 			TrustedApplications: []string{},
 		})
 	}
 
-	debugf("Adding service=%q, label=%q, account=%q, trusted=%v to osx keychain %q", k.service, item.Label, item.Key, isTrusted, k.path)
+	Debugf("Adding service=%q, label=%q, account=%q, trusted=%v to osx keychain %q", k.service, item.Label, item.Key, isTrusted, k.path)
 
 	if err := gokeychain.AddItem(kcItem); err == gokeychain.ErrorDuplicateItem {
-		debugf("Item already exists, updating")
+		Debugf("Item already exists, updating")
 		queryItem := gokeychain.NewItem()
 		queryItem.SetSecClass(gokeychain.SecClassGenericPassword)
 		queryItem.SetService(k.service)
+		if len(k.accessGroups) > 0 {
+			queryItem.SetAccessGroup(k.accessGroups[0])
+		}
 		queryItem.SetAccount(item.Key)
 		queryItem.SetMatchLimit(gokeychain.MatchLimitOne)
 		queryItem.SetReturnAttributes(true)
@@ -189,7 +254,7 @@ func (k *keychain) Set(item Item) error {
 
 		results, err := gokeychain.QueryItem(queryItem)
 		if err != nil {
-			return fmt.Errorf("Failed to query keychain: %v", err)
+			return WrapError(fmt.Errorf("Failed to query keychain: %w", err), KeychainBackend, k.service, item.Key)
 		}
 		if len(results) == 0 {
 			return errors.New("no results")
@@ -199,7 +264,7 @@ func (k *keychain) Set(item Item) error {
 		kcItem.SetAccess(nil)
 
 		if err := gokeychain.UpdateItem(queryItem, kcItem); err != nil {
-			return fmt.Errorf("Failed to update item in keychain: %v", err)
+			return WrapError(fmt.Errorf("Failed to update item in keychain: %w", err), KeychainBackend, k.service, item.Key)
 		}
 	}
 
@@ -219,20 +284,45 @@ func (k *keychain) Remove(key string) error {
 			if err == gokeychain.ErrorNoSuchKeychain {
 				return ErrKeyNotFound
 			}
-			return err
+			return WrapError(err, KeychainBackend, k.service, key)
 		}
 
 		item.SetMatchSearchList(kc)
 	}
 
-	debugf("Removing keychain item service=%q, account=%q, keychain %q", k.service, key, k.path)
-	return gokeychain.DeleteItem(item)
+	Debugf("Removing keychain item service=%q, account=%q, keychain %q", k.service, key, k.path)
+	return WrapError(gokeychain.DeleteItem(item), KeychainBackend, k.service, key)
 }
 
+// Keys lists the keys of every service and access group that items are read from, without
+// duplicates
 func (k *keychain) Keys() ([]string, error) {
+	var keys = []string{}
+	seen := map[string]bool{}
+	for _, service := range k.services() {
+		for _, group := range k.searchAccessGroups() {
+			accountNames, err := k.keys(service, group)
+			if err != nil {
+				return nil, err
+			}
+			for _, name := range accountNames {
+				if !seen[name] {
+					seen[name] = true
+					keys = append(keys, name)
+				}
+			}
+		}
+	}
+	return keys, nil
+}
+
+func (k *keychain) keys(service, group string) ([]string, error) {
 	query := gokeychain.NewItem()
 	query.SetSecClass(gokeychain.SecClassGenericPassword)
-	query.SetService(k.service)
+	query.SetService(service)
+	if group != "" {
+		query.SetAccessGroup(group)
+	}
 	query.SetMatchLimit(gokeychain.MatchLimitAll)
 	query.SetReturnAttributes(true)
 
@@ -243,19 +333,19 @@ func (k *keychain) Keys() ([]string, error) {
 			if err == gokeychain.ErrorNoSuchKeychain {
 				return []string{}, nil
 			}
-			return nil, err
+			return nil, WrapError(err, KeychainBackend, service, "")
 		}
 
 		query.SetMatchSearchList(kc)
 	}
 
-	debugf("Querying keychain for service=%q, keychain=%q", k.service, k.path)
+	Debugf("Querying keychain for service=%q, access group=%q, keychain=%q", service, group, k.path)
 	results, err := gokeychain.QueryItem(query)
 	if err != nil {
-		return nil, err
+		return nil, WrapError(err, KeychainBackend, service, "")
 	}
 
-	debugf("Found %d results", len(results))
+	Debugf("Found %d results", len(results))
 	accountNames := make([]string, len(results))
 	for idx, r := range results {
 		accountNames[idx] = r.Account
@@ -264,6 +354,40 @@ func (k *keychain) Keys() ([]string, error) {
 	return accountNames, nil
 }
 
+// indexMetadata reads the attributes of every item of the service with a single query
+func (k *keychain) indexMetadata() (map[string]Metadata, error) {
+	query := gokeychain.NewItem()
+	query.SetSecClass(gokeychain.SecClassGenericPassword)
+	query.SetService(k.service)
+	query.SetMatchLimit(gokeychain.MatchLimitAll)
+	query.SetReturnAttributes(true)
+
+	if k.path != "" {
+		query.SetMatchSearchList(gokeychain.NewWithPath(k.path))
+	}
+
+	Debugf("Querying keychain for metadata of service=%q, keychain=%q", k.service, k.path)
+	results, err := gokeychain.QueryItem(query)
+	if err == gokeychain.ErrorItemNotFound {
+		return map[string]Metadata{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	index := make(map[string]Metadata, len(results))
+	for _, r := range results {
+		index[r.Account] = Metadata{
+			Item: &Item{
+				Key:         r.Account,
+				Label:       r.Label,
+				Description: r.Description,
+			},
+			ModificationTime: r.ModificationDate,
+		}
+	}
+	return index, nil
+}
+
 func (k *keychain) setupBiometrics() error {
 	fmt.Println("\nTo use biometrics for authentication, your keychain password needs to be stored in your login keychain.\n" +
 		"You will be prompted for your password.\n")
@@ -344,31 +468,50 @@ func (k *keychain) openWithBiometrics() (gokeychain.Keychain, error) {
 func (k *keychain) createOrOpen() (gokeychain.Keychain, error) {
 	kc := gokeychain.NewWithPath(k.path)
 
-	debugf("Checking keychain status")
+	Debugf("Checking keychain status")
 	err := kc.Status()
 	if err == nil {
-		debugf("Keychain status returned nil, keychain exists")
+		Debugf("Keychain status returned nil, keychain exists")
 		log.Printf("Opening %s with biometrics", k.path)
 		return k.openWithBiometrics()
 		//return kc, nil
 	}
 
-	debugf("Keychain status returned error: %v", err)
+	Debugf("Keychain status returned error: %v", err)
 
 	if err != gokeychain.ErrorNoSuchKeychain {
 		return gokeychain.Keychain{}, err
 	}
 
 	if k.passwordFunc == nil {
-		debugf("Creating keychain %s with prompt", k.path)
+		Debugf("Creating keychain %s with prompt", k.path)
 		return gokeychain.NewKeychainWithPrompt(k.path)
 	}
 
-	passphrase, err := k.passwordFunc("Enter passphrase for keychain")
+	passphrase, err := promptPassphrase(k.passwordFunc, "Enter passphrase for keychain", k.allowEmptyPassphrase, k.maxPassphraseAttempts)
 	if err != nil {
 		return gokeychain.Keychain{}, err
 	}
 
-	debugf("Creating keychain %s with provided password", k.path)
+	Debugf("Creating keychain %s with provided password", k.path)
 	return gokeychain.NewKeychain(k.path, passphrase)
 }
+
+// ListKeychains returns the paths of the keychains in the user's search list
+func ListKeychains() ([]string, error) {
+	out, err := exec.Command("security", "list-keychains", "-d", "user").Output()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to list keychains: %v", err)
+	}
+	return parseKeychainList(out), nil
+}
+
+// PickKeychainInteractively lists the user's keychains in the terminal and asks them to
+// choose one, returning its path for use as Config.KeychainName
+func PickKeychainInteractively(prompt string) (string, error) {
+	paths, err := ListKeychains()
+	if err != nil {
+		return "", err
+	}
+	return pickKeychain(paths, prompt, terminalPrompt)
+}
@@ -0,0 +1,28 @@
+package keyring
+
+import "github.com/golang/snappy"
+
+// keychainSnappyHeader is prepended to Keychain item data that has been compressed with
+// Snappy when Config.KeychainCompressItems is set
+const keychainSnappyHeader byte = 0x01
+
+func compressKeychainData(data []byte) []byte {
+	compressed := make([]byte, 1+snappy.MaxEncodedLen(len(data)))
+	compressed[0] = keychainSnappyHeader
+	return compressed[:1+len(snappy.Encode(compressed[1:], data))]
+}
+
+// decompressKeychainData returns data unchanged unless it has a valid Snappy header, so that
+// items stored before compression was enabled can still be read
+func decompressKeychainData(data []byte) []byte {
+	if len(data) == 0 || data[0] != keychainSnappyHeader {
+		return data
+	}
+
+	decoded, err := snappy.Decode(nil, data[1:])
+	if err != nil {
+		Debugf("Keychain item data has a compression header but isn't compressed: %v", err)
+		return data
+	}
+	return decoded
+}
@@ -0,0 +1,31 @@
+package keyring
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestKeychainCompressionRoundTrip(t *testing.T) {
+	data := bytes.Repeat([]byte("llamas are great "), 100)
+
+	compressed := compressKeychainData(data)
+	if compressed[0] != keychainSnappyHeader || len(compressed) >= len(data) {
+		t.Fatalf("Expected compressed data with a header, got %d bytes", len(compressed))
+	}
+
+	if !bytes.Equal(decompressKeychainData(compressed), data) {
+		t.Fatal("Decompressed data doesn't match")
+	}
+}
+
+func TestKeychainDecompressionLeavesUncompressedData(t *testing.T) {
+	for _, data := range [][]byte{
+		[]byte("llamas are great"),
+		{keychainSnappyHeader, 0xff, 0xff, 0xff},
+		{},
+	} {
+		if !bytes.Equal(decompressKeychainData(data), data) {
+			t.Fatalf("Expected %q to be returned unchanged", data)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package keyring
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// parseKeychainList parses the output of `security list-keychains`, which prints one
+// quoted path per line
+func parseKeychainList(output []byte) []string {
+	var paths []string
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		path := strings.Trim(strings.TrimSpace(scanner.Text()), `"`)
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// keychainPath returns the path of the keychain named by Config.KeychainName. Bare names
+// get a .keychain extension, as the security tool does, while absolute paths are used as is.
+func keychainPath(name string) string {
+	if filepath.IsAbs(name) {
+		return name
+	}
+	return name + ".keychain"
+}
+
+// pickKeychain asks the user to choose one of paths by number, returning the chosen path.
+// Paths are returned unchanged, as a name derived from them wouldn't resolve to keychains
+// outside ~/Library/Keychains, or to ones saved as -db files.
+func pickKeychain(paths []string, prompt string, promptFunc PromptFunc) (string, error) {
+	if len(paths) == 0 {
+		return "", fmt.Errorf("No keychains found")
+	}
+
+	var b strings.Builder
+	for idx, path := range paths {
+		fmt.Fprintf(&b, "%d) %s\n", idx+1, path)
+	}
+	b.WriteString(prompt)
+
+	answer, err := promptFunc(b.String())
+	if err != nil {
+		return "", err
+	}
+
+	choice, err := strconv.Atoi(strings.TrimSpace(answer))
+	if err != nil || choice < 1 || choice > len(paths) {
+		return "", fmt.Errorf("Invalid keychain choice %q", answer)
+	}
+
+	return paths[choice-1], nil
+}
@@ -0,0 +1,59 @@
+package keyring
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const securityListKeychainsOutput = `    "/Users/llama/Library/Keychains/login.keychain-db"
+    "/Users/llama/Library/Keychains/aws-vault.keychain-db"
+    "/Library/Keychains/System.keychain"
+`
+
+func TestParseKeychainList(t *testing.T) {
+	paths := parseKeychainList([]byte(securityListKeychainsOutput))
+	expected := []string{
+		"/Users/llama/Library/Keychains/login.keychain-db",
+		"/Users/llama/Library/Keychains/aws-vault.keychain-db",
+		"/Library/Keychains/System.keychain",
+	}
+	if !reflect.DeepEqual(paths, expected) {
+		t.Fatalf("Unexpected paths: %v", paths)
+	}
+}
+
+func TestPickKeychain(t *testing.T) {
+	paths := parseKeychainList([]byte(securityListKeychainsOutput))
+
+	var shown string
+	path, err := pickKeychain(paths, "Choose a keychain", func(prompt string) (string, error) {
+		shown = prompt
+		return "2\n", nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != "/Users/llama/Library/Keychains/aws-vault.keychain-db" {
+		t.Fatalf("Expected the keychain's path, got: %q", path)
+	}
+	if keychainPath(path) != path {
+		t.Fatalf("Expected the path to be usable as Config.KeychainName, got: %q", keychainPath(path))
+	}
+	if !strings.Contains(shown, "3) /Library/Keychains/System.keychain") {
+		t.Fatalf("Expected the prompt to list keychains, got: %q", shown)
+	}
+
+	if _, err = pickKeychain(paths, "Choose a keychain", fixedStringPrompt("4")); err == nil {
+		t.Fatal("Expected an out of range choice to fail")
+	}
+}
+
+func TestKeychainPath(t *testing.T) {
+	if path := keychainPath("aws-vault"); path != "aws-vault.keychain" {
+		t.Fatalf("Expected .keychain to be appended to a bare name, got %q", path)
+	}
+	if path := keychainPath("/Users/llama/Documents/myapp.keychain"); path != "/Users/llama/Documents/myapp.keychain" {
+		t.Fatalf("Expected an absolute path to be used as is, got %q", path)
+	}
+}
@@ -14,13 +14,20 @@ type BackendType string
 
 // All currently supported secure storage backends
 const (
-	InvalidBackend       BackendType = ""
-	SecretServiceBackend BackendType = "secret-service"
-	KeychainBackend      BackendType = "keychain"
-	KWalletBackend       BackendType = "kwallet"
-	WinCredBackend       BackendType = "wincred"
-	FileBackend          BackendType = "file"
-	PassBackend          BackendType = "pass"
+	InvalidBackend            BackendType = ""
+	SecretServiceBackend      BackendType = "secret-service"
+	KeychainBackend           BackendType = "keychain"
+	KWalletBackend            BackendType = "kwallet"
+	WinCredBackend            BackendType = "wincred"
+	FileBackend               BackendType = "file"
+	PassBackend               BackendType = "pass"
+	PKCS11Backend             BackendType = "pkcs11"
+	AgeBackend                BackendType = "age"
+	KeePassBackend            BackendType = "keepass"
+	SealedBoxBackend          BackendType = "sealedbox"
+	GitCredentialStoreBackend BackendType = "git-credential-store"
+	SSHAgentBackend           BackendType = "ssh-agent"
+	WASMBackend               BackendType = "wasm"
 )
 
 // This order makes sure the OS-specific backends
@@ -33,9 +40,20 @@ var backendOrder = []BackendType{
 	// Linux
 	SecretServiceBackend,
 	KWalletBackend,
+	// Browser
+	WASMBackend,
+	// Hardware
+	PKCS11Backend,
 	// General
 	PassBackend,
+	AgeBackend,
+	KeePassBackend,
+	SealedBoxBackend,
 	FileBackend,
+	// Special purpose, these open without any configuration so they come after the file
+	// backend and are only used when allowed explicitly
+	GitCredentialStoreBackend,
+	SSHAgentBackend,
 }
 
 var supportedBackends = map[BackendType]opener{}
@@ -56,23 +74,53 @@ type opener func(cfg Config) (Keyring, error)
 
 // Open will open a specific keyring backend
 func Open(cfg Config) (Keyring, error) {
+	if err := validateKeyPrefix(cfg.KeyPrefix); err != nil {
+		return nil, err
+	}
 	if cfg.AllowedBackends == nil {
 		cfg.AllowedBackends = AvailableBackends()
 	}
-	debugf("Considering backends: %v", cfg.AllowedBackends)
+	Debugf("Considering backends: %v", cfg.AllowedBackends)
 	for _, backend := range cfg.AllowedBackends {
 		if opener, ok := supportedBackends[backend]; ok {
 			openBackend, err := opener(cfg)
 			if err != nil {
-				debugf("Failed backend %s: %s", backend, err)
+				Debugf("Failed backend %s: %s", backend, err)
 				continue
 			}
-			return openBackend, nil
+			return wrap(openBackend, cfg), nil
 		}
 	}
 	return nil, ErrNoAvailImpl
 }
 
+// wrap applies the backend-independent behaviour requested in cfg to an opened keyring
+func wrap(kr Keyring, cfg Config) Keyring {
+	if cfg.ItemChecksums {
+		kr = newChecksumKeyring(kr)
+	}
+	if cfg.KeyPrefix != "" {
+		kr = newPrefixKeyring(kr, cfg.KeyPrefix)
+	}
+	if cfg.MaxReadConcurrent > 0 || cfg.MaxWriteConcurrent > 0 {
+		kr = newConcurrentKeyring(kr, cfg.MaxReadConcurrent, cfg.MaxWriteConcurrent, cfg.ConcurrencyTimeout)
+	}
+	if cfg.Namespaces {
+		kr = newNamespaceKeyring(kr, DefaultNamespace)
+	}
+	// Validation sees keys without their namespace
+	if len(cfg.SchemaValidators) > 0 {
+		kr = newSchemaKeyring(kr, cfg.SchemaValidators)
+	}
+	if cfg.MaxItemDataSize > 0 {
+		kr = newSizeLimitKeyring(kr, cfg.MaxItemDataSize)
+	}
+	if cfg.ClearOnExit {
+		kr = clearOnExit(kr)
+	}
+	return kr
+}
+
 // Item is a thing stored on the keyring
 type Item struct {
 	Key         string
@@ -80,9 +128,35 @@ type Item struct {
 	Label       string
 	Description string
 
+	// Namespace groups items within a keyring opened with Config.Namespaces, where it's
+	// stored as part of the key as "<namespace>/<key>". Empty means the keyring's namespace.
+	Namespace string
+
+	// NotBefore is the time from which Get will return the item. Zero means it's active
+	// immediately. Backends that only store the item's data, such as the macOS Keychain
+	// and PKCS#11, don't keep it.
+	NotBefore time.Time
+
+	// Checksum is the hex encoded SHA-256 of Data, set by Set when Config.ItemChecksums is
+	// enabled. Backends that only store the item's data keep it in a sidecar item instead.
+	Checksum string
+
+	// RotationSchedule is a cron expression describing how often the item should be
+	// rotated, for rotation tools to read. It's only kept by backends that store the whole
+	// item, which reject schedules that don't parse with ErrInvalidRotationSchedule.
+	RotationSchedule string
+
+	// Tags are extra details set by the backend an item was read from, such as the
+	// "service" that the macOS Keychain found it under
+	Tags map[string]string
+
 	// Backend specific config
 	KeychainNotTrustApplication bool
 	KeychainNotSynchronizable   bool
+
+	// AgeRecipients are the age recipients that the age backend encrypts this item to,
+	// in place of Config.AgeRecipients, so that only their identities can read it
+	AgeRecipients []string
 }
 
 // Metadata is information about a thing stored on the keyring; retrieving
@@ -115,6 +189,24 @@ var ErrNoAvailImpl = errors.New("Specified keyring backend not available")
 // ErrKeyNotFound is returned by Keyring Get when the item is not on the keyring
 var ErrKeyNotFound = errors.New("The specified item could not be found in the keyring")
 
+// ErrReadOnly is returned by Keyring Set and Remove when the backend can't be written to
+var ErrReadOnly = errors.New("The keyring backend is read-only")
+
+// ErrCorrupted is returned by Keyring Get when an item fails its integrity check
+var ErrCorrupted = errors.New("The specified item has been modified or corrupted")
+
+// ErrNotYetActive is returned by Keyring Get when the item's NotBefore time hasn't passed yet
+var ErrNotYetActive = errors.New("The specified item is not yet active")
+
+// checkActive returns ErrNotYetActive in place of an item whose NotBefore is in the future
+func checkActive(item Item) (Item, error) {
+	if !item.NotBefore.IsZero() && time.Now().Before(item.NotBefore) {
+		Debugf("Item %q is not active until %s", item.Key, item.NotBefore)
+		return Item{}, ErrNotYetActive
+	}
+	return item, nil
+}
+
 // ErrMetadataNeedsCredentials is returned when Metadata is called against a
 // backend which requires credentials even to see metadata.
 var ErrMetadataNeedsCredentials = errors.New("The keyring backend requires credentials for metadata access")
@@ -124,7 +216,9 @@ var (
 	Debug bool
 )
 
-func debugf(pattern string, args ...interface{}) {
+// Debugf logs a debugging message when Debug is set. Packages that extend the keyring
+// use it so that all of the keyring's debugging output is switched on together.
+func Debugf(pattern string, args ...interface{}) {
 	if Debug {
 		log.Printf("[keyring] "+pattern, args...)
 	}
@@ -1,3 +1,4 @@
+//go:build linux
 // +build linux
 
 package keyring
@@ -83,21 +84,21 @@ func (k *kwalletKeyring) openWallet() error {
 func (k *kwalletKeyring) Get(key string) (Item, error) {
 	err := k.openWallet()
 	if err != nil {
-		return Item{}, err
+		return Item{}, WrapError(err, KWalletBackend, k.folder, key)
 	}
 
 	data, err := k.wallet.ReadEntry(k.handle, k.folder, key, k.appID)
 	if err != nil {
-		return Item{}, err
+		return Item{}, WrapError(err, KWalletBackend, k.folder, key)
 	}
 
 	item := Item{}
 	err = json.Unmarshal(data, &item)
 	if err != nil {
-		return Item{}, err
+		return Item{}, WrapError(err, KWalletBackend, k.folder, key)
 	}
 
-	return item, nil
+	return checkActive(item)
 }
 
 // GetMetadata for kwallet returns an error indicating that it's unsupported
@@ -113,17 +114,17 @@ func (k *kwalletKeyring) GetMetadata(_ string) (Metadata, error) {
 func (k *kwalletKeyring) Set(item Item) error {
 	err := k.openWallet()
 	if err != nil {
-		return err
+		return WrapError(err, KWalletBackend, k.folder, item.Key)
 	}
 
 	data, err := json.Marshal(item)
 	if err != nil {
-		return err
+		return WrapError(err, KWalletBackend, k.folder, item.Key)
 	}
 
 	err = k.wallet.WriteEntry(k.handle, k.folder, item.Key, data, k.appID)
 	if err != nil {
-		return err
+		return WrapError(err, KWalletBackend, k.folder, item.Key)
 	}
 
 	return nil
@@ -132,12 +133,12 @@ func (k *kwalletKeyring) Set(item Item) error {
 func (k *kwalletKeyring) Remove(key string) error {
 	err := k.openWallet()
 	if err != nil {
-		return err
+		return WrapError(err, KWalletBackend, k.folder, key)
 	}
 
 	err = k.wallet.RemoveEntry(k.handle, k.folder, key, k.appID)
 	if err != nil {
-		return err
+		return WrapError(err, KWalletBackend, k.folder, key)
 	}
 
 	return nil
@@ -146,12 +147,12 @@ func (k *kwalletKeyring) Remove(key string) error {
 func (k *kwalletKeyring) Keys() ([]string, error) {
 	err := k.openWallet()
 	if err != nil {
-		return []string{}, err
+		return []string{}, WrapError(err, KWalletBackend, k.folder, "")
 	}
 
 	entries, err := k.wallet.EntryList(k.handle, k.folder, k.appID)
 	if err != nil {
-		return []string{}, err
+		return []string{}, WrapError(err, KWalletBackend, k.folder, "")
 	}
 
 	return entries, nil
@@ -1,3 +1,4 @@
+//go:build linux
 // +build linux
 
 package keyring
@@ -102,12 +103,12 @@ func (k *secretsKeyring) Get(key string) (Item, error) {
 		if err == errCollectionNotFound {
 			return Item{}, ErrKeyNotFound
 		}
-		return Item{}, err
+		return Item{}, WrapError(err, SecretServiceBackend, k.name, key)
 	}
 
 	items, err := k.collection.SearchItems(key)
 	if err != nil {
-		return Item{}, err
+		return Item{}, WrapError(err, SecretServiceBackend, k.name, key)
 	}
 
 	if len(items) == 0 {
@@ -120,27 +121,27 @@ func (k *secretsKeyring) Get(key string) (Item, error) {
 
 	locked, err := item.Locked()
 	if err != nil {
-		return Item{}, err
+		return Item{}, WrapError(err, SecretServiceBackend, k.name, key)
 	}
 
 	if locked {
 		if err := k.service.Unlock(item); err != nil {
-			return Item{}, err
+			return Item{}, WrapError(err, SecretServiceBackend, k.name, key)
 		}
 	}
 
 	secret, err := item.GetSecret(k.session)
 	if err != nil {
-		return Item{}, err
+		return Item{}, WrapError(err, SecretServiceBackend, k.name, key)
 	}
 
 	// pack the secret into the item
 	var ret Item
 	if err = json.Unmarshal(secret.Value, &ret); err != nil {
-		return Item{}, err
+		return Item{}, WrapError(err, SecretServiceBackend, k.name, key)
 	}
 
-	return ret, err
+	return checkActive(ret)
 }
 
 // GetMetadata for libsecret returns an error indicating that it's unsupported
@@ -158,14 +159,14 @@ func (k *secretsKeyring) GetMetadata(key string) (Metadata, error) {
 func (k *secretsKeyring) Set(item Item) error {
 	err := k.openSecrets()
 	if err != nil {
-		return err
+		return WrapError(err, SecretServiceBackend, k.name, item.Key)
 	}
 
 	// create the collection if it doesn't already exist
 	if k.collection == nil {
 		collection, err := k.service.CreateCollection(k.name)
 		if err != nil {
-			return err
+			return WrapError(err, SecretServiceBackend, k.name, item.Key)
 		}
 
 		k.collection = collection
@@ -174,7 +175,7 @@ func (k *secretsKeyring) Set(item Item) error {
 	// create the new item
 	data, err := json.Marshal(item)
 	if err != nil {
-		return err
+		return WrapError(err, SecretServiceBackend, k.name, item.Key)
 	}
 
 	secret := libsecret.NewSecret(k.session, []byte{}, data, "application/json")
@@ -182,17 +183,17 @@ func (k *secretsKeyring) Set(item Item) error {
 	// unlock the collection first
 	locked, err := k.collection.Locked()
 	if err != nil {
-		return err
+		return WrapError(err, SecretServiceBackend, k.name, item.Key)
 	}
 
 	if locked {
 		if err := k.service.Unlock(k.collection); err != nil {
-			return err
+			return WrapError(err, SecretServiceBackend, k.name, item.Key)
 		}
 	}
 
 	if _, err := k.collection.CreateItem(item.Key, secret, true); err != nil {
-		return err
+		return WrapError(err, SecretServiceBackend, k.name, item.Key)
 	}
 
 	return nil
@@ -203,12 +204,12 @@ func (k *secretsKeyring) Remove(key string) error {
 		if err == errCollectionNotFound {
 			return ErrKeyNotFound
 		}
-		return err
+		return WrapError(err, SecretServiceBackend, k.name, key)
 	}
 
 	items, err := k.collection.SearchItems(key)
 	if err != nil {
-		return err
+		return WrapError(err, SecretServiceBackend, k.name, key)
 	}
 
 	// nothing to delete
@@ -222,17 +223,17 @@ func (k *secretsKeyring) Remove(key string) error {
 
 	locked, err := item.Locked()
 	if err != nil {
-		return err
+		return WrapError(err, SecretServiceBackend, k.name, key)
 	}
 
 	if locked {
 		if err := k.service.Unlock(item); err != nil {
-			return err
+			return WrapError(err, SecretServiceBackend, k.name, key)
 		}
 	}
 
 	if err := item.Delete(); err != nil {
-		return err
+		return WrapError(err, SecretServiceBackend, k.name, key)
 	}
 
 	return nil
@@ -243,12 +244,12 @@ func (k *secretsKeyring) Keys() ([]string, error) {
 		if err == errCollectionNotFound {
 			return []string{}, nil
 		}
-		return []string{}, err
+		return []string{}, WrapError(err, SecretServiceBackend, k.name, "")
 	}
 
 	items, err := k.collection.Items()
 	if err != nil {
-		return []string{}, err
+		return []string{}, WrapError(err, SecretServiceBackend, k.name, "")
 	}
 
 	keys := []string{}
@@ -0,0 +1,132 @@
+package keyring
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+)
+
+// CacheStats describes how well a LRUCachedKeyring's cache is performing
+type CacheStats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+}
+
+// LRUCachedKeyring keeps recently used items from another Keyring in memory. Once the
+// cache is full, the least recently used item is evicted to make room for new ones.
+type LRUCachedKeyring struct {
+	kr    Keyring
+	ttl   time.Duration
+	cache *lru.Cache[string, lruCacheEntry]
+
+	mu    sync.Mutex
+	stats CacheStats
+}
+
+type lruCacheEntry struct {
+	item    Item
+	expires time.Time
+}
+
+// NewLRUCachedKeyring returns a LRUCachedKeyring that caches up to maxItems items read
+// from or written to kr. Cached items are discarded after ttl, a ttl of zero keeps them
+// until they are evicted. maxItems must be at least one.
+func NewLRUCachedKeyring(kr Keyring, maxItems int, ttl time.Duration) (*LRUCachedKeyring, error) {
+	if maxItems < 1 {
+		return nil, fmt.Errorf("Invalid cache size %d, it must hold at least one item", maxItems)
+	}
+
+	cache, err := lru.New[string, lruCacheEntry](maxItems)
+	if err != nil {
+		return nil, err
+	}
+	return &LRUCachedKeyring{
+		kr:    kr,
+		ttl:   ttl,
+		cache: cache,
+	}, nil
+}
+
+// Get returns the cached Item matching key, falling back to the underlying Keyring
+func (k *LRUCachedKeyring) Get(key string) (Item, error) {
+	if entry, ok := k.cache.Get(key); ok {
+		if k.ttl == 0 || time.Now().Before(entry.expires) {
+			k.mu.Lock()
+			k.stats.Hits++
+			k.mu.Unlock()
+			return checkActive(copyData(entry.item))
+		}
+		Debugf("Cached item %q has expired", key)
+		k.cache.Remove(key)
+	}
+
+	k.mu.Lock()
+	k.stats.Misses++
+	k.mu.Unlock()
+
+	item, err := k.kr.Get(key)
+	if err != nil {
+		return Item{}, err
+	}
+	k.add(item)
+
+	return item, nil
+}
+
+// GetMetadata is passed through to the underlying Keyring
+func (k *LRUCachedKeyring) GetMetadata(key string) (Metadata, error) {
+	return k.kr.GetMetadata(key)
+}
+
+// Set stores the item on the underlying Keyring and caches it
+func (k *LRUCachedKeyring) Set(item Item) error {
+	if err := k.kr.Set(item); err != nil {
+		k.cache.Remove(item.Key)
+		return err
+	}
+	k.add(item)
+	return nil
+}
+
+// Remove deletes the item from both the cache and the underlying Keyring
+func (k *LRUCachedKeyring) Remove(key string) error {
+	k.cache.Remove(key)
+	return k.kr.Remove(key)
+}
+
+// Keys is passed through to the underlying Keyring
+func (k *LRUCachedKeyring) Keys() ([]string, error) {
+	return k.kr.Keys()
+}
+
+// Stats returns the cache hit, miss and eviction counts so far
+func (k *LRUCachedKeyring) Stats() CacheStats {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.stats
+}
+
+// add caches a copy of item, so that callers changing its Data don't change the cache
+func (k *LRUCachedKeyring) add(item Item) {
+	entry := lruCacheEntry{item: copyData(item)}
+	if k.ttl > 0 {
+		entry.expires = time.Now().Add(k.ttl)
+	}
+
+	if evicted := k.cache.Add(item.Key, entry); evicted {
+		k.mu.Lock()
+		k.stats.Evictions++
+		k.mu.Unlock()
+	}
+}
+
+// copyData returns item with its own copy of Data
+func copyData(item Item) Item {
+	if item.Data != nil {
+		item.Data = append([]byte{}, item.Data...)
+	}
+	return item
+}
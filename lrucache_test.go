@@ -0,0 +1,125 @@
+package keyring
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLRUCachedKeyringServesFromCache(t *testing.T) {
+	backing := NewArrayKeyring([]Item{{Key: "llamas", Data: []byte("llamas are great")}})
+	k, err := NewLRUCachedKeyring(backing, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		foundItem, err := k.Get("llamas")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(foundItem.Data) != "llamas are great" {
+			t.Fatalf("Value stored was not the value retrieved: %q", foundItem.Data)
+		}
+	}
+
+	if stats := k.Stats(); stats.Hits != 2 || stats.Misses != 1 {
+		t.Fatalf("Expected 2 hits and 1 miss, got %+v", stats)
+	}
+}
+
+func TestLRUCachedKeyringEvictsLeastRecentlyUsed(t *testing.T) {
+	k, err := NewLRUCachedKeyring(&ArrayKeyring{}, 2, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{"llamas", "alpacas", "vicunas"} {
+		if err := k.Set(Item{Key: key, Data: []byte(key)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if stats := k.Stats(); stats.Evictions != 1 {
+		t.Fatalf("Expected 1 eviction, got %+v", stats)
+	}
+
+	if _, err := k.Get("llamas"); err != nil {
+		t.Fatal(err)
+	}
+	if stats := k.Stats(); stats.Misses != 1 {
+		t.Fatalf("Expected evicted item to be a miss, got %+v", stats)
+	}
+}
+
+func TestLRUCachedKeyringExpiresItems(t *testing.T) {
+	backing := NewArrayKeyring([]Item{{Key: "llamas", Data: []byte("llamas are great")}})
+	k, err := NewLRUCachedKeyring(backing, 10, time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := k.Get("llamas"); err != nil {
+		t.Fatal(err)
+	}
+
+	_ = backing.Set(Item{Key: "llamas", Data: []byte("llamas are grand")})
+	time.Sleep(5 * time.Millisecond)
+
+	foundItem, err := k.Get("llamas")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(foundItem.Data) != "llamas are grand" {
+		t.Fatalf("Expected expired item to be refetched, got %q", foundItem.Data)
+	}
+}
+
+func TestLRUCachedKeyringRemove(t *testing.T) {
+	k, err := NewLRUCachedKeyring(&ArrayKeyring{}, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := k.Set(Item{Key: "llamas", Data: []byte("llamas are great")}); err != nil {
+		t.Fatal(err)
+	}
+	if err := k.Remove("llamas"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := k.Get("llamas"); err != ErrKeyNotFound {
+		t.Fatalf("Expected ErrKeyNotFound, got: %s", err)
+	}
+}
+
+func TestLRUCachedKeyringRejectsEmptyCache(t *testing.T) {
+	if _, err := NewLRUCachedKeyring(&ArrayKeyring{}, 0, 0); err == nil {
+		t.Fatal("Expected an error for a cache that can't hold any items")
+	}
+}
+
+func TestLRUCachedKeyringCopiesData(t *testing.T) {
+	k, err := NewLRUCachedKeyring(&ArrayKeyring{}, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := []byte("llamas are great")
+	if err = k.Set(Item{Key: "llamas", Data: data}); err != nil {
+		t.Fatal(err)
+	}
+	copy(data, "alpacas")
+
+	foundItem, err := k.Get("llamas")
+	if err != nil {
+		t.Fatal(err)
+	}
+	copy(foundItem.Data, "vicunas")
+
+	if foundItem, err = k.Get("llamas"); err != nil {
+		t.Fatal(err)
+	}
+	if string(foundItem.Data) != "llamas are great" {
+		t.Fatalf("Expected the cached data not to change, got %q", foundItem.Data)
+	}
+}
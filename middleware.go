@@ -0,0 +1,88 @@
+package keyring
+
+// Names of Keyring operations, as passed to Middleware
+const (
+	OpGet         = "Get"
+	OpGetMetadata = "GetMetadata"
+	OpSet         = "Set"
+	OpRemove      = "Remove"
+	OpKeys        = "Keys"
+)
+
+// Middleware is called around each operation on a Keyring returned by NewMiddlewareKeyring.
+// The key is empty for Keys.
+type Middleware interface {
+	// Before is called before the operation runs. Returning an error aborts it.
+	Before(op string, key string) error
+	// After is called once the operation has run, with the error it returned
+	After(op string, key string, err error)
+}
+
+type middlewareKeyring struct {
+	kr Keyring
+	mw []Middleware
+}
+
+// NewMiddlewareKeyring wraps kr so that each operation passes through mw. Before hooks are
+// called in the order given and After hooks in reverse, so the first middleware sees the
+// outermost view of the operation. If a Before hook fails, the operation and any later
+// middlewares are skipped, and the After hooks of middlewares already entered get the error.
+func NewMiddlewareKeyring(kr Keyring, mw ...Middleware) Keyring {
+	return &middlewareKeyring{kr: kr, mw: mw}
+}
+
+func (k *middlewareKeyring) run(op string, key string, fn func() error) error {
+	for idx, m := range k.mw {
+		if err := m.Before(op, key); err != nil {
+			Debugf("Middleware aborted %s of %q: %v", op, key, err)
+			k.after(idx, op, key, err)
+			return err
+		}
+	}
+
+	err := fn()
+	k.after(len(k.mw), op, key, err)
+	return err
+}
+
+func (k *middlewareKeyring) after(entered int, op string, key string, err error) {
+	for idx := entered - 1; idx >= 0; idx-- {
+		k.mw[idx].After(op, key, err)
+	}
+}
+
+func (k *middlewareKeyring) Get(key string) (item Item, err error) {
+	err = k.run(OpGet, key, func() error {
+		item, err = k.kr.Get(key)
+		return err
+	})
+	return item, err
+}
+
+func (k *middlewareKeyring) GetMetadata(key string) (md Metadata, err error) {
+	err = k.run(OpGetMetadata, key, func() error {
+		md, err = k.kr.GetMetadata(key)
+		return err
+	})
+	return md, err
+}
+
+func (k *middlewareKeyring) Set(item Item) error {
+	return k.run(OpSet, item.Key, func() error {
+		return k.kr.Set(item)
+	})
+}
+
+func (k *middlewareKeyring) Remove(key string) error {
+	return k.run(OpRemove, key, func() error {
+		return k.kr.Remove(key)
+	})
+}
+
+func (k *middlewareKeyring) Keys() (keys []string, err error) {
+	err = k.run(OpKeys, "", func() error {
+		keys, err = k.kr.Keys()
+		return err
+	})
+	return keys, err
+}
@@ -0,0 +1,72 @@
+package keyring
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+type recordingMiddleware struct {
+	name      string
+	calls     *[]string
+	beforeErr error
+}
+
+func (m recordingMiddleware) Before(op string, key string) error {
+	*m.calls = append(*m.calls, fmt.Sprintf("%s.Before(%s, %s)", m.name, op, key))
+	return m.beforeErr
+}
+
+func (m recordingMiddleware) After(op string, key string, err error) {
+	*m.calls = append(*m.calls, fmt.Sprintf("%s.After(%s, %s, %v)", m.name, op, key, err))
+}
+
+func TestMiddlewareKeyringOrder(t *testing.T) {
+	var calls []string
+	k := NewMiddlewareKeyring(&ArrayKeyring{},
+		recordingMiddleware{name: "first", calls: &calls},
+		recordingMiddleware{name: "second", calls: &calls},
+	)
+
+	if err := k.Set(Item{Key: "llamas", Data: []byte("llamas are great")}); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"first.Before(Set, llamas)",
+		"second.Before(Set, llamas)",
+		"second.After(Set, llamas, <nil>)",
+		"first.After(Set, llamas, <nil>)",
+	}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Fatalf("Unexpected middleware calls: %v", calls)
+	}
+}
+
+func TestMiddlewareKeyringBeforeAborts(t *testing.T) {
+	var calls []string
+	errDenied := errors.New("no llamas allowed")
+	backing := &ArrayKeyring{}
+	k := NewMiddlewareKeyring(backing,
+		recordingMiddleware{name: "first", calls: &calls},
+		recordingMiddleware{name: "second", calls: &calls, beforeErr: errDenied},
+		recordingMiddleware{name: "third", calls: &calls},
+	)
+
+	if err := k.Set(Item{Key: "llamas"}); err != errDenied {
+		t.Fatalf("Expected Before error, got: %v", err)
+	}
+	if _, err := backing.Get("llamas"); err != ErrKeyNotFound {
+		t.Fatal("Expected the aborted Set not to reach the keyring")
+	}
+
+	expected := []string{
+		"first.Before(Set, llamas)",
+		"second.Before(Set, llamas)",
+		"first.After(Set, llamas, no llamas allowed)",
+	}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Fatalf("Unexpected middleware calls: %v", calls)
+	}
+}
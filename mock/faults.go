@@ -0,0 +1,79 @@
+package mock
+
+import (
+	"sync"
+
+	"github.com/99designs/keyring"
+)
+
+// FaultRule makes the CallNumber'th call to Operation fail with Error. Operation is one of
+// keyring.OpGet, OpGetMetadata, OpSet, OpRemove or OpKeys, and calls are numbered from 1.
+type FaultRule struct {
+	Operation  string
+	CallNumber int
+	Error      error
+}
+
+type faultInjector struct {
+	kr    keyring.Keyring
+	rules []FaultRule
+
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+// NewFaultInjector wraps kr so that the calls matched by rules return their error instead
+// of reaching kr, and all other calls are passed through. This makes it possible to test
+// how code recovers from transient failures without relying on timing.
+func NewFaultInjector(kr keyring.Keyring, rules []FaultRule) keyring.Keyring {
+	return &faultInjector{kr: kr, rules: rules, calls: map[string]int{}}
+}
+
+// fault counts a call to op and returns the error it should fail with, if any
+func (f *faultInjector) fault(op string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls[op]++
+	for _, r := range f.rules {
+		if r.Operation == op && r.CallNumber == f.calls[op] {
+			return r.Error
+		}
+	}
+	return nil
+}
+
+func (f *faultInjector) Get(key string) (keyring.Item, error) {
+	if err := f.fault(keyring.OpGet); err != nil {
+		return keyring.Item{}, err
+	}
+	return f.kr.Get(key)
+}
+
+func (f *faultInjector) GetMetadata(key string) (keyring.Metadata, error) {
+	if err := f.fault(keyring.OpGetMetadata); err != nil {
+		return keyring.Metadata{}, err
+	}
+	return f.kr.GetMetadata(key)
+}
+
+func (f *faultInjector) Set(item keyring.Item) error {
+	if err := f.fault(keyring.OpSet); err != nil {
+		return err
+	}
+	return f.kr.Set(item)
+}
+
+func (f *faultInjector) Remove(key string) error {
+	if err := f.fault(keyring.OpRemove); err != nil {
+		return err
+	}
+	return f.kr.Remove(key)
+}
+
+func (f *faultInjector) Keys() ([]string, error) {
+	if err := f.fault(keyring.OpKeys); err != nil {
+		return nil, err
+	}
+	return f.kr.Keys()
+}
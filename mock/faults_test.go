@@ -0,0 +1,29 @@
+package mock
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/99designs/keyring"
+)
+
+func TestFaultInjector(t *testing.T) {
+	errTransient := errors.New("connection reset")
+	kr := NewFaultInjector(keyring.NewArrayKeyring([]keyring.Item{{Key: "llamas", Data: []byte("llamas are great")}}),
+		[]FaultRule{{Operation: keyring.OpGet, CallNumber: 2, Error: errTransient}})
+
+	for i, expected := range []error{nil, errTransient, nil} {
+		item, err := kr.Get("llamas")
+		if err != expected {
+			t.Fatalf("Call %d: expected error %v, got %v", i+1, expected, err)
+		}
+		if err == nil && string(item.Data) != "llamas are great" {
+			t.Fatalf("Call %d: unexpected data %q", i+1, item.Data)
+		}
+	}
+
+	// Other operations are counted separately
+	if err := kr.Set(keyring.Item{Key: "alpacas"}); err != nil {
+		t.Fatal(err)
+	}
+}
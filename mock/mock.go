@@ -0,0 +1,201 @@
+// Package mock provides a Keyring that verifies the calls made to it against a set of
+// expectations, for use in tests of code that relies on the keyring package.
+package mock
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/99designs/keyring"
+)
+
+// ErrUnexpectedCall is returned by MockKeyring for calls that no expectation matched
+var ErrUnexpectedCall = errors.New("Unexpected call to mock keyring")
+
+// Call is an expected call to a MockKeyring along with the values it should return
+type Call struct {
+	mu   *sync.Mutex
+	op   string
+	arg  interface{}
+	item keyring.Item
+	md   keyring.Metadata
+	keys []string
+	err  error
+	done bool
+}
+
+func (c *Call) String() string {
+	if c.arg == nil {
+		return c.op + "()"
+	}
+	return fmt.Sprintf("%s(%#v)", c.op, c.arg)
+}
+
+// GetCall is an expected call to Get
+type GetCall struct{ *Call }
+
+// Returns sets the values returned when the expected Get call is made
+func (c GetCall) Returns(item keyring.Item, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.item, c.err = item, err
+}
+
+// GetMetadataCall is an expected call to GetMetadata
+type GetMetadataCall struct{ *Call }
+
+// Returns sets the values returned when the expected GetMetadata call is made
+func (c GetMetadataCall) Returns(md keyring.Metadata, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.md, c.err = md, err
+}
+
+// KeysCall is an expected call to Keys
+type KeysCall struct{ *Call }
+
+// Returns sets the values returned when the expected Keys call is made
+func (c KeysCall) Returns(keys []string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.keys, c.err = keys, err
+}
+
+// ErrCall is an expected call to Set or Remove
+type ErrCall struct{ *Call }
+
+// Returns sets the error returned when the expected call is made
+func (c ErrCall) Returns(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.err = err
+}
+
+// MockKeyring is a Keyring that only accepts calls that have been set up beforehand with
+// its Expect methods. Each expectation is satisfied by a single call, and calls without a
+// matching expectation are reported with t.Errorf. It is safe for concurrent use.
+type MockKeyring struct {
+	t     testing.TB
+	mu    sync.Mutex
+	calls []*Call
+}
+
+// NewMockKeyring returns a MockKeyring that reports failures to t
+func NewMockKeyring(t testing.TB) *MockKeyring {
+	return &MockKeyring{t: t}
+}
+
+// ExpectGet expects a call to Get with the given key
+func (m *MockKeyring) ExpectGet(key string) GetCall {
+	return GetCall{m.expect("Get", key)}
+}
+
+// ExpectGetMetadata expects a call to GetMetadata with the given key
+func (m *MockKeyring) ExpectGetMetadata(key string) GetMetadataCall {
+	return GetMetadataCall{m.expect("GetMetadata", key)}
+}
+
+// ExpectSet expects a call to Set with an item equal to item
+func (m *MockKeyring) ExpectSet(item keyring.Item) ErrCall {
+	return ErrCall{m.expect("Set", item)}
+}
+
+// ExpectRemove expects a call to Remove with the given key
+func (m *MockKeyring) ExpectRemove(key string) ErrCall {
+	return ErrCall{m.expect("Remove", key)}
+}
+
+// ExpectKeys expects a call to Keys
+func (m *MockKeyring) ExpectKeys() KeysCall {
+	return KeysCall{m.expect("Keys", nil)}
+}
+
+// AssertExpectations reports each expected call that hasn't been made with t.Errorf, and
+// returns whether all expectations were met
+func (m *MockKeyring) AssertExpectations() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ok := true
+	for _, c := range m.calls {
+		if !c.done {
+			m.t.Helper()
+			m.t.Errorf("Expected call to mock keyring was not made: %s", c)
+			ok = false
+		}
+	}
+	return ok
+}
+
+// Get returns the values set up for a matching ExpectGet call
+func (m *MockKeyring) Get(key string) (keyring.Item, error) {
+	c, err := m.called("Get", key)
+	if err != nil {
+		return keyring.Item{}, err
+	}
+	return c.item, c.err
+}
+
+// GetMetadata returns the values set up for a matching ExpectGetMetadata call
+func (m *MockKeyring) GetMetadata(key string) (keyring.Metadata, error) {
+	c, err := m.called("GetMetadata", key)
+	if err != nil {
+		return keyring.Metadata{}, err
+	}
+	return c.md, c.err
+}
+
+// Set returns the error set up for a matching ExpectSet call
+func (m *MockKeyring) Set(item keyring.Item) error {
+	c, err := m.called("Set", item)
+	if err != nil {
+		return err
+	}
+	return c.err
+}
+
+// Remove returns the error set up for a matching ExpectRemove call
+func (m *MockKeyring) Remove(key string) error {
+	c, err := m.called("Remove", key)
+	if err != nil {
+		return err
+	}
+	return c.err
+}
+
+// Keys returns the values set up for a matching ExpectKeys call
+func (m *MockKeyring) Keys() ([]string, error) {
+	c, err := m.called("Keys", nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.keys, c.err
+}
+
+func (m *MockKeyring) expect(op string, arg interface{}) *Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c := &Call{mu: &m.mu, op: op, arg: arg}
+	m.calls = append(m.calls, c)
+	return c
+}
+
+func (m *MockKeyring) called(op string, arg interface{}) (Call, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, c := range m.calls {
+		if !c.done && c.op == op && reflect.DeepEqual(c.arg, arg) {
+			c.done = true
+			return *c, nil
+		}
+	}
+
+	unexpected := Call{op: op, arg: arg}
+	m.t.Errorf("Unexpected call to mock keyring: %s", &unexpected)
+	return unexpected, ErrUnexpectedCall
+}
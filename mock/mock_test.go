@@ -0,0 +1,71 @@
+package mock
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/99designs/keyring"
+)
+
+type recordingT struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recordingT) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recordingT) Helper() {}
+
+func TestMockKeyringExpectedCalls(t *testing.T) {
+	m := NewMockKeyring(t)
+	item := keyring.Item{Key: "llamas", Data: []byte("llamas are great")}
+
+	m.ExpectSet(item).Returns(nil)
+	m.ExpectGet("llamas").Returns(item, nil)
+	m.ExpectRemove("llamas").Returns(nil)
+	m.ExpectGet("llamas").Returns(keyring.Item{}, keyring.ErrKeyNotFound)
+
+	if err := m.Set(item); err != nil {
+		t.Fatal(err)
+	}
+
+	foundItem, err := m.Get("llamas")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(foundItem.Data) != "llamas are great" {
+		t.Fatalf("Value returned was not the value expected: %q", foundItem.Data)
+	}
+
+	if err := m.Remove("llamas"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := m.Get("llamas"); err != keyring.ErrKeyNotFound {
+		t.Fatalf("Expected ErrKeyNotFound, got: %v", err)
+	}
+
+	m.AssertExpectations()
+}
+
+func TestMockKeyringUnexpectedCall(t *testing.T) {
+	rt := &recordingT{TB: t}
+	m := NewMockKeyring(rt)
+	m.ExpectGet("llamas").Returns(keyring.Item{}, nil)
+
+	if _, err := m.Get("alpacas"); err != ErrUnexpectedCall {
+		t.Fatalf("Expected ErrUnexpectedCall, got: %v", err)
+	}
+	if len(rt.errors) != 1 {
+		t.Fatalf("Expected unexpected call to be reported, got: %v", rt.errors)
+	}
+
+	if m.AssertExpectations() {
+		t.Fatal("Expected AssertExpectations to fail with an unmet expectation")
+	}
+	if len(rt.errors) != 2 {
+		t.Fatalf("Expected unmet expectation to be reported, got: %v", rt.errors)
+	}
+}
@@ -0,0 +1,144 @@
+// Package nagios checks the health of a keyring, reporting the result in the format
+// expected of Nagios (and Icinga) plugins.
+package nagios
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"time"
+
+	"github.com/99designs/keyring"
+)
+
+// Status is a Nagios service state
+type Status int
+
+// Service states, as defined by the Nagios plugin API
+const (
+	OK       Status = 0
+	Warning  Status = 1
+	Critical Status = 2
+	Unknown  Status = 3
+)
+
+func (s Status) String() string {
+	switch s {
+	case OK:
+		return "OK"
+	case Warning:
+		return "WARNING"
+	case Critical:
+		return "CRITICAL"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// Thresholds are the latencies above which a check is considered to be in a warning or
+// critical state. A zero threshold is never exceeded.
+type Thresholds struct {
+	Warning  time.Duration
+	Critical time.Duration
+}
+
+// DefaultThresholds are the Thresholds used by Check
+var DefaultThresholds = Thresholds{
+	Warning:  time.Second,
+	Critical: 5 * time.Second,
+}
+
+// NagiosResult is the outcome of a keyring health check
+type NagiosResult struct {
+	Status     Status
+	Message    string
+	Latency    time.Duration
+	Thresholds Thresholds
+}
+
+// ExitCode returns the code a Nagios plugin should exit with to report the result
+func (r NagiosResult) ExitCode() int {
+	return int(r.Status)
+}
+
+// String returns the result as a line of plugin output, including latency perfdata
+func (r NagiosResult) String() string {
+	return fmt.Sprintf("KEYRING %s - %s | latency=%.3fs;%s;%s;0",
+		r.Status, r.Message, r.Latency.Seconds(),
+		threshold(r.Thresholds.Warning), threshold(r.Thresholds.Critical))
+}
+
+func threshold(d time.Duration) string {
+	if d == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
+
+// Check writes, reads back and removes an item under testKey using DefaultThresholds
+func Check(kr keyring.Keyring, testKey string) NagiosResult {
+	return CheckWithThresholds(kr, testKey, DefaultThresholds)
+}
+
+// CheckWithThresholds writes, reads back and removes an item under testKey, timing the
+// round trip against th. If the keyring is read-only, returning keyring.ErrReadOnly or a
+// permission error, but can still be listed, the result is a warning rather than critical.
+func CheckWithThresholds(kr keyring.Keyring, testKey string, th Thresholds) NagiosResult {
+	result := NagiosResult{Thresholds: th}
+	if testKey == "" {
+		result.Status = Unknown
+		result.Message = "no test key given"
+		return result
+	}
+
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		result.Status = Unknown
+		result.Message = fmt.Sprintf("failed to generate test data: %v", err)
+		return result
+	}
+	data := []byte(hex.EncodeToString(nonce))
+
+	start := time.Now()
+
+	if err := kr.Set(keyring.Item{Key: testKey, Data: data, Label: "keyring health check"}); errors.Is(err, keyring.ErrReadOnly) || errors.Is(err, fs.ErrPermission) {
+		if _, keysErr := kr.Keys(); keysErr != nil {
+			return finish(&result, start, Critical, "keyring is unreachable: %v", keysErr)
+		}
+		return finish(&result, start, Warning, "keyring is readable but not writable: %v", err)
+	} else if err != nil {
+		return finish(&result, start, Critical, "failed to write test item: %v", err)
+	}
+
+	item, err := kr.Get(testKey)
+	if err != nil {
+		return finish(&result, start, Critical, "failed to read test item: %v", err)
+	}
+	if !bytes.Equal(item.Data, data) {
+		return finish(&result, start, Critical, "test item read back didn't match what was written")
+	}
+
+	if err = kr.Remove(testKey); err != nil {
+		return finish(&result, start, Critical, "failed to remove test item: %v", err)
+	}
+
+	latency := time.Since(start)
+	switch {
+	case th.Critical > 0 && latency > th.Critical:
+		return finish(&result, start, Critical, "write/read/remove took %s", latency)
+	case th.Warning > 0 && latency > th.Warning:
+		return finish(&result, start, Warning, "write/read/remove took %s", latency)
+	default:
+		return finish(&result, start, OK, "write/read/remove took %s", latency)
+	}
+}
+
+func finish(r *NagiosResult, start time.Time, s Status, format string, args ...interface{}) NagiosResult {
+	r.Status = s
+	r.Message = fmt.Sprintf(format, args...)
+	r.Latency = time.Since(start)
+	return *r
+}
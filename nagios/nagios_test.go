@@ -0,0 +1,87 @@
+package nagios
+
+import (
+	"errors"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/99designs/keyring"
+)
+
+type failingKeyring struct {
+	*keyring.ArrayKeyring
+	err error
+}
+
+func (k failingKeyring) Set(_ keyring.Item) error {
+	return k.err
+}
+
+func TestCheckOK(t *testing.T) {
+	kr := &keyring.ArrayKeyring{}
+
+	result := Check(kr, "nagios-check")
+	if result.Status != OK || result.ExitCode() != 0 {
+		t.Fatalf("Expected OK, got: %s", result)
+	}
+	if !strings.HasPrefix(result.String(), "KEYRING OK - ") {
+		t.Fatalf("Unexpected plugin output: %s", result)
+	}
+
+	if _, err := kr.Get("nagios-check"); err != keyring.ErrKeyNotFound {
+		t.Fatal("Expected test item to be removed")
+	}
+}
+
+func TestCheckReadOnlyIsWarning(t *testing.T) {
+	result := Check(failingKeyring{&keyring.ArrayKeyring{}, keyring.ErrReadOnly}, "nagios-check")
+	if result.Status != Warning || result.ExitCode() != 1 {
+		t.Fatalf("Expected WARNING, got: %s", result)
+	}
+}
+
+func TestCheckUnwritableDirectoryIsWarning(t *testing.T) {
+	if runtime.GOOS == "windows" || os.Geteuid() == 0 {
+		t.Skip("Directory permissions aren't enforced")
+	}
+
+	dir := t.TempDir()
+	kr, err := keyring.Open(keyring.Config{
+		AllowedBackends: []keyring.BackendType{keyring.FileBackend},
+		FileDir:         dir,
+		FilePasswordFunc: func(string) (string, error) {
+			return "no more secrets", nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Chmod(dir, 0500); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chmod(dir, 0700)
+
+	result := Check(kr, "nagios-check")
+	if result.Status != Warning || result.ExitCode() != 1 {
+		t.Fatalf("Expected WARNING, got: %s", result)
+	}
+}
+
+func TestCheckWriteFailureIsCritical(t *testing.T) {
+	result := Check(failingKeyring{&keyring.ArrayKeyring{}, errors.New("permission denied")}, "nagios-check")
+	if result.Status != Critical || result.ExitCode() != 2 {
+		t.Fatalf("Expected CRITICAL, got: %s", result)
+	}
+	if !strings.Contains(result.Message, "permission denied") {
+		t.Fatalf("Expected the write error in the message, got: %s", result)
+	}
+}
+
+func TestCheckWithoutKeyIsUnknown(t *testing.T) {
+	result := Check(&keyring.ArrayKeyring{}, "")
+	if result.Status != Unknown || result.ExitCode() != 3 {
+		t.Fatalf("Expected UNKNOWN, got: %s", result)
+	}
+}
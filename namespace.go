@@ -0,0 +1,160 @@
+package keyring
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DefaultNamespace is the namespace used by keyrings opened with Config.Namespaces for
+// items that don't set one
+const DefaultNamespace = "default"
+
+// validateNamespace rejects namespaces that would be ambiguous once joined with a key
+func validateNamespace(ns string) error {
+	if ns == "" || strings.Contains(ns, "/") {
+		return fmt.Errorf("Namespace %q must not be empty or contain a /", ns)
+	}
+	return nil
+}
+
+type namespaceKeyring struct {
+	kr        Keyring
+	namespace string
+}
+
+// newNamespaceKeyring wraps kr so that keys are stored as "<namespace>/<key>", and only
+// keys in namespace are listed
+func newNamespaceKeyring(kr Keyring, namespace string) *namespaceKeyring {
+	return &namespaceKeyring{kr: kr, namespace: namespace}
+}
+
+func (k *namespaceKeyring) Get(key string) (Item, error) {
+	item, err := k.kr.Get(k.namespace + "/" + key)
+	if err != nil {
+		return Item{}, err
+	}
+	item.Key = key
+	item.Namespace = k.namespace
+	return item, nil
+}
+
+func (k *namespaceKeyring) GetMetadata(key string) (Metadata, error) {
+	md, err := k.kr.GetMetadata(k.namespace + "/" + key)
+	if err != nil {
+		return Metadata{}, err
+	}
+	if md.Item != nil {
+		item := *md.Item
+		item.Key = key
+		item.Namespace = k.namespace
+		md.Item = &item
+	}
+	return md, nil
+}
+
+// Set stores item in its Namespace, or the keyring's namespace if it doesn't have one
+func (k *namespaceKeyring) Set(item Item) error {
+	if item.Namespace == "" {
+		item.Namespace = k.namespace
+	}
+	if err := validateNamespace(item.Namespace); err != nil {
+		return err
+	}
+	item.Key = item.Namespace + "/" + item.Key
+	return k.kr.Set(item)
+}
+
+func (k *namespaceKeyring) Remove(key string) error {
+	return k.kr.Remove(k.namespace + "/" + key)
+}
+
+func (k *namespaceKeyring) Keys() ([]string, error) {
+	return keysInNamespace(k.kr, k.namespace)
+}
+
+func keysInNamespace(kr Keyring, ns string) ([]string, error) {
+	keys, err := kr.Keys()
+	if err != nil {
+		return nil, err
+	}
+
+	var inNamespace = []string{}
+	for _, key := range keys {
+		if strings.HasPrefix(key, ns+"/") {
+			inNamespace = append(inNamespace, strings.TrimPrefix(key, ns+"/"))
+		}
+	}
+	return inNamespace, nil
+}
+
+// namespaced returns the keyring that kr stores namespaced keys in. For keyrings opened
+// with Config.Namespaces that's the keyring below the namespace, otherwise it's kr itself.
+func namespaced(kr Keyring) Keyring {
+	switch k := kr.(type) {
+	case *ClearOnExitKeyring:
+		return namespaced(k.Keyring)
+	case *sizeLimitKeyring:
+		return namespaced(k.Keyring)
+	case *schemaKeyring:
+		return namespaced(k.Keyring)
+	case *namespaceKeyring:
+		return k.kr
+	}
+	return kr
+}
+
+// inNamespace returns kr with its namespace replaced by ns, keeping the validation
+// wrapped around it by Config.SchemaValidators and Config.MaxItemDataSize
+func inNamespace(kr Keyring, ns string) Keyring {
+	switch k := kr.(type) {
+	case *ClearOnExitKeyring:
+		return inNamespace(k.Keyring, ns)
+	case *sizeLimitKeyring:
+		return newSizeLimitKeyring(inNamespace(k.Keyring, ns), k.maxItemDataSize)
+	case *schemaKeyring:
+		return newSchemaKeyring(inNamespace(k.Keyring, ns), k.validators)
+	case *namespaceKeyring:
+		return newNamespaceKeyring(k.kr, ns)
+	}
+	return newNamespaceKeyring(kr, ns)
+}
+
+// WithNamespace returns a view of kr whose keys are in namespace ns, as if it had been
+// opened with Config.Namespaces and ns in place of DefaultNamespace. Items set through
+// the view aren't removed by Config.ClearOnExit.
+func WithNamespace(kr Keyring, ns string) (Keyring, error) {
+	if err := validateNamespace(ns); err != nil {
+		return nil, err
+	}
+	return inNamespace(kr, ns), nil
+}
+
+// KeysInNamespace returns the keys of the items in namespace ns, without the namespace
+func KeysInNamespace(kr Keyring, ns string) ([]string, error) {
+	if err := validateNamespace(ns); err != nil {
+		return nil, err
+	}
+	return keysInNamespace(namespaced(kr), ns)
+}
+
+// ListNamespaces returns the distinct namespaces of the items on kr, sorted. Keys without
+// a namespace are ignored.
+func ListNamespaces(kr Keyring) ([]string, error) {
+	keys, err := namespaced(kr).Keys()
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	namespaces := []string{}
+	for _, key := range keys {
+		if idx := strings.Index(key, "/"); idx > 0 && !seen[key[:idx]] {
+			seen[key[:idx]] = true
+			namespaces = append(namespaces, key[:idx])
+		}
+	}
+	sort.Strings(namespaces)
+
+	return namespaces, nil
+}
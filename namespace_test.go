@@ -0,0 +1,136 @@
+package keyring
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestNamespaceKeyring(t *testing.T) {
+	backing := NewArrayKeyring([]Item{{Key: "alpacas", Data: []byte("no namespace")}})
+	k := wrap(backing, Config{Namespaces: true})
+
+	if err := k.Set(Item{Key: "llamas", Data: []byte("llamas are great")}); err != nil {
+		t.Fatal(err)
+	}
+	if err := k.Set(Item{Key: "llamas", Namespace: "farm", Data: []byte("farm llamas")}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := backing.Get("default/llamas"); err != nil {
+		t.Fatalf("Expected the key in the default namespace in the backend: %v", err)
+	}
+	if _, err := backing.Get("farm/llamas"); err != nil {
+		t.Fatalf("Expected the key in the farm namespace in the backend: %v", err)
+	}
+
+	item, err := k.Get("llamas")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.Key != "llamas" || item.Namespace != DefaultNamespace || string(item.Data) != "llamas are great" {
+		t.Fatalf("Unexpected item: %+v", item)
+	}
+
+	keys, err := k.Keys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(keys, []string{"llamas"}) {
+		t.Fatalf("Expected only keys in the default namespace, got: %v", keys)
+	}
+
+	if err = k.Remove("llamas"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = backing.Get("farm/llamas"); err != nil {
+		t.Fatalf("Expected the farm namespace to be untouched: %v", err)
+	}
+}
+
+func TestWithNamespace(t *testing.T) {
+	k := wrap(NewArrayKeyring(nil), Config{Namespaces: true, ClearOnExit: true})
+	if err := k.Set(Item{Key: "llamas", Namespace: "farm", Data: []byte("farm llamas")}); err != nil {
+		t.Fatal(err)
+	}
+
+	farm, err := WithNamespace(k, "farm")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	item, err := farm.Get("llamas")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.Namespace != "farm" || string(item.Data) != "farm llamas" {
+		t.Fatalf("Unexpected item: %+v", item)
+	}
+
+	if _, err = k.Get("llamas"); err != ErrKeyNotFound {
+		t.Fatalf("Expected ErrKeyNotFound in the default namespace, got: %v", err)
+	}
+}
+
+func TestKeysInNamespaceAndListNamespaces(t *testing.T) {
+	k := wrap(NewArrayKeyring([]Item{
+		{Key: "default/llamas"},
+		{Key: "farm/llamas"},
+		{Key: "farm/alpacas"},
+		{Key: "zoo/camels"},
+		{Key: "no-namespace"},
+	}), Config{Namespaces: true})
+
+	keys, err := KeysInNamespace(k, "farm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(keys)
+	if !reflect.DeepEqual(keys, []string{"alpacas", "llamas"}) {
+		t.Fatalf("Unexpected keys in farm: %v", keys)
+	}
+
+	namespaces, err := ListNamespaces(k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(namespaces, []string{"default", "farm", "zoo"}) {
+		t.Fatalf("Unexpected namespaces: %v", namespaces)
+	}
+}
+
+func TestNamespaceKeyringRejectsInvalidNamespace(t *testing.T) {
+	k := wrap(NewArrayKeyring(nil), Config{Namespaces: true})
+	if err := k.Set(Item{Key: "llamas", Namespace: "farm/barn"}); err == nil {
+		t.Fatal("Expected an error for a namespace containing a /")
+	}
+	if _, err := WithNamespace(k, ""); err == nil {
+		t.Fatal("Expected an error for an empty namespace")
+	}
+}
+
+func TestNamespaceKeyringValidation(t *testing.T) {
+	k := wrap(NewArrayKeyring(nil), Config{
+		Namespaces:       true,
+		SchemaValidators: map[string]JSONSchema{"db": dbCredentialSchema},
+		MaxItemDataSize:  64,
+	})
+
+	var ve *ValidationError
+	if err := k.Set(Item{Key: "db", Data: []byte(`{"username": "llama"}`)}); !errors.As(err, &ve) {
+		t.Fatalf("Expected a ValidationError, got: %v", err)
+	}
+	var tooLarge *ErrItemTooLarge
+	if err := k.Set(Item{Key: "llamas", Data: make([]byte, 65)}); !errors.As(err, &tooLarge) {
+		t.Fatalf("Expected ErrItemTooLarge, got: %v", err)
+	}
+
+	farm, err := WithNamespace(k, "farm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = farm.Set(Item{Key: "db", Data: []byte(`{"username": "llama"}`)}); !errors.As(err, &ve) {
+		t.Fatalf("Expected a ValidationError in another namespace, got: %v", err)
+	}
+}
@@ -0,0 +1,49 @@
+package keyring
+
+import (
+	"fmt"
+	"sync"
+)
+
+// openManyConcurrency is the number of keyrings OpenMany opens at once
+const openManyConcurrency = 10
+
+// OpenResult is the outcome of opening one of the configurations passed to OpenMany
+type OpenResult struct {
+	Keyring Keyring
+	Error   error
+}
+
+// OpenMany opens a keyring for each of cfgs with Open, several at a time. Results are in
+// the same order as cfgs. If any failed, the error is a MultiError of their errors, and the
+// keyrings that did open are still returned.
+func OpenMany(cfgs []Config) ([]OpenResult, error) {
+	results := make([]OpenResult, len(cfgs))
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, openManyConcurrency)
+	for idx := range cfgs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(idx int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			kr, err := Open(cfgs[idx])
+			results[idx] = OpenResult{Keyring: kr, Error: err}
+		}(idx)
+	}
+	wg.Wait()
+
+	var errs []error
+	for idx, r := range results {
+		if r.Error != nil {
+			errs = append(errs, fmt.Errorf("config %d (%s): %w", idx, cfgs[idx].ServiceName, r.Error))
+		}
+	}
+	if len(errs) > 0 {
+		return results, &MultiError{Errs: errs}
+	}
+	return results, nil
+}
@@ -0,0 +1,31 @@
+package keyring
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestOpenMany(t *testing.T) {
+	dir := t.TempDir()
+	passwordFunc := fixedStringPrompt("llamas")
+
+	results, err := OpenMany([]Config{
+		{ServiceName: "llamas", AllowedBackends: []BackendType{FileBackend}, FileDir: dir, FilePasswordFunc: passwordFunc},
+		{ServiceName: "alpacas", AllowedBackends: []BackendType{InvalidBackend}},
+		{ServiceName: "vicunas", AllowedBackends: []BackendType{FileBackend}, FileDir: dir, FilePasswordFunc: passwordFunc},
+	})
+
+	var multi *MultiError
+	if !errors.As(err, &multi) || len(multi.Errs) != 1 {
+		t.Fatalf("Expected a MultiError with 1 error, got: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(results))
+	}
+	if results[0].Keyring == nil || results[2].Keyring == nil {
+		t.Fatalf("Expected the file keyrings to open: %+v", results)
+	}
+	if results[1].Error != ErrNoAvailImpl {
+		t.Fatalf("Expected ErrNoAvailImpl for the second config, got: %v", results[1].Error)
+	}
+}
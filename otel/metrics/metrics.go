@@ -0,0 +1,143 @@
+// Package metrics records OpenTelemetry metrics for the operations on a keyring, so that
+// their rate, latency and error ratio can be aggregated on dashboards.
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/99designs/keyring"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/aggregation"
+)
+
+const instrumentationName = "github.com/99designs/keyring/otel/metrics"
+
+// DataBytesBoundaries are the keyring.item.data_bytes bucket boundaries used by View. Most
+// secrets are passwords and tokens under 256 bytes, with certificates and service account
+// files in the kilobytes, so the buckets are finer at the low end than the SDK's defaults.
+var DataBytesBoundaries = []float64{16, 32, 64, 128, 256, 512, 1024, 2048, 4096, 8192, 16384, 65536, 262144, 1048576}
+
+// View returns a view that aggregates keyring.item.data_bytes with DataBytesBoundaries.
+// Register it with sdkmetric.WithView when creating the MeterProvider.
+func View() sdkmetric.View {
+	return sdkmetric.NewView(
+		sdkmetric.Instrument{Name: "keyring.item.data_bytes"},
+		sdkmetric.Stream{Aggregation: aggregation.ExplicitBucketHistogram{Boundaries: DataBytesBoundaries}},
+	)
+}
+
+type meterKeyring struct {
+	kr      keyring.Keyring
+	backend attribute.KeyValue
+
+	duration  metric.Float64Histogram
+	count     metric.Int64Counter
+	dataBytes metric.Int64Histogram
+}
+
+// NewMeterKeyring wraps kr so that each operation is recorded with meters from mp. The
+// instruments are:
+//
+//	keyring.operation.duration  histogram of how long operations took, in seconds
+//	keyring.operation.count     counter of operations
+//	keyring.item.data_bytes     histogram of the size of item data read or written
+//
+// Measurements are attributed with keyring.operation, keyring.backend (set to backend) and
+// keyring.result, which is "ok" or "error".
+func NewMeterKeyring(kr keyring.Keyring, mp metric.MeterProvider, backend keyring.BackendType) (keyring.Keyring, error) {
+	meter := mp.Meter(instrumentationName)
+
+	duration, err := meter.Float64Histogram("keyring.operation.duration",
+		metric.WithUnit("s"),
+		metric.WithDescription("Duration of keyring operations."))
+	if err != nil {
+		return nil, err
+	}
+	count, err := meter.Int64Counter("keyring.operation.count",
+		metric.WithUnit("{operation}"),
+		metric.WithDescription("Number of keyring operations."))
+	if err != nil {
+		return nil, err
+	}
+	dataBytes, err := meter.Int64Histogram("keyring.item.data_bytes",
+		metric.WithUnit("By"),
+		metric.WithDescription("Size of the data of items read from or written to the keyring."))
+	if err != nil {
+		return nil, err
+	}
+
+	return &meterKeyring{
+		kr:        kr,
+		backend:   attribute.String("keyring.backend", string(backend)),
+		duration:  duration,
+		count:     count,
+		dataBytes: dataBytes,
+	}, nil
+}
+
+// record measures fn as op, and the size of *data afterwards if the operation succeeded
+func (k *meterKeyring) record(op string, data *[]byte, fn func() error) {
+	start := time.Now()
+	err := fn()
+	elapsed := time.Since(start)
+
+	result := "ok"
+	if err != nil {
+		result = "error"
+	}
+	attrs := metric.WithAttributes(
+		attribute.String("keyring.operation", op),
+		k.backend,
+		attribute.String("keyring.result", result),
+	)
+
+	ctx := context.Background()
+	k.duration.Record(ctx, elapsed.Seconds(), attrs)
+	k.count.Add(ctx, 1, attrs)
+	if data != nil && err == nil {
+		k.dataBytes.Record(ctx, int64(len(*data)), attrs)
+	}
+}
+
+func (k *meterKeyring) Get(key string) (item keyring.Item, err error) {
+	k.record(keyring.OpGet, &item.Data, func() error {
+		item, err = k.kr.Get(key)
+		return err
+	})
+	return item, err
+}
+
+func (k *meterKeyring) GetMetadata(key string) (md keyring.Metadata, err error) {
+	k.record(keyring.OpGetMetadata, nil, func() error {
+		md, err = k.kr.GetMetadata(key)
+		return err
+	})
+	return md, err
+}
+
+func (k *meterKeyring) Set(item keyring.Item) (err error) {
+	k.record(keyring.OpSet, &item.Data, func() error {
+		err = k.kr.Set(item)
+		return err
+	})
+	return err
+}
+
+func (k *meterKeyring) Remove(key string) (err error) {
+	k.record(keyring.OpRemove, nil, func() error {
+		err = k.kr.Remove(key)
+		return err
+	})
+	return err
+}
+
+func (k *meterKeyring) Keys() (keys []string, err error) {
+	k.record(keyring.OpKeys, nil, func() error {
+		keys, err = k.kr.Keys()
+		return err
+	})
+	return keys, err
+}
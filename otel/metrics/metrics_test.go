@@ -0,0 +1,105 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+
+	"github.com/99designs/keyring"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestMeterKeyring(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	kr, err := NewMeterKeyring(&keyring.ArrayKeyring{}, mp, keyring.FileBackend)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = kr.Set(keyring.Item{Key: "llamas", Data: []byte("llamas are great")}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = kr.Get("llamas"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = kr.Get("alpacas"); err != keyring.ErrKeyNotFound {
+		t.Fatalf("Expected ErrKeyNotFound, got: %v", err)
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err = reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+
+	counts := map[string]int64{}
+	var dataBytes int64
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			switch data := m.Data.(type) {
+			case metricdata.Sum[int64]:
+				for _, dp := range data.DataPoints {
+					op, _ := dp.Attributes.Value(attribute.Key("keyring.operation"))
+					result, _ := dp.Attributes.Value(attribute.Key("keyring.result"))
+					backend, _ := dp.Attributes.Value(attribute.Key("keyring.backend"))
+					if backend.AsString() != "file" {
+						t.Fatalf("Unexpected backend attribute: %q", backend.AsString())
+					}
+					counts[op.AsString()+"/"+result.AsString()] += dp.Value
+				}
+			case metricdata.Histogram[int64]:
+				for _, dp := range data.DataPoints {
+					dataBytes += dp.Sum
+				}
+			}
+		}
+	}
+
+	expected := map[string]int64{"Set/ok": 1, "Get/ok": 1, "Get/error": 1}
+	for k, v := range expected {
+		if counts[k] != v {
+			t.Fatalf("Expected %d %s operations, got counts %v", v, k, counts)
+		}
+	}
+	if dataBytes != 32 {
+		t.Fatalf("Expected 32 bytes of item data, got %d", dataBytes)
+	}
+}
+
+func TestView(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader), sdkmetric.WithView(View()))
+
+	kr, err := NewMeterKeyring(&keyring.ArrayKeyring{}, mp, keyring.FileBackend)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = kr.Set(keyring.Item{Key: "llamas", Data: []byte("llamas are great")}); err != nil {
+		t.Fatal(err)
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err = reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != "keyring.item.data_bytes" {
+				continue
+			}
+			dp := m.Data.(metricdata.Histogram[int64]).DataPoints[0]
+			if len(dp.Bounds) != len(DataBytesBoundaries) {
+				t.Fatalf("Expected bounds %v, got %v", DataBytesBoundaries, dp.Bounds)
+			}
+			// 16 bytes falls in the first bucket, (-inf, 16]
+			if dp.BucketCounts[0] != 1 {
+				t.Fatalf("Expected the item in the first bucket, got counts %v", dp.BucketCounts)
+			}
+			return
+		}
+	}
+	t.Fatal("No keyring.item.data_bytes histogram was recorded")
+}
@@ -0,0 +1,162 @@
+// Package pagerduty pages the on-call team through PagerDuty when operations on a keyring
+// keep failing.
+package pagerduty
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/99designs/keyring"
+)
+
+// DefaultEventsURL is the PagerDuty Events API v2 endpoint
+const DefaultEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+// PagerDutyConfig configures when and where alerts are sent
+type PagerDutyConfig struct {
+	// RoutingKey is the integration key of the PagerDuty service to alert
+	RoutingKey string
+	// ErrorThreshold is the number of consecutive failures of an operation that triggers an
+	// alert, defaulting to 1
+	ErrorThreshold int
+	// Backend names the keyring's backend in alerts
+	Backend keyring.BackendType
+	// EventsURL is the Events API endpoint, defaulting to DefaultEventsURL
+	EventsURL string
+	// Client is the HTTP client used to send alerts, defaulting to one that gives up after
+	// Timeout
+	Client *http.Client
+	// Timeout bounds each alert when Client isn't set, so that a slow PagerDuty doesn't
+	// block keyring operations. Zero means 10 seconds.
+	Timeout time.Duration
+}
+
+type alertingKeyring struct {
+	kr  keyring.Keyring
+	cfg PagerDutyConfig
+
+	mu     sync.Mutex
+	errors map[string]int
+}
+
+// NewAlertingKeyring wraps kr so that an alert is sent to PagerDuty when an operation fails
+// cfg.ErrorThreshold times in a row. The count for an operation is reset when it next
+// succeeds, and alerts for the same backend and operation share a dedup key, so a burst of
+// failures raises a single incident. ErrKeyNotFound isn't counted as a failure.
+func NewAlertingKeyring(kr keyring.Keyring, cfg PagerDutyConfig) keyring.Keyring {
+	if cfg.ErrorThreshold <= 0 {
+		cfg.ErrorThreshold = 1
+	}
+	if cfg.EventsURL == "" {
+		cfg.EventsURL = DefaultEventsURL
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{Timeout: cfg.Timeout}
+	}
+	return &alertingKeyring{kr: kr, cfg: cfg, errors: map[string]int{}}
+}
+
+func (k *alertingKeyring) observe(op string, err error) {
+	if errors.Is(err, keyring.ErrKeyNotFound) {
+		err = nil
+	}
+
+	k.mu.Lock()
+	if err == nil {
+		delete(k.errors, op)
+		k.mu.Unlock()
+		return
+	}
+	k.errors[op]++
+	count := k.errors[op]
+	k.mu.Unlock()
+
+	if count == k.cfg.ErrorThreshold {
+		if alertErr := k.alert(op, err); alertErr != nil {
+			keyring.Debugf("Failed to send PagerDuty alert for %s: %v", op, alertErr)
+		}
+	}
+}
+
+// DedupKey returns the dedup key used for alerts about op on backend
+func DedupKey(backend keyring.BackendType, op string) string {
+	sum := sha256.Sum256([]byte(string(backend) + op))
+	return hex.EncodeToString(sum[:])
+}
+
+func (k *alertingKeyring) alert(op string, err error) error {
+	body, jsonErr := json.Marshal(map[string]interface{}{
+		"routing_key":  k.cfg.RoutingKey,
+		"event_action": "trigger",
+		"dedup_key":    DedupKey(k.cfg.Backend, op),
+		"payload": map[string]interface{}{
+			"summary":  fmt.Sprintf("Keyring %s failed %d times in a row: %v", op, k.cfg.ErrorThreshold, err),
+			"source":   "keyring",
+			"severity": "error",
+			"custom_details": map[string]string{
+				"keyring.backend":   string(k.cfg.Backend),
+				"keyring.operation": op,
+				"keyring.error":     err.Error(),
+			},
+		},
+	})
+	if jsonErr != nil {
+		return jsonErr
+	}
+
+	resp, postErr := k.cfg.Client.Post(k.cfg.EventsURL, "application/json", bytes.NewReader(body))
+	if postErr != nil {
+		return postErr
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("PagerDuty returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	keyring.Debugf("Sent PagerDuty alert for %s", op)
+	return nil
+}
+
+func (k *alertingKeyring) Get(key string) (keyring.Item, error) {
+	item, err := k.kr.Get(key)
+	k.observe(keyring.OpGet, err)
+	return item, err
+}
+
+func (k *alertingKeyring) GetMetadata(key string) (keyring.Metadata, error) {
+	md, err := k.kr.GetMetadata(key)
+	k.observe(keyring.OpGetMetadata, err)
+	return md, err
+}
+
+func (k *alertingKeyring) Set(item keyring.Item) error {
+	err := k.kr.Set(item)
+	k.observe(keyring.OpSet, err)
+	return err
+}
+
+func (k *alertingKeyring) Remove(key string) error {
+	err := k.kr.Remove(key)
+	k.observe(keyring.OpRemove, err)
+	return err
+}
+
+func (k *alertingKeyring) Keys() ([]string, error) {
+	keys, err := k.kr.Keys()
+	k.observe(keyring.OpKeys, err)
+	return keys, err
+}
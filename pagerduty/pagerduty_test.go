@@ -0,0 +1,119 @@
+package pagerduty
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/99designs/keyring"
+)
+
+type failingKeyring struct {
+	keyring.ArrayKeyring
+	err error
+}
+
+func (k *failingKeyring) Set(item keyring.Item) error {
+	if k.err != nil {
+		return k.err
+	}
+	return k.ArrayKeyring.Set(item)
+}
+
+type fakePagerDuty struct {
+	mu     sync.Mutex
+	events []map[string]interface{}
+}
+
+func (p *fakePagerDuty) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var event map[string]interface{}
+	_ = json.NewDecoder(r.Body).Decode(&event)
+
+	p.mu.Lock()
+	p.events = append(p.events, event)
+	p.mu.Unlock()
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func TestAlertingKeyring(t *testing.T) {
+	pd := &fakePagerDuty{}
+	srv := httptest.NewServer(pd)
+	defer srv.Close()
+
+	backing := &failingKeyring{err: errors.New("backend unavailable")}
+	kr := NewAlertingKeyring(backing, PagerDutyConfig{
+		RoutingKey:     "llamas",
+		ErrorThreshold: 2,
+		Backend:        keyring.FileBackend,
+		EventsURL:      srv.URL,
+	})
+
+	// A success in between resets the count
+	_ = kr.Set(keyring.Item{Key: "llamas"})
+	backing.err = nil
+	_ = kr.Set(keyring.Item{Key: "llamas"})
+	backing.err = errors.New("backend unavailable")
+	_ = kr.Set(keyring.Item{Key: "llamas"})
+	if len(pd.events) != 0 {
+		t.Fatalf("Expected no alerts before the threshold, got %d", len(pd.events))
+	}
+
+	for i := 0; i < 3; i++ {
+		_ = kr.Set(keyring.Item{Key: "llamas"})
+	}
+	if len(pd.events) != 1 {
+		t.Fatalf("Expected 1 alert, got %d", len(pd.events))
+	}
+
+	event := pd.events[0]
+	if event["routing_key"] != "llamas" || event["dedup_key"] != DedupKey(keyring.FileBackend, keyring.OpSet) {
+		t.Fatalf("Unexpected event: %v", event)
+	}
+	details := event["payload"].(map[string]interface{})["custom_details"].(map[string]interface{})
+	if details["keyring.backend"] != "file" || details["keyring.operation"] != "Set" || details["keyring.error"] != "backend unavailable" {
+		t.Fatalf("Unexpected details: %v", details)
+	}
+}
+
+func TestAlertingKeyringIgnoresKeyNotFound(t *testing.T) {
+	pd := &fakePagerDuty{}
+	srv := httptest.NewServer(pd)
+	defer srv.Close()
+
+	kr := NewAlertingKeyring(&keyring.ArrayKeyring{}, PagerDutyConfig{EventsURL: srv.URL})
+	if _, err := kr.Get("llamas"); err != keyring.ErrKeyNotFound {
+		t.Fatalf("Expected ErrKeyNotFound, got: %v", err)
+	}
+	if len(pd.events) != 0 {
+		t.Fatalf("Expected no alerts, got %d", len(pd.events))
+	}
+}
+
+func TestAlertingKeyringTimesOut(t *testing.T) {
+	done := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-done
+	}))
+	defer srv.Close()
+	defer close(done)
+
+	backing := &failingKeyring{err: errors.New("backend unavailable")}
+	kr := NewAlertingKeyring(backing, PagerDutyConfig{
+		RoutingKey: "llamas",
+		EventsURL:  srv.URL,
+		Timeout:    50 * time.Millisecond,
+	})
+
+	start := time.Now()
+	if err := kr.Set(keyring.Item{Key: "llamas"}); err == nil {
+		t.Fatal("Expected the backend's error")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("Expected the alert to time out, took %s", elapsed)
+	}
+}
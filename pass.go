@@ -58,18 +58,20 @@ func (k *passKeyring) Get(key string) (Item, error) {
 	name := filepath.Join(k.prefix, key)
 	cmd, err := k.pass("show", name)
 	if err != nil {
-		return Item{}, err
+		return Item{}, WrapError(err, PassBackend, k.prefix, key)
 	}
 
 	output, err := cmd.Output()
 	if err != nil {
-		return Item{}, err
+		return Item{}, WrapError(err, PassBackend, k.prefix, key)
 	}
 
 	var decoded Item
-	err = json.Unmarshal(output, &decoded)
+	if err = json.Unmarshal(output, &decoded); err != nil {
+		return Item{}, WrapError(err, PassBackend, k.prefix, key)
+	}
 
-	return decoded, err
+	return checkActive(decoded)
 }
 
 func (k *passKeyring) GetMetadata(key string) (Metadata, error) {
@@ -79,20 +81,20 @@ func (k *passKeyring) GetMetadata(key string) (Metadata, error) {
 func (k *passKeyring) Set(i Item) error {
 	bytes, err := json.Marshal(i)
 	if err != nil {
-		return err
+		return WrapError(err, PassBackend, k.prefix, i.Key)
 	}
 
 	name := filepath.Join(k.prefix, i.Key)
 	cmd, err := k.pass("insert", "-m", "-f", name)
 	if err != nil {
-		return err
+		return WrapError(err, PassBackend, k.prefix, i.Key)
 	}
 
 	cmd.Stdin = strings.NewReader(string(bytes))
 
 	err = cmd.Run()
 	if err != nil {
-		return err
+		return WrapError(err, PassBackend, k.prefix, i.Key)
 	}
 
 	return nil
@@ -106,12 +108,12 @@ func (k *passKeyring) Remove(key string) error {
 	name := filepath.Join(k.prefix, key)
 	cmd, err := k.pass("rm", "-f", name)
 	if err != nil {
-		return err
+		return WrapError(err, PassBackend, k.prefix, key)
 	}
 
 	err = cmd.Run()
 	if err != nil {
-		return err
+		return WrapError(err, PassBackend, k.prefix, key)
 	}
 
 	return nil
@@ -135,7 +137,7 @@ func (k *passKeyring) Keys() ([]string, error) {
 		if os.IsNotExist(err) {
 			return keys, nil
 		}
-		return keys, err
+		return keys, WrapError(err, PassBackend, k.prefix, "")
 	}
 	if !info.IsDir() {
 		return keys, fmt.Errorf("%s is not a directory", path)
@@ -156,5 +158,5 @@ func (k *passKeyring) Keys() ([]string, error) {
 		return nil
 	})
 
-	return keys, err
+	return keys, WrapError(err, PassBackend, k.prefix, "")
 }
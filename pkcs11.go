@@ -0,0 +1,279 @@
+//go:build cgo
+// +build cgo
+
+package keyring
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/miekg/pkcs11"
+)
+
+// pkcs11PoolSize is the number of idle sessions kept open for reuse
+const pkcs11PoolSize = 4
+
+func init() {
+	supportedBackends[PKCS11Backend] = opener(func(cfg Config) (Keyring, error) {
+		if cfg.PKCS11Module == "" {
+			return nil, errors.New("No PKCS#11 module provided")
+		}
+
+		ctx := pkcs11.New(cfg.PKCS11Module)
+		if ctx == nil {
+			return nil, fmt.Errorf("Failed to load PKCS#11 module %s", cfg.PKCS11Module)
+		}
+		if err := ctx.Initialize(); err != nil {
+			ctx.Destroy()
+			return nil, err
+		}
+
+		k := &pkcs11Keyring{
+			ctx:     ctx,
+			slot:    cfg.PKCS11SlotID,
+			service: cfg.ServiceName,
+			pool:    make(chan pkcs11.SessionHandle, pkcs11PoolSize),
+		}
+
+		if cfg.PKCS11TokenLabel != "" {
+			slot, err := k.findSlot(cfg.PKCS11TokenLabel)
+			if err != nil {
+				k.destroy()
+				return nil, err
+			}
+			k.slot = slot
+		}
+
+		session, err := k.session()
+		if err != nil {
+			k.destroy()
+			return nil, err
+		}
+
+		// Logging in applies to every session the application has open on the token
+		err = ctx.Login(session, pkcs11.CKU_USER, cfg.PKCS11PIN)
+		if err != nil && err != pkcs11.Error(pkcs11.CKR_USER_ALREADY_LOGGED_IN) {
+			_ = ctx.CloseSession(session)
+			k.destroy()
+			return nil, err
+		}
+		k.release(session)
+
+		return k, nil
+	})
+}
+
+// pkcs11Keyring stores items as CKO_DATA objects on a PKCS#11 token, with the key as the
+// object's CKA_LABEL and the service name as its CKA_APPLICATION
+type pkcs11Keyring struct {
+	ctx     *pkcs11.Ctx
+	slot    uint
+	service string
+	pool    chan pkcs11.SessionHandle
+}
+
+func (k *pkcs11Keyring) findSlot(label string) (uint, error) {
+	slots, err := k.ctx.GetSlotList(true)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, slot := range slots {
+		info, err := k.ctx.GetTokenInfo(slot)
+		if err != nil {
+			return 0, err
+		}
+		if strings.TrimRight(info.Label, " \x00") == label {
+			Debugf("Found PKCS#11 token %q in slot %d", label, slot)
+			return slot, nil
+		}
+	}
+
+	return 0, fmt.Errorf("No PKCS#11 token with label %q", label)
+}
+
+// destroy closes the pooled sessions and unloads the module, for when the keyring fails to
+// open after the module was initialized
+func (k *pkcs11Keyring) destroy() {
+	for {
+		select {
+		case s := <-k.pool:
+			_ = k.ctx.CloseSession(s)
+		default:
+			_ = k.ctx.Finalize()
+			k.ctx.Destroy()
+			return
+		}
+	}
+}
+
+// session takes an idle session from the pool, or opens a new one if there are none
+func (k *pkcs11Keyring) session() (pkcs11.SessionHandle, error) {
+	select {
+	case s := <-k.pool:
+		return s, nil
+	default:
+		return k.ctx.OpenSession(k.slot, pkcs11.CKF_SERIAL_SESSION|pkcs11.CKF_RW_SESSION)
+	}
+}
+
+// release returns a session to the pool, closing it if the pool is already full
+func (k *pkcs11Keyring) release(s pkcs11.SessionHandle) {
+	select {
+	case k.pool <- s:
+	default:
+		_ = k.ctx.CloseSession(s)
+	}
+}
+
+func (k *pkcs11Keyring) template(key string) []*pkcs11.Attribute {
+	t := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_DATA),
+		pkcs11.NewAttribute(pkcs11.CKA_APPLICATION, k.service),
+	}
+	if key != "" {
+		t = append(t, pkcs11.NewAttribute(pkcs11.CKA_LABEL, key))
+	}
+	return t
+}
+
+func (k *pkcs11Keyring) findObjects(s pkcs11.SessionHandle, key string) ([]pkcs11.ObjectHandle, error) {
+	if err := k.ctx.FindObjectsInit(s, k.template(key)); err != nil {
+		return nil, err
+	}
+
+	var handles []pkcs11.ObjectHandle
+	for {
+		batch, _, err := k.ctx.FindObjects(s, 100)
+		if err != nil {
+			_ = k.ctx.FindObjectsFinal(s)
+			return nil, err
+		}
+		if len(batch) == 0 {
+			break
+		}
+		handles = append(handles, batch...)
+	}
+
+	return handles, k.ctx.FindObjectsFinal(s)
+}
+
+func (k *pkcs11Keyring) Get(key string) (Item, error) {
+	s, err := k.session()
+	if err != nil {
+		return Item{}, WrapError(err, PKCS11Backend, k.service, key)
+	}
+	defer k.release(s)
+
+	handles, err := k.findObjects(s, key)
+	if err != nil {
+		return Item{}, WrapError(err, PKCS11Backend, k.service, key)
+	}
+	if len(handles) == 0 {
+		return Item{}, ErrKeyNotFound
+	}
+
+	attrs, err := k.ctx.GetAttributeValue(s, handles[0], []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_VALUE, nil),
+	})
+	if err != nil {
+		return Item{}, WrapError(err, PKCS11Backend, k.service, key)
+	}
+
+	return Item{
+		Key:  key,
+		Data: attrs[0].Value,
+	}, nil
+}
+
+// GetMetadata for pkcs11 returns an error indicating that it's unsupported
+// for this backend.
+//
+// Items are stored as private objects, which can't be seen without logging in.
+func (k *pkcs11Keyring) GetMetadata(_ string) (Metadata, error) {
+	return Metadata{}, ErrMetadataNeedsCredentials
+}
+
+func (k *pkcs11Keyring) Set(item Item) error {
+	s, err := k.session()
+	if err != nil {
+		return WrapError(err, PKCS11Backend, k.service, item.Key)
+	}
+	defer k.release(s)
+
+	existing, err := k.findObjects(s, item.Key)
+	if err != nil {
+		return WrapError(err, PKCS11Backend, k.service, item.Key)
+	}
+
+	template := append(k.template(item.Key),
+		pkcs11.NewAttribute(pkcs11.CKA_TOKEN, true),
+		pkcs11.NewAttribute(pkcs11.CKA_PRIVATE, true),
+		pkcs11.NewAttribute(pkcs11.CKA_VALUE, item.Data),
+	)
+
+	Debugf("Creating PKCS#11 data object application=%q, label=%q", k.service, item.Key)
+	if _, err = k.ctx.CreateObject(s, template); err != nil {
+		return WrapError(err, PKCS11Backend, k.service, item.Key)
+	}
+
+	// Only remove the previous value once the new one is safely stored
+	for _, h := range existing {
+		if err = k.ctx.DestroyObject(s, h); err != nil {
+			return WrapError(err, PKCS11Backend, k.service, item.Key)
+		}
+	}
+
+	return nil
+}
+
+func (k *pkcs11Keyring) Remove(key string) error {
+	s, err := k.session()
+	if err != nil {
+		return WrapError(err, PKCS11Backend, k.service, key)
+	}
+	defer k.release(s)
+
+	handles, err := k.findObjects(s, key)
+	if err != nil {
+		return WrapError(err, PKCS11Backend, k.service, key)
+	}
+	if len(handles) == 0 {
+		return ErrKeyNotFound
+	}
+
+	for _, h := range handles {
+		if err = k.ctx.DestroyObject(s, h); err != nil {
+			return WrapError(err, PKCS11Backend, k.service, key)
+		}
+	}
+
+	return nil
+}
+
+func (k *pkcs11Keyring) Keys() ([]string, error) {
+	s, err := k.session()
+	if err != nil {
+		return nil, WrapError(err, PKCS11Backend, k.service, "")
+	}
+	defer k.release(s)
+
+	handles, err := k.findObjects(s, "")
+	if err != nil {
+		return nil, WrapError(err, PKCS11Backend, k.service, "")
+	}
+
+	keys := []string{}
+	for _, h := range handles {
+		attrs, err := k.ctx.GetAttributeValue(s, h, []*pkcs11.Attribute{
+			pkcs11.NewAttribute(pkcs11.CKA_LABEL, nil),
+		})
+		if err != nil {
+			return nil, WrapError(err, PKCS11Backend, k.service, "")
+		}
+		keys = append(keys, string(attrs[0].Value))
+	}
+
+	return keys, nil
+}
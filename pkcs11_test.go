@@ -0,0 +1,83 @@
+//go:build cgo
+// +build cgo
+
+package keyring
+
+import (
+	"os"
+	"testing"
+)
+
+// These tests need a token to work against, e.g. one created with SoftHSM:
+//
+//	softhsm2-util --init-token --free --label keyring-test --pin 1234 --so-pin 1234
+//	PKCS11_TEST_MODULE=/usr/lib/softhsm/libsofthsm2.so go test -run PKCS11
+func openPKCS11(t *testing.T) Keyring {
+	module := os.Getenv("PKCS11_TEST_MODULE")
+	if module == "" {
+		t.Skip("PKCS11_TEST_MODULE not set")
+	}
+
+	k, err := supportedBackends[PKCS11Backend](Config{
+		ServiceName:      "keyring-test",
+		PKCS11Module:     module,
+		PKCS11TokenLabel: "keyring-test",
+		PKCS11PIN:        "1234",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
+
+func TestPKCS11KeyringSetGetRemove(t *testing.T) {
+	k := openPKCS11(t)
+	item := Item{Key: "llamas", Data: []byte("llamas are great")}
+
+	if err := k.Set(item); err != nil {
+		t.Fatal(err)
+	}
+
+	foundItem, err := k.Get("llamas")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(foundItem.Data) != "llamas are great" {
+		t.Fatalf("Value stored was not the value retrieved: %q", foundItem.Data)
+	}
+
+	keys, err := k.Keys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 1 || keys[0] != "llamas" {
+		t.Fatalf("Unexpected keys: %v", keys)
+	}
+
+	if err = k.Remove("llamas"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = k.Get("llamas"); err != ErrKeyNotFound {
+		t.Fatalf("Expected ErrKeyNotFound, got: %v", err)
+	}
+}
+
+func TestPKCS11KeyringWrongPIN(t *testing.T) {
+	module := os.Getenv("PKCS11_TEST_MODULE")
+	if module == "" {
+		t.Skip("PKCS11_TEST_MODULE not set")
+	}
+
+	// The module is unloaded when logging in fails, so opening it again must still work
+	for i := 0; i < 2; i++ {
+		_, err := supportedBackends[PKCS11Backend](Config{
+			PKCS11Module:     module,
+			PKCS11TokenLabel: "keyring-test",
+			PKCS11PIN:        "wrong",
+		})
+		if err == nil {
+			t.Fatal("Expected an error logging in with the wrong PIN")
+		}
+	}
+	openPKCS11(t)
+}
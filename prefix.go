@@ -0,0 +1,72 @@
+package keyring
+
+import (
+	"fmt"
+	"strings"
+)
+
+// validateKeyPrefix rejects prefixes ending in a path separator, which would lead to keys
+// with a doubled separator when joined with keys that start with one
+func validateKeyPrefix(prefix string) error {
+	if strings.HasSuffix(prefix, "/") || strings.HasSuffix(prefix, `\`) {
+		return fmt.Errorf("Key prefix %q must not end with a path separator", prefix)
+	}
+	return nil
+}
+
+type prefixKeyring struct {
+	kr     Keyring
+	prefix string
+}
+
+// newPrefixKeyring wraps kr so that prefix is prepended to every key stored in it, and
+// only keys with the prefix are listed
+func newPrefixKeyring(kr Keyring, prefix string) *prefixKeyring {
+	return &prefixKeyring{kr: kr, prefix: prefix}
+}
+
+func (k *prefixKeyring) Get(key string) (Item, error) {
+	item, err := k.kr.Get(k.prefix + key)
+	if err != nil {
+		return Item{}, err
+	}
+	item.Key = key
+	return item, nil
+}
+
+func (k *prefixKeyring) GetMetadata(key string) (Metadata, error) {
+	md, err := k.kr.GetMetadata(k.prefix + key)
+	if err != nil {
+		return Metadata{}, err
+	}
+	if md.Item != nil {
+		item := *md.Item
+		item.Key = key
+		md.Item = &item
+	}
+	return md, nil
+}
+
+func (k *prefixKeyring) Set(item Item) error {
+	item.Key = k.prefix + item.Key
+	return k.kr.Set(item)
+}
+
+func (k *prefixKeyring) Remove(key string) error {
+	return k.kr.Remove(k.prefix + key)
+}
+
+func (k *prefixKeyring) Keys() ([]string, error) {
+	keys, err := k.kr.Keys()
+	if err != nil {
+		return nil, err
+	}
+
+	var prefixed = []string{}
+	for _, key := range keys {
+		if strings.HasPrefix(key, k.prefix) {
+			prefixed = append(prefixed, strings.TrimPrefix(key, k.prefix))
+		}
+	}
+	return prefixed, nil
+}
@@ -0,0 +1,50 @@
+package keyring
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPrefixKeyring(t *testing.T) {
+	backing := NewArrayKeyring([]Item{{Key: "alpacas", Data: []byte("not ours")}})
+	k := wrap(backing, Config{KeyPrefix: "team-a"})
+
+	if err := k.Set(Item{Key: "llamas", Data: []byte("llamas are great")}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := backing.Get("team-allamas"); err != nil {
+		t.Fatalf("Expected the prefixed key in the backend: %v", err)
+	}
+
+	item, err := k.Get("llamas")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.Key != "llamas" || string(item.Data) != "llamas are great" {
+		t.Fatalf("Unexpected item: %+v", item)
+	}
+
+	keys, err := k.Keys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(keys, []string{"llamas"}) {
+		t.Fatalf("Expected only prefixed keys, got: %v", keys)
+	}
+
+	if err = k.Remove("llamas"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = backing.Get("team-allamas"); err != ErrKeyNotFound {
+		t.Fatalf("Expected ErrKeyNotFound, got: %v", err)
+	}
+}
+
+func TestOpenRejectsKeyPrefixEndingInSeparator(t *testing.T) {
+	for _, prefix := range []string{"team-a/", `team-a\`} {
+		if _, err := Open(Config{KeyPrefix: prefix, AllowedBackends: []BackendType{FileBackend}}); err == nil {
+			t.Fatalf("Expected Open to reject prefix %q", prefix)
+		}
+	}
+}
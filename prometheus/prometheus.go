@@ -0,0 +1,129 @@
+// Package prometheus exports metrics about the items stored on a keyring to Prometheus.
+package prometheus
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/99designs/keyring"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Exporter periodically refreshes the metrics for a keyring
+type Exporter struct {
+	kr keyring.Keyring
+
+	items  prometheus.Gauge
+	oldest prometheus.Gauge
+	newest prometheus.Gauge
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// Register registers item metrics for kr, labelled with service, and starts a goroutine that
+// refreshes them every interval until Stop is called. The metrics are:
+//
+//	keyring_items_total          the number of items on the keyring
+//	keyring_oldest_item_seconds  the age of the least recently modified item
+//	keyring_newest_item_seconds  the age of the most recently modified item
+//
+// Item ages come from keyring.IndexMetadata, so they are only reported by backends that
+// support reading metadata. If registering any of the metrics fails, those already
+// registered are unregistered again. interval must be positive.
+func Register(kr keyring.Keyring, service string, reg prometheus.Registerer, interval time.Duration) (*Exporter, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("Invalid refresh interval %s, it must be positive", interval)
+	}
+
+	labels := prometheus.Labels{"service": service}
+	e := &Exporter{
+		kr: kr,
+		items: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name:        "keyring_items_total",
+			Help:        "Number of items stored on the keyring.",
+			ConstLabels: labels,
+		}),
+		oldest: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name:        "keyring_oldest_item_seconds",
+			Help:        "Seconds since the least recently modified item on the keyring was modified.",
+			ConstLabels: labels,
+		}),
+		newest: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name:        "keyring_newest_item_seconds",
+			Help:        "Seconds since the most recently modified item on the keyring was modified.",
+			ConstLabels: labels,
+		}),
+		stop: make(chan struct{}),
+	}
+
+	collectors := []prometheus.Collector{e.items, e.oldest, e.newest}
+	for idx, c := range collectors {
+		if err := reg.Register(c); err != nil {
+			for _, registered := range collectors[:idx] {
+				reg.Unregister(registered)
+			}
+			return nil, err
+		}
+	}
+
+	e.refresh()
+
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				e.refresh()
+			case <-e.stop:
+				return
+			}
+		}
+	}()
+
+	return e, nil
+}
+
+// Stop stops refreshing the metrics. It's safe to call more than once.
+func (e *Exporter) Stop() {
+	e.stopOnce.Do(func() {
+		close(e.stop)
+	})
+	e.wg.Wait()
+}
+
+func (e *Exporter) refresh() {
+	index, err := keyring.IndexMetadata(e.kr)
+	if err != nil {
+		// Still count the items of backends that can't read their metadata
+		keys, err := e.kr.Keys()
+		if err == nil {
+			e.items.Set(float64(len(keys)))
+		}
+		return
+	}
+	e.items.Set(float64(len(index)))
+
+	var oldest, newest time.Time
+	for _, md := range index {
+		if md.ModificationTime.IsZero() {
+			continue
+		}
+		if oldest.IsZero() || md.ModificationTime.Before(oldest) {
+			oldest = md.ModificationTime
+		}
+		if md.ModificationTime.After(newest) {
+			newest = md.ModificationTime
+		}
+	}
+
+	if !oldest.IsZero() {
+		e.oldest.Set(time.Since(oldest).Seconds())
+		e.newest.Set(time.Since(newest).Seconds())
+	}
+}
@@ -0,0 +1,90 @@
+package prometheus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/99designs/keyring"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+type timestampedKeyring struct {
+	*keyring.ArrayKeyring
+	modified map[string]time.Time
+}
+
+func (k timestampedKeyring) GetMetadata(key string) (keyring.Metadata, error) {
+	return keyring.Metadata{ModificationTime: k.modified[key]}, nil
+}
+
+func TestRegister(t *testing.T) {
+	now := time.Now()
+	kr := timestampedKeyring{
+		ArrayKeyring: keyring.NewArrayKeyring([]keyring.Item{{Key: "llamas"}, {Key: "alpacas"}}),
+		modified: map[string]time.Time{
+			"llamas":  now.Add(-time.Hour),
+			"alpacas": now.Add(-time.Minute),
+		},
+	}
+
+	reg := prometheus.NewRegistry()
+	e, err := Register(kr, "llamas-service", reg, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer e.Stop()
+
+	if v := testutil.ToFloat64(e.items); v != 2 {
+		t.Fatalf("Expected 2 items, got %v", v)
+	}
+	if v := testutil.ToFloat64(e.oldest); v < 3600 || v > 3660 {
+		t.Fatalf("Expected oldest item to be an hour old, got %vs", v)
+	}
+	if v := testutil.ToFloat64(e.newest); v < 60 || v > 120 {
+		t.Fatalf("Expected newest item to be a minute old, got %vs", v)
+	}
+
+	if _, err = Register(kr, "llamas-service", reg, time.Hour); err == nil {
+		t.Fatal("Expected registering the same metrics twice to fail")
+	}
+}
+
+func TestRegisterUnregistersOnFailure(t *testing.T) {
+	kr := keyring.NewArrayKeyring(nil)
+	reg := prometheus.NewRegistry()
+
+	conflicting := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name:        "keyring_newest_item_seconds",
+		Help:        "Seconds since the most recently modified item on the keyring was modified.",
+		ConstLabels: prometheus.Labels{"service": "llamas-service"},
+	})
+	if err := reg.Register(conflicting); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Register(kr, "llamas-service", reg, time.Hour); err == nil {
+		t.Fatal("Expected registering a duplicate metric to fail")
+	}
+
+	reg.Unregister(conflicting)
+	e, err := Register(kr, "llamas-service", reg, time.Hour)
+	if err != nil {
+		t.Fatalf("Expected the metrics registered before the failure to have been unregistered: %v", err)
+	}
+	e.Stop()
+}
+
+func TestRegisterRejectsInvalidInterval(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	if _, err := Register(keyring.NewArrayKeyring(nil), "llamas-service", reg, 0); err == nil {
+		t.Fatal("Expected a zero interval to fail")
+	}
+
+	// Nothing was registered, so a valid interval still works
+	e, err := Register(keyring.NewArrayKeyring(nil), "llamas-service", reg, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.Stop()
+	e.Stop()
+}
@@ -1,6 +1,7 @@
 package keyring
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
@@ -10,6 +11,35 @@ import (
 // PromptFunc is a function used to prompt the user for a password
 type PromptFunc func(string) (string, error)
 
+// DefaultMaxPassphraseAttempts is how many times an empty passphrase is prompted for again
+// when Config.MaxPassphraseAttempts isn't set
+const DefaultMaxPassphraseAttempts = 3
+
+// ErrPassphraseRequired is returned when only empty passphrases are entered for a new
+// keychain, and Config.AllowEmptyPassphrase isn't set
+var ErrPassphraseRequired = errors.New("A passphrase is required to create the keychain")
+
+// promptPassphrase calls prompt until it returns a passphrase that isn't empty, up to
+// attempts times, returning ErrPassphraseRequired if it never does. Empty passphrases
+// are returned as is when allowEmpty is set.
+func promptPassphrase(prompt PromptFunc, message string, allowEmpty bool, attempts int) (string, error) {
+	if attempts <= 0 {
+		attempts = DefaultMaxPassphraseAttempts
+	}
+
+	for i := 0; i < attempts; i++ {
+		passphrase, err := prompt(message)
+		if err != nil {
+			return "", err
+		}
+		if passphrase != "" || allowEmpty {
+			return passphrase, nil
+		}
+		Debugf("Empty passphrase entered, attempt %d of %d", i+1, attempts)
+	}
+	return "", ErrPassphraseRequired
+}
+
 func terminalPrompt(prompt string) (string, error) {
 	fmt.Printf("%s: ", prompt)
 	b, err := terminal.ReadPassword(int(os.Stdin.Fd()))
@@ -0,0 +1,45 @@
+package keyring
+
+import "testing"
+
+func TestPromptPassphraseRetriesEmpty(t *testing.T) {
+	answers := []string{"", "", "llamas"}
+	calls := 0
+	prompt := func(string) (string, error) {
+		calls++
+		return answers[calls-1], nil
+	}
+
+	passphrase, err := promptPassphrase(prompt, "Passphrase", false, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if passphrase != "llamas" || calls != 3 {
+		t.Fatalf("Expected llamas after 3 prompts, got %q after %d", passphrase, calls)
+	}
+}
+
+func TestPromptPassphraseRequired(t *testing.T) {
+	calls := 0
+	prompt := func(string) (string, error) {
+		calls++
+		return "", nil
+	}
+
+	if _, err := promptPassphrase(prompt, "Passphrase", false, 2); err != ErrPassphraseRequired {
+		t.Fatalf("Expected ErrPassphraseRequired, got: %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("Expected 2 prompts, got %d", calls)
+	}
+}
+
+func TestPromptPassphraseAllowEmpty(t *testing.T) {
+	passphrase, err := promptPassphrase(fixedStringPrompt(""), "Passphrase", true, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if passphrase != "" {
+		t.Fatalf("Expected an empty passphrase, got %q", passphrase)
+	}
+}
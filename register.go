@@ -0,0 +1,30 @@
+package keyring
+
+import "fmt"
+
+// RegisterBackend makes a backend available to Open under name, so that backends can live
+// in separate packages. It is meant to be called from the init function of the backend's
+// package, which applications then import for its side effects:
+//
+//	import _ "github.com/acme/keyring-mybackend"
+//
+// Registered backends are tried by Open after the built-in ones, and are listed by
+// AvailableBackends. RegisterBackend panics if name is already registered or open is nil.
+// It must not be called concurrently with opening keyrings.
+func RegisterBackend(name BackendType, open func(Config) (Keyring, error)) {
+	if open == nil {
+		panic("keyring: RegisterBackend opener is nil")
+	}
+	if _, dup := supportedBackends[name]; dup {
+		panic(fmt.Sprintf("keyring: RegisterBackend called twice for backend %q", name))
+	}
+
+	supportedBackends[name] = opener(open)
+
+	for _, b := range backendOrder {
+		if b == name {
+			return
+		}
+	}
+	backendOrder = append(backendOrder, name)
+}
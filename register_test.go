@@ -0,0 +1,36 @@
+package keyring
+
+import "testing"
+
+func TestRegisterBackend(t *testing.T) {
+	const llamaBackend BackendType = "llama"
+	defer func() {
+		delete(supportedBackends, llamaBackend)
+		backendOrder = backendOrder[:len(backendOrder)-1]
+	}()
+
+	backing := NewArrayKeyring([]Item{{Key: "llamas", Data: []byte("llamas are great")}})
+	RegisterBackend(llamaBackend, func(cfg Config) (Keyring, error) {
+		return backing, nil
+	})
+
+	available := AvailableBackends()
+	if available[len(available)-1] != llamaBackend {
+		t.Fatalf("Expected the registered backend to be available last, got %v", available)
+	}
+
+	kr, err := Open(Config{AllowedBackends: []BackendType{llamaBackend}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = kr.Get("llamas"); err != nil {
+		t.Fatal(err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Expected registering the same backend twice to panic")
+		}
+	}()
+	RegisterBackend(llamaBackend, func(cfg Config) (Keyring, error) { return nil, nil })
+}
@@ -0,0 +1,32 @@
+package keyring
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/robfig/cron/v3"
+)
+
+// ErrInvalidRotationSchedule is returned by Set when an item's RotationSchedule isn't a
+// valid cron expression
+var ErrInvalidRotationSchedule = errors.New("Invalid rotation schedule")
+
+// ParseRotationSchedule parses a RotationSchedule, which is a standard five field cron
+// expression such as "0 0 * * 0", or a descriptor such as "@weekly"
+func ParseRotationSchedule(schedule string) (cron.Schedule, error) {
+	s, err := cron.ParseStandard(schedule)
+	if err != nil {
+		return nil, fmt.Errorf("%w %q: %v", ErrInvalidRotationSchedule, schedule, err)
+	}
+	return s, nil
+}
+
+// ValidateRotationSchedule returns ErrInvalidRotationSchedule if item has a RotationSchedule
+// that can't be parsed. Backends that store the whole item call it from Set.
+func ValidateRotationSchedule(item Item) error {
+	if item.RotationSchedule == "" {
+		return nil
+	}
+	_, err := ParseRotationSchedule(item.RotationSchedule)
+	return err
+}
@@ -0,0 +1,23 @@
+package keyring
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSetRejectsInvalidRotationSchedule(t *testing.T) {
+	kr := &ArrayKeyring{}
+
+	err := kr.Set(Item{Key: "llamas", RotationSchedule: "every tuesday"})
+	if !errors.Is(err, ErrInvalidRotationSchedule) {
+		t.Fatalf("Expected ErrInvalidRotationSchedule, got: %v", err)
+	}
+
+	if err = kr.Set(Item{Key: "llamas", RotationSchedule: "0 0 * * 0"}); err != nil {
+		t.Fatal(err)
+	}
+	item, _ := kr.Get("llamas")
+	if item.RotationSchedule != "0 0 * * 0" {
+		t.Fatalf("Expected the schedule to be kept, got %q", item.RotationSchedule)
+	}
+}
@@ -0,0 +1,69 @@
+// Package rotator works out when keyring items are due to be rotated from the cron
+// expression in their RotationSchedule, so that rotation tools don't need a separate
+// policy database.
+package rotator
+
+import (
+	"time"
+
+	"github.com/99designs/keyring"
+)
+
+// NextRotation returns when the item key is next due to be rotated: the first time its
+// RotationSchedule fires after the item was last modified. ok is false if the item has no
+// schedule, or its backend doesn't report when it was modified.
+//
+// The schedule is read with GetMetadata. Backends that don't return items from
+// GetMetadata, such as the file backend, only report the modification time, so the
+// schedule is read with Get instead.
+func NextRotation(kr keyring.Keyring, key string) (next time.Time, ok bool, err error) {
+	md, err := kr.GetMetadata(key)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	if md.ModificationTime.IsZero() {
+		keyring.Debugf("No modification time for %q", key)
+		return time.Time{}, false, nil
+	}
+
+	var schedule string
+	if md.Item != nil {
+		schedule = md.Item.RotationSchedule
+	} else {
+		item, err := kr.Get(key)
+		if err != nil {
+			return time.Time{}, false, err
+		}
+		schedule = item.RotationSchedule
+	}
+	if schedule == "" {
+		return time.Time{}, false, nil
+	}
+
+	s, err := keyring.ParseRotationSchedule(schedule)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	return s.Next(md.ModificationTime), true, nil
+}
+
+// Due returns the keys of items whose next rotation is at or before now
+func Due(kr keyring.Keyring, now time.Time) ([]string, error) {
+	keys, err := kr.Keys()
+	if err != nil {
+		return nil, err
+	}
+
+	var due = []string{}
+	for _, key := range keys {
+		next, ok, err := NextRotation(kr, key)
+		if err != nil {
+			return nil, err
+		}
+		if ok && !next.After(now) {
+			keyring.Debugf("%q was due to be rotated at %s", key, next)
+			due = append(due, key)
+		}
+	}
+	return due, nil
+}
@@ -0,0 +1,54 @@
+package rotator
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/99designs/keyring"
+)
+
+// metadataKeyring reports fixed modification times, and returns items from GetMetadata
+type metadataKeyring struct {
+	keyring.ArrayKeyring
+	modified map[string]time.Time
+}
+
+func (k *metadataKeyring) GetMetadata(key string) (keyring.Metadata, error) {
+	item, err := k.Get(key)
+	if err != nil {
+		return keyring.Metadata{}, err
+	}
+	item.Data = nil
+	return keyring.Metadata{Item: &item, ModificationTime: k.modified[key]}, nil
+}
+
+func TestDue(t *testing.T) {
+	// A Wednesday
+	now := time.Date(2024, 5, 15, 12, 0, 0, 0, time.Local)
+
+	kr := &metadataKeyring{modified: map[string]time.Time{
+		"llamas":   now.Add(-10 * 24 * time.Hour),
+		"alpacas":  now.Add(-24 * time.Hour),
+		"guanacos": now.Add(-365 * 24 * time.Hour),
+	}}
+	_ = kr.Set(keyring.Item{Key: "llamas", RotationSchedule: "0 0 * * 0"})
+	_ = kr.Set(keyring.Item{Key: "alpacas", RotationSchedule: "@weekly"})
+	_ = kr.Set(keyring.Item{Key: "guanacos"})
+
+	next, ok, err := NextRotation(kr, "alpacas")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok || next.Weekday() != time.Sunday || !next.After(now) {
+		t.Fatalf("Expected alpacas to be due next Sunday, got %s", next)
+	}
+
+	due, err := Due(kr, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(due, []string{"llamas"}) {
+		t.Fatalf("Expected only llamas to be due, got %v", due)
+	}
+}
@@ -0,0 +1,126 @@
+package keyring
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// JSONSchema is a JSON Schema document that item data must conform to
+type JSONSchema string
+
+// ValidationError is returned by Set when an item's data doesn't conform to the schema
+// configured for its key
+type ValidationError struct {
+	Key        string
+	Pattern    string
+	Violations []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("Item %q doesn't match the schema for %q: %s",
+		e.Key, e.Pattern, strings.Join(e.Violations, "; "))
+}
+
+type schemaKeyring struct {
+	Keyring
+	validators map[string]JSONSchema
+
+	mu       sync.Mutex
+	compiled map[string]*jsonschema.Schema
+}
+
+// newSchemaKeyring wraps kr so that Set validates item data against the schemas in
+// validators whose key glob (as understood by path.Match) matches the item's key
+func newSchemaKeyring(kr Keyring, validators map[string]JSONSchema) *schemaKeyring {
+	return &schemaKeyring{
+		Keyring:    kr,
+		validators: validators,
+		compiled:   map[string]*jsonschema.Schema{},
+	}
+}
+
+// schema returns the compiled schema for pattern, compiling it on first use
+func (k *schemaKeyring) schema(pattern string) (*jsonschema.Schema, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if s, ok := k.compiled[pattern]; ok {
+		return s, nil
+	}
+
+	url := "keyring://schema/" + pattern
+	c := jsonschema.NewCompiler()
+	if err := c.AddResource(url, strings.NewReader(string(k.validators[pattern]))); err != nil {
+		return nil, fmt.Errorf("Invalid schema for %q: %v", pattern, err)
+	}
+	s, err := c.Compile(url)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid schema for %q: %v", pattern, err)
+	}
+
+	k.compiled[pattern] = s
+	return s, nil
+}
+
+func (k *schemaKeyring) validate(item Item) error {
+	for pattern := range k.validators {
+		if ok, err := path.Match(pattern, item.Key); err != nil {
+			return fmt.Errorf("Invalid schema key pattern %q: %v", pattern, err)
+		} else if !ok {
+			continue
+		}
+
+		s, err := k.schema(pattern)
+		if err != nil {
+			return err
+		}
+
+		dec := json.NewDecoder(bytes.NewReader(item.Data))
+		dec.UseNumber()
+		var v interface{}
+		if err = dec.Decode(&v); err != nil {
+			return &ValidationError{Key: item.Key, Pattern: pattern, Violations: []string{
+				fmt.Sprintf("data is not valid JSON: %v", err),
+			}}
+		}
+
+		err = s.Validate(v)
+		if ve, ok := err.(*jsonschema.ValidationError); ok {
+			return &ValidationError{Key: item.Key, Pattern: pattern, Violations: violations(ve)}
+		} else if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// violations flattens a schema validation error into its individual failures
+func violations(ve *jsonschema.ValidationError) []string {
+	var v []string
+	for _, e := range ve.BasicOutput().Errors {
+		if e.Error == "" || strings.HasPrefix(e.Error, "doesn't validate with") {
+			continue
+		}
+		location := e.InstanceLocation
+		if location == "" {
+			location = "/"
+		}
+		v = append(v, fmt.Sprintf("%s: %s", location, e.Error))
+	}
+	return v
+}
+
+func (k *schemaKeyring) Set(item Item) error {
+	if err := k.validate(item); err != nil {
+		Debugf("Rejected %q: %v", item.Key, err)
+		return err
+	}
+	return k.Keyring.Set(item)
+}
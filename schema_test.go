@@ -0,0 +1,44 @@
+package keyring
+
+import (
+	"errors"
+	"testing"
+)
+
+const dbCredentialSchema = `{
+	"type": "object",
+	"required": ["username", "password", "host"],
+	"properties": {
+		"username": {"type": "string"},
+		"password": {"type": "string"},
+		"host": {"type": "string"}
+	}
+}`
+
+func TestSchemaKeyringValidatesMatchingKeys(t *testing.T) {
+	backing := &ArrayKeyring{}
+	k := newSchemaKeyring(backing, map[string]JSONSchema{
+		"db/*": dbCredentialSchema,
+	})
+
+	err := k.Set(Item{Key: "db/llamas", Data: []byte(`{"username": "llama", "password": 42}`)})
+	var ve *ValidationError
+	if !errors.As(err, &ve) {
+		t.Fatalf("Expected a ValidationError, got: %v", err)
+	}
+	if len(ve.Violations) != 2 {
+		t.Fatalf("Expected missing host and wrong password type to be reported, got: %v", ve.Violations)
+	}
+	if _, err = backing.Get("db/llamas"); err != ErrKeyNotFound {
+		t.Fatal("Expected invalid item not to be stored")
+	}
+
+	err = k.Set(Item{Key: "db/llamas", Data: []byte(`{"username": "llama", "password": "s3cret", "host": "db"}`)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = k.Set(Item{Key: "api-token", Data: []byte("not json")}); err != nil {
+		t.Fatalf("Expected keys not matching a pattern to skip validation, got: %v", err)
+	}
+}
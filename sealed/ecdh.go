@@ -0,0 +1,99 @@
+package sealed
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"io"
+
+	"github.com/99designs/keyring"
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/hkdf"
+	"golang.org/x/crypto/nacl/box"
+)
+
+// ecdhInfo binds keys derived for item files to this use of them
+const ecdhInfo = "keyring sealed item"
+
+// GenerateECDHKey returns a new X25519 key pair for Config.ECDHPublicKey and
+// Config.ECDHPrivateKey
+func GenerateECDHKey() (publicKey, privateKey *[32]byte, err error) {
+	return box.GenerateKey(rand.Reader)
+}
+
+// ecdhPublicKey returns the public key of private
+func ecdhPublicKey(private *[32]byte) (*[32]byte, error) {
+	b, err := curve25519.X25519(private[:], curve25519.Basepoint)
+	if err != nil {
+		return nil, err
+	}
+
+	var public [32]byte
+	copy(public[:], b)
+	return &public, nil
+}
+
+// ecdhAEAD returns the cipher for an item file, keyed with HKDF-SHA256 over the secret
+// shared by the ephemeral and recipient keys
+func ecdhAEAD(shared []byte, ephemeral, recipient *[32]byte) (cipher.AEAD, error) {
+	salt := append(append([]byte{}, ephemeral[:]...), recipient[:]...)
+
+	key := make([]byte, 32)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, shared, salt, []byte(ecdhInfo)), key); err != nil {
+		return nil, err
+	}
+	return newAEAD(key)
+}
+
+// sealECDH encrypts plaintext for recipient under a key agreed with a new ephemeral key
+// pair, whose public key is prepended to the result
+func sealECDH(recipient *[32]byte, name string, plaintext []byte) ([]byte, error) {
+	ephemeral, private, err := GenerateECDHKey()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		for i := range private {
+			private[i] = 0
+		}
+	}()
+
+	shared, err := curve25519.X25519(private[:], recipient[:])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := ecdhAEAD(shared, ephemeral, recipient)
+	if err != nil {
+		return nil, err
+	}
+	return sealWith(aead, ephemeral[:], name, plaintext)
+}
+
+// openECDH decrypts an item file written by sealECDH for public with its private key
+func openECDH(private, public *[32]byte, name string, sealed []byte) ([]byte, error) {
+	if len(sealed) < len(public) {
+		return nil, keyring.ErrCorrupted
+	}
+
+	var ephemeral [32]byte
+	copy(ephemeral[:], sealed)
+
+	shared, err := curve25519.X25519(private[:], ephemeral[:])
+	if err != nil {
+		return nil, keyring.ErrCorrupted
+	}
+	aead, err := ecdhAEAD(shared, &ephemeral, public)
+	if err != nil {
+		return nil, err
+	}
+	return openWith(aead, name, sealed[len(ephemeral):])
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
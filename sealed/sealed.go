@@ -0,0 +1,351 @@
+// Package sealed stores keyring items in a directory of files encrypted with a data key
+// that is itself encrypted by a remote key management service (KMS), known as envelope
+// encryption.
+//
+// The encrypted data key is kept in the directory alongside the items. When the backend
+// is created it's sent to the KMS to be decrypted, and the plaintext data key is held in
+// memory until Close. Any KMS can be used by implementing the KMS interface with its SDK,
+// such as AWS KMS, Google Cloud KMS or Azure Key Vault.
+//
+// Alternatively items can be encrypted to an X25519 public key with Config.ECDHPublicKey.
+// Each item is then encrypted under a key agreed between the recipient's public key and a
+// new ephemeral key pair, so a process that only stores items never needs a secret.
+package sealed
+
+import (
+	"context"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/99designs/keyring"
+)
+
+const (
+	// dataKeyFile is the file in the directory holding the data key encrypted by the KMS
+	dataKeyFile = "data-key.enc"
+
+	// itemFileExt is the extension of item files
+	itemFileExt = ".sealed"
+)
+
+// ErrClosed is returned by operations on a SealedBackend after Close
+var ErrClosed = errors.New("The sealed keyring has been closed")
+
+// ErrNoPrivateKey is returned when reading items from a SealedBackend that was created
+// with an ECDH public key but no private key
+var ErrNoPrivateKey = errors.New("The sealed keyring has no private key to decrypt items with")
+
+// KMS encrypts and decrypts data keys with a key held by a key management service
+type KMS interface {
+	Encrypt(ctx context.Context, plaintext []byte) ([]byte, error)
+	Decrypt(ctx context.Context, ciphertext []byte) ([]byte, error)
+}
+
+// Config configures a SealedBackend
+type Config struct {
+	// Dir is the directory that the encrypted data key and items are stored in
+	Dir string
+	// KMS decrypts the data key, and encrypts a new one if Dir doesn't have one yet
+	KMS KMS
+
+	// ECDHPublicKey is the X25519 key that items are encrypted to instead of using a KMS
+	ECDHPublicKey *[32]byte
+	// ECDHPrivateKey decrypts items encrypted to its public key. Without it the keyring
+	// can only store and remove items.
+	ECDHPrivateKey *[32]byte
+}
+
+// SealedBackend is a keyring of files encrypted with AES-256-GCM under a data key
+// protected by a KMS, or under keys agreed with an ECDH public key
+type SealedBackend struct {
+	dir string
+
+	mu         sync.RWMutex
+	closed     bool
+	dataKey    []byte
+	aead       cipher.AEAD
+	publicKey  *[32]byte
+	privateKey *[32]byte
+}
+
+// New returns a SealedBackend for cfg.Dir. The data key is decrypted by cfg.KMS, or
+// generated and encrypted by it if the directory doesn't have one yet. If an ECDH key is
+// configured instead, no data key is used.
+func New(ctx context.Context, cfg Config) (*SealedBackend, error) {
+	if cfg.Dir == "" {
+		return nil, errors.New("No directory provided for sealed keyring")
+	}
+
+	useECDH := cfg.ECDHPublicKey != nil || cfg.ECDHPrivateKey != nil
+	if cfg.KMS == nil && !useECDH {
+		return nil, errors.New("No KMS or ECDH key provided for sealed keyring")
+	} else if cfg.KMS != nil && useECDH {
+		return nil, errors.New("A sealed keyring can't use both a KMS and an ECDH key")
+	}
+	if err := os.MkdirAll(cfg.Dir, 0700); err != nil {
+		return nil, err
+	}
+
+	if useECDH {
+		return newECDH(cfg)
+	}
+
+	dataKey, err := loadDataKey(ctx, cfg.Dir, cfg.KMS)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SealedBackend{dir: cfg.Dir, dataKey: dataKey, aead: aead}, nil
+}
+
+// newECDH returns a SealedBackend for cfg's ECDH keys, deriving the public key from the
+// private key if it isn't set
+func newECDH(cfg Config) (*SealedBackend, error) {
+	b := &SealedBackend{dir: cfg.Dir, publicKey: cfg.ECDHPublicKey}
+	if cfg.ECDHPrivateKey == nil {
+		return b, nil
+	}
+
+	public, err := ecdhPublicKey(cfg.ECDHPrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	if b.publicKey != nil && *b.publicKey != *public {
+		return nil, errors.New("ECDH public key doesn't match the private key")
+	}
+
+	private := *cfg.ECDHPrivateKey
+	b.publicKey, b.privateKey = public, &private
+	return b, nil
+}
+
+// loadDataKey decrypts the data key in dir with kms, creating one if there isn't one
+func loadDataKey(ctx context.Context, dir string, kms KMS) ([]byte, error) {
+	path := filepath.Join(dir, dataKeyFile)
+
+	encrypted, err := ioutil.ReadFile(path)
+	if err == nil {
+		dataKey, err := kms.Decrypt(ctx, encrypted)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to decrypt data key with KMS: %v", err)
+		}
+		if len(dataKey) != 32 {
+			return nil, fmt.Errorf("Data key is %d bytes, expected 32", len(dataKey))
+		}
+		return dataKey, nil
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	dataKey := make([]byte, 32)
+	if _, err = rand.Read(dataKey); err != nil {
+		return nil, err
+	}
+	if encrypted, err = kms.Encrypt(ctx, dataKey); err != nil {
+		return nil, fmt.Errorf("Failed to encrypt data key with KMS: %v", err)
+	}
+
+	// O_EXCL so that two processes creating the keyring at once can't overwrite each other's key
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if os.IsExist(err) {
+		return loadDataKey(ctx, dir, kms)
+	} else if err != nil {
+		return nil, err
+	}
+	if _, err = f.Write(encrypted); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if err = f.Close(); err != nil {
+		return nil, err
+	}
+
+	keyring.Debugf("Created data key in %s", path)
+	return dataKey, nil
+}
+
+// filename is the name of the file that key is stored in. The key itself is only stored
+// encrypted.
+func filename(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:]) + itemFileExt
+}
+
+func (b *SealedBackend) seal(name string, item keyring.Item) ([]byte, error) {
+	plaintext, err := json.Marshal(item)
+	if err != nil {
+		return nil, err
+	}
+
+	if b.publicKey != nil {
+		return sealECDH(b.publicKey, name, plaintext)
+	}
+	return sealWith(b.aead, nil, name, plaintext)
+}
+
+// sealWith encrypts plaintext with aead, returning it after prefix and a random nonce
+func sealWith(aead cipher.AEAD, prefix []byte, name string, plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	// The filename is authenticated, so that item files can't be swapped
+	return aead.Seal(append(prefix, nonce...), nonce, plaintext, []byte(name)), nil
+}
+
+// openWith decrypts the nonce and ciphertext written by sealWith
+func openWith(aead cipher.AEAD, name string, sealed []byte) ([]byte, error) {
+	nonceSize := aead.NonceSize()
+	if len(sealed) < nonceSize {
+		return nil, keyring.ErrCorrupted
+	}
+	plaintext, err := aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], []byte(name))
+	if err != nil {
+		return nil, keyring.ErrCorrupted
+	}
+	return plaintext, nil
+}
+
+func (b *SealedBackend) open(name string) (keyring.Item, error) {
+	sealed, err := ioutil.ReadFile(filepath.Join(b.dir, name))
+	if os.IsNotExist(err) {
+		return keyring.Item{}, keyring.ErrKeyNotFound
+	} else if err != nil {
+		return keyring.Item{}, err
+	}
+
+	var plaintext []byte
+	if b.publicKey != nil {
+		plaintext, err = openECDH(b.privateKey, b.publicKey, name, sealed)
+	} else {
+		plaintext, err = openWith(b.aead, name, sealed)
+	}
+	if err != nil {
+		return keyring.Item{}, err
+	}
+
+	var item keyring.Item
+	if err = json.Unmarshal(plaintext, &item); err != nil {
+		return keyring.Item{}, err
+	}
+	return item, nil
+}
+
+func (b *SealedBackend) Get(key string) (keyring.Item, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.closed {
+		return keyring.Item{}, ErrClosed
+	}
+	if b.publicKey != nil && b.privateKey == nil {
+		return keyring.Item{}, ErrNoPrivateKey
+	}
+
+	return b.open(filename(key))
+}
+
+// GetMetadata returns only the modification time, as everything else is encrypted
+func (b *SealedBackend) GetMetadata(key string) (keyring.Metadata, error) {
+	stat, err := os.Stat(filepath.Join(b.dir, filename(key)))
+	if os.IsNotExist(err) {
+		return keyring.Metadata{}, keyring.ErrKeyNotFound
+	} else if err != nil {
+		return keyring.Metadata{}, err
+	}
+
+	return keyring.Metadata{ModificationTime: stat.ModTime()}, nil
+}
+
+func (b *SealedBackend) Set(item keyring.Item) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.closed {
+		return ErrClosed
+	}
+
+	if err := keyring.ValidateRotationSchedule(item); err != nil {
+		return err
+	}
+
+	name := filename(item.Key)
+	sealed, err := b.seal(name, item)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(b.dir, name), sealed, 0600)
+}
+
+func (b *SealedBackend) Remove(key string) error {
+	err := os.Remove(filepath.Join(b.dir, filename(key)))
+	if os.IsNotExist(err) {
+		return keyring.ErrKeyNotFound
+	}
+	return err
+}
+
+// Keys decrypts every item file to find its key
+func (b *SealedBackend) Keys() ([]string, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.closed {
+		return nil, ErrClosed
+	}
+	if b.publicKey != nil && b.privateKey == nil {
+		return nil, ErrNoPrivateKey
+	}
+
+	files, err := ioutil.ReadDir(b.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys = []string{}
+	for _, f := range files {
+		if !strings.HasSuffix(f.Name(), itemFileExt) {
+			continue
+		}
+		item, err := b.open(f.Name())
+		if err != nil {
+			keyring.Debugf("Skipping %s: %v", f.Name(), err)
+			continue
+		}
+		keys = append(keys, item.Key)
+	}
+	return keys, nil
+}
+
+// Close zero-fills the data key or ECDH private key held in memory, and drops the cipher
+// derived from the data key, whose key schedule can't be wiped. Later operations return
+// ErrClosed.
+func (b *SealedBackend) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for i := range b.dataKey {
+		b.dataKey[i] = 0
+	}
+	if b.privateKey != nil {
+		*b.privateKey = [32]byte{}
+	}
+	b.dataKey = nil
+	b.aead = nil
+	b.privateKey = nil
+	b.closed = true
+	return nil
+}
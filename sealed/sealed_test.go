@@ -0,0 +1,184 @@
+package sealed
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/99designs/keyring"
+)
+
+// fakeKMS "encrypts" by reversing the data, and counts the calls made to it
+type fakeKMS struct {
+	encrypts, decrypts int
+}
+
+func reverse(b []byte) []byte {
+	r := make([]byte, len(b))
+	for i := range b {
+		r[len(b)-1-i] = b[i]
+	}
+	return r
+}
+
+func (k *fakeKMS) Encrypt(_ context.Context, plaintext []byte) ([]byte, error) {
+	k.encrypts++
+	return reverse(plaintext), nil
+}
+
+func (k *fakeKMS) Decrypt(_ context.Context, ciphertext []byte) ([]byte, error) {
+	k.decrypts++
+	return reverse(ciphertext), nil
+}
+
+func TestSealedBackend(t *testing.T) {
+	dir := t.TempDir()
+	kms := &fakeKMS{}
+
+	b, err := New(context.Background(), Config{Dir: dir, KMS: kms})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kms.encrypts != 1 || kms.decrypts != 0 {
+		t.Fatalf("Expected a data key to be created, got %d encrypts and %d decrypts", kms.encrypts, kms.decrypts)
+	}
+
+	if err = b.Set(keyring.Item{Key: "llamas", Data: []byte("llamas are great")}); err != nil {
+		t.Fatal(err)
+	}
+
+	dataKey := b.dataKey
+	if err = b.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(dataKey, make([]byte, 32)) {
+		t.Fatal("Expected the data key to be zero-filled")
+	}
+	if _, err = b.Get("llamas"); err != ErrClosed {
+		t.Fatalf("Expected ErrClosed, got: %v", err)
+	}
+
+	b, err = New(context.Background(), Config{Dir: dir, KMS: kms})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kms.encrypts != 1 || kms.decrypts != 1 {
+		t.Fatalf("Expected the data key to be decrypted, got %d encrypts and %d decrypts", kms.encrypts, kms.decrypts)
+	}
+
+	item, err := b.Get("llamas")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(item.Data) != "llamas are great" {
+		t.Fatalf("Unexpected data %q", item.Data)
+	}
+
+	keys, err := b.Keys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 1 || keys[0] != "llamas" {
+		t.Fatalf("Unexpected keys: %v", keys)
+	}
+
+	if err = b.Remove("llamas"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = b.Get("llamas"); err != keyring.ErrKeyNotFound {
+		t.Fatalf("Expected ErrKeyNotFound, got: %v", err)
+	}
+}
+
+func TestSealedBackendKMSFailure(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := New(context.Background(), Config{Dir: dir, KMS: &fakeKMS{}}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := New(context.Background(), Config{Dir: dir, KMS: failingKMS{}})
+	if err == nil {
+		t.Fatal("Expected an error when the KMS can't decrypt the data key")
+	}
+}
+
+type failingKMS struct{}
+
+func (failingKMS) Encrypt(context.Context, []byte) ([]byte, error) {
+	return nil, errors.New("Access denied")
+}
+
+func (failingKMS) Decrypt(context.Context, []byte) ([]byte, error) {
+	return nil, errors.New("Access denied")
+}
+
+func TestSealedBackendECDH(t *testing.T) {
+	dir := t.TempDir()
+	public, private, err := GenerateECDHKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writer, err := New(context.Background(), Config{Dir: dir, ECDHPublicKey: public})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = writer.Set(keyring.Item{Key: "llamas", Data: []byte("llamas are great")}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = writer.Get("llamas"); err != ErrNoPrivateKey {
+		t.Fatalf("Expected ErrNoPrivateKey, got: %v", err)
+	}
+	if _, err = writer.Keys(); err != ErrNoPrivateKey {
+		t.Fatalf("Expected ErrNoPrivateKey, got: %v", err)
+	}
+
+	reader, err := New(context.Background(), Config{Dir: dir, ECDHPrivateKey: private})
+	if err != nil {
+		t.Fatal(err)
+	}
+	item, err := reader.Get("llamas")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(item.Data) != "llamas are great" {
+		t.Fatalf("Unexpected data %q", item.Data)
+	}
+	keys, err := reader.Keys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 1 || keys[0] != "llamas" {
+		t.Fatalf("Unexpected keys: %v", keys)
+	}
+
+	other, _, err := GenerateECDHKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = New(context.Background(), Config{Dir: dir, ECDHPublicKey: other, ECDHPrivateKey: private}); err == nil {
+		t.Fatal("Expected an error for a public key that doesn't match the private key")
+	}
+
+	wrong, err := New(context.Background(), Config{Dir: dir, ECDHPublicKey: other})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = wrong.Set(keyring.Item{Key: "alpacas", Data: []byte("alpacas are too")}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = reader.Get("alpacas"); err != keyring.ErrCorrupted {
+		t.Fatalf("Expected ErrCorrupted for an item encrypted to another key, got: %v", err)
+	}
+
+	if err = reader.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if *private == [32]byte{} {
+		t.Fatal("Expected the configured private key not to be zero-filled")
+	}
+	if _, err = reader.Get("llamas"); err != ErrClosed {
+		t.Fatalf("Expected ErrClosed, got: %v", err)
+	}
+}
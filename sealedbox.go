@@ -0,0 +1,271 @@
+package keyring
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/nacl/box"
+)
+
+// sealedBoxFileExt is the extension of item files written by the sealedbox backend
+const sealedBoxFileExt = ".box"
+
+func init() {
+	supportedBackends[SealedBoxBackend] = opener(func(cfg Config) (Keyring, error) {
+		if cfg.SealedBoxDir == "" {
+			return nil, errors.New("No directory provided for sealedbox keyring")
+		}
+		if cfg.SealedBoxPublicKey == "" && cfg.SealedBoxPrivateKey == "" {
+			return nil, errors.New("No sealedbox public key or private key file provided")
+		}
+
+		k := &sealedBoxKeyring{
+			dir:            cfg.SealedBoxDir,
+			service:        cfg.ServiceName,
+			privateKeyFile: cfg.SealedBoxPrivateKey,
+		}
+		if cfg.SealedBoxPublicKey != "" {
+			publicKey, err := parseSealedBoxKey(cfg.SealedBoxPublicKey)
+			if err != nil {
+				return nil, fmt.Errorf("Failed to parse sealedbox public key: %w", err)
+			}
+			k.publicKey = publicKey
+		}
+
+		return k, nil
+	})
+}
+
+// sealedBoxKeyring stores each item as a NaCl sealed box in its own file. Items can be
+// written with only the recipient's public key, so a directory of them can be prepared
+// elsewhere and carried to the machine holding the private key.
+type sealedBoxKeyring struct {
+	dir            string
+	service        string
+	publicKey      *[32]byte
+	privateKeyFile string
+
+	mu         sync.Mutex
+	privateKey *[32]byte
+}
+
+// parseSealedBoxKey decodes a base64 encoded Curve25519 key
+func parseSealedBoxKey(s string) (*[32]byte, error) {
+	b, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) != 32 {
+		return nil, fmt.Errorf("Expected a 32 byte key, got %d bytes", len(b))
+	}
+
+	var key [32]byte
+	copy(key[:], b)
+	return &key, nil
+}
+
+// GenerateSealedBoxKey returns a new base64 encoded Curve25519 key pair for the sealedbox
+// backend
+func GenerateSealedBoxKey() (publicKey, privateKey string, err error) {
+	public, private, err := box.GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", err
+	}
+	return base64.StdEncoding.EncodeToString(public[:]), base64.StdEncoding.EncodeToString(private[:]), nil
+}
+
+// keys reads the private key file on first use, deriving the public key from it if one
+// wasn't configured
+func (k *sealedBoxKeyring) keys() (publicKey, privateKey *[32]byte, err error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if k.privateKey != nil {
+		return k.publicKey, k.privateKey, nil
+	}
+	if k.privateKeyFile == "" {
+		return nil, nil, errors.New("No sealedbox private key file provided")
+	}
+
+	path, err := expandTilde(k.privateKeyFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	privateKey, err = parseSealedBoxKey(string(b))
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to parse sealedbox private key file %s: %w", path, err)
+	}
+
+	derived, err := curve25519.X25519(privateKey[:], curve25519.Basepoint)
+	if err != nil {
+		return nil, nil, err
+	}
+	if k.publicKey == nil {
+		k.publicKey = new([32]byte)
+		copy(k.publicKey[:], derived)
+	} else if !bytes.Equal(k.publicKey[:], derived) {
+		return nil, nil, fmt.Errorf("The sealedbox private key in %s doesn't match the public key", path)
+	}
+
+	k.privateKey = privateKey
+	return k.publicKey, k.privateKey, nil
+}
+
+func (k *sealedBoxKeyring) filename(key string) string {
+	sum := sha256.Sum256([]byte(k.service + "/" + key))
+	return hex.EncodeToString(sum[:]) + sealedBoxFileExt
+}
+
+func (k *sealedBoxKeyring) path(key string) (string, error) {
+	dir, err := ensureDir(k.dir)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, k.filename(key)), nil
+}
+
+func (k *sealedBoxKeyring) open(path string) (Item, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Item{}, err
+	}
+
+	publicKey, privateKey, err := k.keys()
+	if err != nil {
+		return Item{}, err
+	}
+
+	payload, ok := box.OpenAnonymous(nil, b, publicKey, privateKey)
+	if !ok {
+		return Item{}, ErrCorrupted
+	}
+
+	var item Item
+	err = json.Unmarshal(payload, &item)
+	return item, err
+}
+
+func (k *sealedBoxKeyring) Get(key string) (Item, error) {
+	path, err := k.path(key)
+	if err != nil {
+		return Item{}, WrapError(err, SealedBoxBackend, k.service, key)
+	}
+
+	item, err := k.open(path)
+	if os.IsNotExist(err) {
+		return Item{}, ErrKeyNotFound
+	} else if err != nil {
+		return Item{}, WrapError(err, SealedBoxBackend, k.service, key)
+	}
+	return checkActive(item)
+}
+
+// GetMetadata for sealedbox only returns the modification time, as everything else is encrypted
+func (k *sealedBoxKeyring) GetMetadata(key string) (Metadata, error) {
+	path, err := k.path(key)
+	if err != nil {
+		return Metadata{}, WrapError(err, SealedBoxBackend, k.service, key)
+	}
+
+	stat, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return Metadata{}, ErrKeyNotFound
+	} else if err != nil {
+		return Metadata{}, WrapError(err, SealedBoxBackend, k.service, key)
+	}
+
+	return Metadata{
+		ModificationTime: stat.ModTime(),
+	}, nil
+}
+
+func (k *sealedBoxKeyring) Set(item Item) error {
+	if err := ValidateRotationSchedule(item); err != nil {
+		return WrapError(err, SealedBoxBackend, k.service, item.Key)
+	}
+
+	publicKey := k.publicKey
+	if publicKey == nil {
+		var err error
+		if publicKey, _, err = k.keys(); err != nil {
+			return WrapError(err, SealedBoxBackend, k.service, item.Key)
+		}
+	}
+
+	path, err := k.path(item.Key)
+	if err != nil {
+		return WrapError(err, SealedBoxBackend, k.service, item.Key)
+	}
+
+	payload, err := json.Marshal(item)
+	if err != nil {
+		return WrapError(err, SealedBoxBackend, k.service, item.Key)
+	}
+
+	sealed, err := box.SealAnonymous(nil, payload, publicKey, rand.Reader)
+	if err != nil {
+		return WrapError(err, SealedBoxBackend, k.service, item.Key)
+	}
+
+	return WrapError(ioutil.WriteFile(path, sealed, 0600), SealedBoxBackend, k.service, item.Key)
+}
+
+func (k *sealedBoxKeyring) Remove(key string) error {
+	path, err := k.path(key)
+	if err != nil {
+		return WrapError(err, SealedBoxBackend, k.service, key)
+	}
+
+	err = os.Remove(path)
+	if os.IsNotExist(err) {
+		return ErrKeyNotFound
+	}
+	return WrapError(err, SealedBoxBackend, k.service, key)
+}
+
+// Keys opens every item in the directory, skipping those sealed to another key or whose
+// file name shows they belong to another service
+func (k *sealedBoxKeyring) Keys() ([]string, error) {
+	dir, err := ensureDir(k.dir)
+	if err != nil {
+		return nil, WrapError(err, SealedBoxBackend, k.service, "")
+	}
+
+	matches, err := filepath.Glob(filepath.Join(dir, "*"+sealedBoxFileExt))
+	if err != nil {
+		return nil, WrapError(err, SealedBoxBackend, k.service, "")
+	}
+
+	var keys = []string{}
+	for _, path := range matches {
+		item, err := k.open(path)
+		if err == ErrCorrupted {
+			Debugf("Skipping %s, it isn't sealed to our key", path)
+			continue
+		} else if err != nil {
+			return nil, WrapError(err, SealedBoxBackend, k.service, "")
+		}
+		if filepath.Base(path) != k.filename(item.Key) {
+			continue
+		}
+		keys = append(keys, item.Key)
+	}
+
+	return keys, nil
+}
@@ -0,0 +1,100 @@
+package keyring
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestSealedBoxKeyringSetGetRemove(t *testing.T) {
+	dir := t.TempDir()
+
+	publicKey, privateKey, err := GenerateSealedBoxKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	privateKeyFile := filepath.Join(dir, "private.key")
+	if err = ioutil.WriteFile(privateKeyFile, []byte(privateKey+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// Items are sealed with only the public key, e.g. on another machine
+	sealer, err := supportedBackends[SealedBoxBackend](Config{
+		ServiceName:        "llamas",
+		SealedBoxDir:       filepath.Join(dir, "items"),
+		SealedBoxPublicKey: publicKey,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = sealer.Set(Item{Key: "llama", Data: []byte("llamas are great")}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = sealer.Get("llama"); err == nil {
+		t.Fatal("Expected Get to fail without a private key")
+	}
+
+	k, err := supportedBackends[SealedBoxBackend](Config{
+		ServiceName:         "llamas",
+		SealedBoxDir:        filepath.Join(dir, "items"),
+		SealedBoxPrivateKey: privateKeyFile,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	item, err := k.Get("llama")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(item.Data) != "llamas are great" {
+		t.Fatalf("Value stored was not the value retrieved: %q", item.Data)
+	}
+
+	keys, err := k.Keys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 1 || keys[0] != "llama" {
+		t.Fatalf("Unexpected keys: %v", keys)
+	}
+
+	if err = k.Remove("llama"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = k.Get("llama"); err != ErrKeyNotFound {
+		t.Fatalf("Expected ErrKeyNotFound, got: %v", err)
+	}
+}
+
+func TestSealedBoxKeyringMismatchedKeys(t *testing.T) {
+	dir := t.TempDir()
+
+	publicKey, _, err := GenerateSealedBoxKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, privateKey, err := GenerateSealedBoxKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	privateKeyFile := filepath.Join(dir, "private.key")
+	if err = ioutil.WriteFile(privateKeyFile, []byte(privateKey), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	k, err := supportedBackends[SealedBoxBackend](Config{
+		SealedBoxDir:        dir,
+		SealedBoxPublicKey:  publicKey,
+		SealedBoxPrivateKey: privateKeyFile,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = k.Set(Item{Key: "llama", Data: []byte("llamas are great")}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = k.Get("llama"); err == nil {
+		t.Fatal("Expected Get to fail when the private key doesn't match the public key")
+	}
+}
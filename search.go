@@ -0,0 +1,65 @@
+package keyring
+
+import (
+	"sort"
+	"strings"
+)
+
+// SearchOptions controls how SearchKeys matches keys against a query. When more than one of
+// Prefix, Suffix and Contains is set, a key must satisfy all of them. When none are set,
+// Contains is assumed.
+type SearchOptions struct {
+	Prefix        bool
+	Suffix        bool
+	Contains      bool
+	CaseSensitive bool
+}
+
+// keySearcher is implemented by backends that can narrow down keys more efficiently than
+// listing them all. Results may include false positives, which SearchKeys filters out.
+type keySearcher interface {
+	searchKeys(query string, opts SearchOptions) ([]string, error)
+}
+
+// SearchKeys returns the keys on kr that match query, sorted and without duplicates
+func SearchKeys(kr Keyring, query string, opts SearchOptions) ([]string, error) {
+	var keys []string
+	var err error
+	if ks, ok := kr.(keySearcher); ok {
+		keys, err = ks.searchKeys(query, opts)
+	} else {
+		keys, err = kr.Keys()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	matches := []string{}
+	for _, key := range keys {
+		if !seen[key] && opts.match(key, query) {
+			seen[key] = true
+			matches = append(matches, key)
+		}
+	}
+	sort.Strings(matches)
+
+	return matches, nil
+}
+
+func (opts SearchOptions) match(key, query string) bool {
+	if !opts.CaseSensitive {
+		key, query = strings.ToLower(key), strings.ToLower(query)
+	}
+
+	if opts.Prefix && !strings.HasPrefix(key, query) {
+		return false
+	}
+	if opts.Suffix && !strings.HasSuffix(key, query) {
+		return false
+	}
+	if (opts.Contains || !(opts.Prefix || opts.Suffix)) && !strings.Contains(key, query) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,61 @@
+package keyring
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSearchKeys(t *testing.T) {
+	k := NewArrayKeyring([]Item{
+		{Key: "llamas/great"},
+		{Key: "llamas/grand"},
+		{Key: "Alpacas/great"},
+		{Key: "vicunas"},
+	})
+
+	for _, tc := range []struct {
+		query    string
+		opts     SearchOptions
+		expected []string
+	}{
+		{"llamas/", SearchOptions{Prefix: true}, []string{"llamas/grand", "llamas/great"}},
+		{"great", SearchOptions{Suffix: true}, []string{"Alpacas/great", "llamas/great"}},
+		{"ALPACAS", SearchOptions{}, []string{"Alpacas/great"}},
+		{"ALPACAS", SearchOptions{CaseSensitive: true}, []string{}},
+		{"llamas", SearchOptions{Prefix: true, Suffix: true}, []string{}},
+	} {
+		keys, err := SearchKeys(k, tc.query, tc.opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(keys, tc.expected) {
+			t.Errorf("SearchKeys(%q, %+v) = %v, expected %v", tc.query, tc.opts, keys, tc.expected)
+		}
+	}
+}
+
+func TestFileKeyringSearchKeys(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keyring-search-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, name := range []string{"llamas-great", "llamas-grand", "alpacas-great"} {
+		if err = ioutil.WriteFile(filepath.Join(dir, name), nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	k := &fileKeyring{dir: dir}
+	keys, err := SearchKeys(k, "llamas", SearchOptions{Prefix: true, CaseSensitive: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(keys, []string{"llamas-grand", "llamas-great"}) {
+		t.Fatalf("Unexpected keys: %v", keys)
+	}
+}
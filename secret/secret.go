@@ -0,0 +1,81 @@
+// Package secret gives typed access to items on a keyring, so that callers don't have to
+// marshal and unmarshal item data themselves.
+package secret
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/99designs/keyring"
+	"google.golang.org/protobuf/proto"
+)
+
+// Secret is a value of type T stored as the data of the item key on a keyring
+type Secret[T any] struct {
+	kr        keyring.Keyring
+	key       string
+	unmarshal func([]byte) (T, error)
+	marshal   func(T) ([]byte, error)
+}
+
+// New returns a Secret that encodes values with marshal and decodes them with unmarshal
+func New[T any](kr keyring.Keyring, key string, marshal func(T) ([]byte, error), unmarshal func([]byte) (T, error)) *Secret[T] {
+	return &Secret[T]{kr: kr, key: key, marshal: marshal, unmarshal: unmarshal}
+}
+
+// NewJSONSecret returns a Secret stored as JSON
+func NewJSONSecret[T any](kr keyring.Keyring, key string) *Secret[T] {
+	return New(kr, key,
+		func(val T) ([]byte, error) {
+			return json.Marshal(val)
+		},
+		func(b []byte) (T, error) {
+			var val T
+			err := json.Unmarshal(b, &val)
+			return val, err
+		})
+}
+
+// NewProtoSecret returns a Secret stored in the protocol buffers wire format. T must be a
+// pointer to a generated message type, such as *pb.Credentials.
+func NewProtoSecret[T proto.Message](kr keyring.Keyring, key string) *Secret[T] {
+	return New(kr, key,
+		func(val T) ([]byte, error) {
+			return proto.Marshal(val)
+		},
+		func(b []byte) (T, error) {
+			var zero T
+			val := zero.ProtoReflect().New().Interface().(T)
+			err := proto.Unmarshal(b, val)
+			return val, err
+		})
+}
+
+// Get reads and decodes the secret. Keyrings can't be interrupted, so ctx is only checked
+// before the item is read.
+func (s *Secret[T]) Get(ctx context.Context) (T, error) {
+	var zero T
+	if err := ctx.Err(); err != nil {
+		return zero, err
+	}
+
+	item, err := s.kr.Get(s.key)
+	if err != nil {
+		return zero, err
+	}
+	return s.unmarshal(item.Data)
+}
+
+// Set encodes val and stores it, replacing the data of any existing item. Keyrings can't be
+// interrupted, so ctx is only checked before the item is written.
+func (s *Secret[T]) Set(ctx context.Context, val T) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	data, err := s.marshal(val)
+	if err != nil {
+		return err
+	}
+	return s.kr.Set(keyring.Item{Key: s.key, Data: data})
+}
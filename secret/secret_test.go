@@ -0,0 +1,65 @@
+package secret
+
+import (
+	"context"
+	"testing"
+
+	"github.com/99designs/keyring"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+type credentials struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+func TestJSONSecret(t *testing.T) {
+	kr := &keyring.ArrayKeyring{}
+	s := NewJSONSecret[credentials](kr, "db")
+
+	if err := s.Set(context.Background(), credentials{Username: "llama", Password: "alpaca"}); err != nil {
+		t.Fatal(err)
+	}
+
+	item, err := kr.Get("db")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(item.Data) != `{"username":"llama","password":"alpaca"}` {
+		t.Fatalf("Unexpected item data: %s", item.Data)
+	}
+
+	creds, err := s.Get(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if creds.Username != "llama" || creds.Password != "alpaca" {
+		t.Fatalf("Unexpected credentials: %+v", creds)
+	}
+}
+
+func TestProtoSecret(t *testing.T) {
+	s := NewProtoSecret[*wrapperspb.StringValue](&keyring.ArrayKeyring{}, "token")
+
+	if err := s.Set(context.Background(), wrapperspb.String("llamas are great")); err != nil {
+		t.Fatal(err)
+	}
+
+	val, err := s.Get(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if val.GetValue() != "llamas are great" {
+		t.Fatalf("Unexpected value: %q", val.GetValue())
+	}
+}
+
+func TestSecretCancelledContext(t *testing.T) {
+	s := NewJSONSecret[string](&keyring.ArrayKeyring{}, "token")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := s.Set(ctx, "llamas"); err != context.Canceled {
+		t.Fatalf("Expected context.Canceled, got: %v", err)
+	}
+}
@@ -0,0 +1,133 @@
+// Package secretsharing splits a secret into shares using Shamir's Secret Sharing and
+// spreads them across several keyrings, so that no single keyring holds enough to
+// recover it.
+//
+//	shares, err := secretsharing.Split(masterKey, 3, 5)
+//	err = secretsharing.Store(keyrings, shares, "master-key")
+//	...
+//	masterKey, err = secretsharing.Reconstruct(keyrings, "master-key")
+package secretsharing
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/99designs/keyring"
+)
+
+// ShareDescription is the Item.Description given to shares stored on a keyring
+const ShareDescription = "shamir-share"
+
+// Share is one part of a split secret. Any Threshold of the Total shares are enough to
+// reconstruct it.
+type Share struct {
+	Index     int    `json:"index"`
+	Threshold int    `json:"threshold"`
+	Total     int    `json:"total"`
+	Data      []byte `json:"data"`
+}
+
+// ErrNotEnoughShares is returned by Reconstruct when fewer than the threshold number of
+// shares could be read
+var ErrNotEnoughShares = errors.New("Not enough shares to reconstruct the secret")
+
+// Split divides secret into the given number of shares, any threshold of which can
+// reconstruct it. Shares are numbered from 1.
+func Split(secret []byte, threshold, shares int) ([]Share, error) {
+	if len(secret) == 0 {
+		return nil, errors.New("Cannot split an empty secret")
+	}
+	if threshold < 2 || threshold > shares {
+		return nil, fmt.Errorf("Threshold must be between 2 and the number of shares, got %d", threshold)
+	}
+	if shares > 255 {
+		return nil, fmt.Errorf("Cannot split into more than 255 shares, got %d", shares)
+	}
+
+	parts, err := split(secret, threshold, shares)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]Share, shares)
+	for i, p := range parts {
+		result[i] = Share{
+			Index:     i + 1,
+			Threshold: threshold,
+			Total:     shares,
+			Data:      p,
+		}
+	}
+
+	return result, nil
+}
+
+// Store writes shares[i] to keyrings[i] under key. Each share is stored as a separate
+// Item that records its index, the threshold and the total number of shares.
+func Store(keyrings []keyring.Keyring, shares []Share, key string) error {
+	if len(keyrings) != len(shares) {
+		return fmt.Errorf("Got %d shares for %d keyrings", len(shares), len(keyrings))
+	}
+
+	for i, share := range shares {
+		data, err := json.Marshal(share)
+		if err != nil {
+			return err
+		}
+
+		err = keyrings[i].Set(keyring.Item{
+			Key:         key,
+			Data:        data,
+			Label:       fmt.Sprintf("%s (share %d of %d)", key, share.Index, share.Total),
+			Description: ShareDescription,
+		})
+		if err != nil {
+			return fmt.Errorf("Failed to store share %d: %w", share.Index, err)
+		}
+	}
+
+	return nil
+}
+
+// Reconstruct reads the shares stored under key from keyrings and recovers the secret.
+// Keyrings that can't be read are skipped, as long as at least the threshold number of
+// shares are available; otherwise ErrNotEnoughShares is returned.
+func Reconstruct(keyrings []keyring.Keyring, key string) ([]byte, error) {
+	var shares []Share
+	for i, kr := range keyrings {
+		item, err := kr.Get(key)
+		if err != nil {
+			keyring.Debugf("Skipping keyring %d: %v", i, err)
+			continue
+		}
+
+		var share Share
+		if err = json.Unmarshal(item.Data, &share); err != nil {
+			return nil, fmt.Errorf("Failed to decode share from keyring %d: %w", i, err)
+		}
+		if share.Index < 1 || share.Index > 255 {
+			return nil, fmt.Errorf("Invalid share index %d in keyring %d", share.Index, i)
+		}
+		if len(shares) > 0 && share.Threshold != shares[0].Threshold {
+			return nil, fmt.Errorf("Share in keyring %d has threshold %d, expected %d",
+				i, share.Threshold, shares[0].Threshold)
+		}
+		shares = append(shares, share)
+	}
+
+	if len(shares) == 0 || len(shares) < shares[0].Threshold {
+		return nil, ErrNotEnoughShares
+	}
+
+	// Any threshold shares determine the polynomial, so extras aren't needed
+	shares = shares[:shares[0].Threshold]
+	xs := make([]byte, len(shares))
+	ys := make([][]byte, len(shares))
+	for i, share := range shares {
+		xs[i] = byte(share.Index)
+		ys[i] = share.Data
+	}
+
+	return combine(xs, ys)
+}
@@ -0,0 +1,92 @@
+package secretsharing
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/99designs/keyring"
+)
+
+func newKeyrings(n int) []keyring.Keyring {
+	keyrings := make([]keyring.Keyring, n)
+	for i := range keyrings {
+		keyrings[i] = &keyring.ArrayKeyring{}
+	}
+	return keyrings
+}
+
+func TestSplitAndCombineEverySubset(t *testing.T) {
+	secret := []byte("llamas are great")
+	shares, err := Split(secret, 3, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for a := 0; a < 5; a++ {
+		for b := a + 1; b < 5; b++ {
+			for c := b + 1; c < 5; c++ {
+				subset := []Share{shares[a], shares[b], shares[c]}
+				xs := make([]byte, 3)
+				ys := make([][]byte, 3)
+				for i, s := range subset {
+					xs[i] = byte(s.Index)
+					ys[i] = s.Data
+				}
+				got, err := combine(xs, ys)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !bytes.Equal(got, secret) {
+					t.Fatalf("Shares %d, %d, %d gave %q", a+1, b+1, c+1, got)
+				}
+			}
+		}
+	}
+}
+
+func TestSplitRejectsBadThreshold(t *testing.T) {
+	if _, err := Split([]byte("llamas"), 1, 3); err == nil {
+		t.Fatal("Expected a threshold of 1 to be rejected")
+	}
+	if _, err := Split([]byte("llamas"), 4, 3); err == nil {
+		t.Fatal("Expected a threshold above the number of shares to be rejected")
+	}
+}
+
+func TestStoreAndReconstruct(t *testing.T) {
+	secret := []byte("llamas are great")
+	keyrings := newKeyrings(5)
+
+	shares, err := Split(secret, 3, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = Store(keyrings, shares, "master"); err != nil {
+		t.Fatal(err)
+	}
+
+	item, err := keyrings[1].Get("master")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.Label != "master (share 2 of 5)" || item.Description != ShareDescription {
+		t.Fatalf("Unexpected share item: %q, %q", item.Label, item.Description)
+	}
+
+	// Lose two of the shares
+	_ = keyrings[0].Remove("master")
+	_ = keyrings[3].Remove("master")
+
+	got, err := Reconstruct(keyrings, "master")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, secret) {
+		t.Fatalf("Reconstructed %q", got)
+	}
+
+	_ = keyrings[4].Remove("master")
+	if _, err = Reconstruct(keyrings, "master"); err != ErrNotEnoughShares {
+		t.Fatalf("Expected ErrNotEnoughShares, got: %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+package secretsharing
+
+import (
+	"crypto/rand"
+	"errors"
+)
+
+// Arithmetic in GF(2^8) with the AES reducing polynomial x^8 + x^4 + x^3 + x + 1.
+// Operations avoid lookup tables and data dependent branches so that they don't leak
+// secret bytes through timing.
+
+func gfMul(a, b byte) byte {
+	var p byte
+	for i := 0; i < 8; i++ {
+		p ^= -(b & 1) & a
+		a = (a << 1) ^ (-(a >> 7) & 0x1b)
+		b >>= 1
+	}
+	return p
+}
+
+// gfInv returns the multiplicative inverse of a, which is a^254 since a^255 = 1
+func gfInv(a byte) byte {
+	r := a
+	for i := 0; i < 6; i++ {
+		r = gfMul(r, r)
+		r = gfMul(r, a)
+	}
+	return gfMul(r, r)
+}
+
+// split evaluates a random polynomial of degree threshold-1 for each byte of secret at
+// x = 1..shares. The returned slices are indexed by x-1.
+func split(secret []byte, threshold, shares int) ([][]byte, error) {
+	coeffs := make([]byte, threshold-1)
+	out := make([][]byte, shares)
+	for i := range out {
+		out[i] = make([]byte, len(secret))
+	}
+
+	for idx, s := range secret {
+		if _, err := rand.Read(coeffs); err != nil {
+			return nil, err
+		}
+		for i := range out {
+			x := byte(i + 1)
+			// Horner's method, from the highest coefficient down to the secret
+			var y byte
+			for c := len(coeffs) - 1; c >= 0; c-- {
+				y = gfMul(y, x) ^ coeffs[c]
+			}
+			out[i][idx] = gfMul(y, x) ^ s
+		}
+	}
+
+	return out, nil
+}
+
+// combine recovers the secret by Lagrange interpolation of ys at x = 0
+func combine(xs []byte, ys [][]byte) ([]byte, error) {
+	for i := range xs {
+		if xs[i] == 0 {
+			return nil, errors.New("Share index must not be zero")
+		}
+		for j := 0; j < i; j++ {
+			if xs[i] == xs[j] {
+				return nil, errors.New("Duplicate share index")
+			}
+		}
+		if len(ys[i]) != len(ys[0]) {
+			return nil, errors.New("Shares have different lengths")
+		}
+	}
+
+	secret := make([]byte, len(ys[0]))
+	for i := range xs {
+		// basis = prod_{j != i} x_j / (x_j - x_i), where subtraction is xor
+		var basis byte = 1
+		for j := range xs {
+			if i == j {
+				continue
+			}
+			basis = gfMul(basis, gfMul(xs[j], gfInv(xs[j]^xs[i])))
+		}
+		for idx := range secret {
+			secret[idx] ^= gfMul(ys[i][idx], basis)
+		}
+	}
+
+	return secret, nil
+}
@@ -0,0 +1,151 @@
+package keyring
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// KeyCacheTTL is how long signers and decrypters returned by AsSigner and AsDecrypter use
+// a private key before getting it from the keyring again, so that rotated keys are picked up
+var KeyCacheTTL = 5 * time.Minute
+
+// AsSigner returns a crypto.Signer for the PEM encoded RSA, ECDSA or Ed25519 private key
+// stored as the data of the item key
+func AsSigner(kr Keyring, key string) (crypto.Signer, error) {
+	k, err := newKeyringKey(kr, key)
+	if err != nil {
+		return nil, err
+	}
+	return &keyringSigner{k}, nil
+}
+
+// AsDecrypter returns a crypto.Decrypter for the PEM encoded RSA private key stored as the
+// data of the item key
+func AsDecrypter(kr Keyring, key string) (crypto.Decrypter, error) {
+	k, err := newKeyringKey(kr, key)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := k.priv.(crypto.Decrypter); !ok {
+		return nil, fmt.Errorf("Key %q is a %T, which can't decrypt", key, k.priv)
+	}
+	return &keyringDecrypter{k}, nil
+}
+
+// keyringKey is a private key read from a keyring, which is read again once it's older
+// than the ttl
+type keyringKey struct {
+	kr  Keyring
+	key string
+	ttl time.Duration
+
+	mu      sync.Mutex
+	priv    crypto.Signer
+	fetched time.Time
+}
+
+func newKeyringKey(kr Keyring, key string) (*keyringKey, error) {
+	k := &keyringKey{kr: kr, key: key, ttl: KeyCacheTTL}
+	if _, err := k.signer(); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// signer returns the private key, getting it from the keyring again if it has expired
+func (k *keyringKey) signer() (crypto.Signer, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if k.priv != nil && time.Since(k.fetched) < k.ttl {
+		return k.priv, nil
+	}
+
+	item, err := k.kr.Get(k.key)
+	if err != nil {
+		return nil, err
+	}
+	priv, err := parsePrivateKey(item.Data)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse private key %q: %v", k.key, err)
+	}
+
+	Debugf("Loaded %T private key %q", priv, k.key)
+	k.priv = priv
+	k.fetched = time.Now()
+	return priv, nil
+}
+
+// Public returns the public key, getting the private key from the keyring again if it has
+// expired. The last key read is used if that fails, as crypto.Signer can't return an error.
+func (k *keyringKey) Public() crypto.PublicKey {
+	priv, err := k.signer()
+	if err != nil {
+		Debugf("Failed to refresh private key %q, using the cached key: %v", k.key, err)
+		k.mu.Lock()
+		defer k.mu.Unlock()
+		return k.priv.Public()
+	}
+	return priv.Public()
+}
+
+// parsePrivateKey parses a PEM encoded PKCS#8, PKCS#1 or SEC 1 private key
+func parsePrivateKey(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("No PEM data found")
+	}
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("Unsupported private key type %T", key)
+		}
+		return signer, nil
+	default:
+		return nil, fmt.Errorf("Unsupported PEM block type %q", block.Type)
+	}
+}
+
+type keyringSigner struct {
+	*keyringKey
+}
+
+func (s *keyringSigner) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	priv, err := s.signer()
+	if err != nil {
+		return nil, err
+	}
+	return priv.Sign(rand, digest, opts)
+}
+
+type keyringDecrypter struct {
+	*keyringKey
+}
+
+func (d *keyringDecrypter) Decrypt(rand io.Reader, msg []byte, opts crypto.DecrypterOpts) ([]byte, error) {
+	priv, err := d.signer()
+	if err != nil {
+		return nil, err
+	}
+	decrypter, ok := priv.(crypto.Decrypter)
+	if !ok {
+		return nil, fmt.Errorf("Key %q is a %T, which can't decrypt", d.key, priv)
+	}
+	return decrypter.Decrypt(rand, msg, opts)
+}
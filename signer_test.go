@@ -0,0 +1,104 @@
+package keyring
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+)
+
+func pemEncode(t *testing.T, priv interface{}) []byte {
+	der, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+func TestAsSignerPicksUpRotatedKeys(t *testing.T) {
+	defer func(ttl time.Duration) { KeyCacheTTL = ttl }(KeyCacheTTL)
+	KeyCacheTTL = 0
+
+	first, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	kr := &ArrayKeyring{}
+	_ = kr.Set(Item{Key: "signing-key", Data: pemEncode(t, first)})
+
+	signer, err := AsSigner(kr, "signing-key")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	digest := sha256.Sum256([]byte("llamas are great"))
+	sig, err := signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ecdsa.VerifyASN1(&first.PublicKey, digest[:], sig) {
+		t.Fatal("Expected the signature to verify")
+	}
+
+	second, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	_ = kr.Set(Item{Key: "signing-key", Data: pemEncode(t, second)})
+
+	sig, err = signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ecdsa.VerifyASN1(&second.PublicKey, digest[:], sig) {
+		t.Fatal("Expected the signature to be made with the rotated key")
+	}
+	if !second.PublicKey.Equal(signer.Public()) {
+		t.Fatal("Expected the public key of the rotated key")
+	}
+
+	// The last key read is kept if the keyring fails
+	_ = kr.Remove("signing-key")
+	if !second.PublicKey.Equal(signer.Public()) {
+		t.Fatal("Expected the cached public key when the key can't be read")
+	}
+}
+
+func TestAsDecrypter(t *testing.T) {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kr := &ArrayKeyring{}
+	_ = kr.Set(Item{Key: "rsa", Data: pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(priv),
+	})})
+
+	decrypter, err := AsDecrypter(kr, "rsa")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ciphertext, err := rsa.EncryptPKCS1v15(rand.Reader, decrypter.Public().(*rsa.PublicKey), []byte("llamas"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	plaintext, err := decrypter.Decrypt(rand.Reader, ciphertext, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(plaintext) != "llamas" {
+		t.Fatalf("Unexpected plaintext %q", plaintext)
+	}
+}
+
+func TestAsDecrypterRejectsECDSA(t *testing.T) {
+	priv, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	kr := &ArrayKeyring{}
+	_ = kr.Set(Item{Key: "ec", Data: pemEncode(t, priv)})
+
+	if _, err := AsDecrypter(kr, "ec"); err == nil {
+		t.Fatal("Expected an error for an ECDSA key")
+	}
+}
@@ -0,0 +1,41 @@
+package keyring
+
+import "fmt"
+
+// ErrItemTooLarge is returned by Set when an item's data is larger than
+// Config.MaxItemDataSize
+type ErrItemTooLarge struct {
+	Key    string
+	Actual int
+	Max    int
+}
+
+func (e *ErrItemTooLarge) Error() string {
+	return fmt.Sprintf("Item %q has %d bytes of data, more than the maximum of %d; "+
+		"use NewChunkedKeyring to store large items", e.Key, e.Actual, e.Max)
+}
+
+type sizeLimitKeyring struct {
+	Keyring
+	maxItemDataSize int
+}
+
+// newSizeLimitKeyring wraps kr so that Set rejects items with more than max bytes of data
+// before they reach the backend
+func newSizeLimitKeyring(kr Keyring, max int) *sizeLimitKeyring {
+	return &sizeLimitKeyring{Keyring: kr, maxItemDataSize: max}
+}
+
+func (k *sizeLimitKeyring) validate(item Item) error {
+	if len(item.Data) > k.maxItemDataSize {
+		return &ErrItemTooLarge{Key: item.Key, Actual: len(item.Data), Max: k.maxItemDataSize}
+	}
+	return nil
+}
+
+func (k *sizeLimitKeyring) Set(item Item) error {
+	if err := k.validate(item); err != nil {
+		return err
+	}
+	return k.Keyring.Set(item)
+}
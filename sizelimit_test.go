@@ -0,0 +1,31 @@
+package keyring
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSizeLimitKeyringRejectsLargeItems(t *testing.T) {
+	backing := &ArrayKeyring{}
+	k := wrap(backing, Config{MaxItemDataSize: 8})
+
+	err := k.Set(Item{Key: "llamas", Data: []byte("llamas are great")})
+	var tooLarge *ErrItemTooLarge
+	if !errors.As(err, &tooLarge) {
+		t.Fatalf("Expected ErrItemTooLarge, got: %v", err)
+	}
+	if tooLarge.Actual != 16 || tooLarge.Max != 8 {
+		t.Fatalf("Unexpected sizes: %+v", tooLarge)
+	}
+	if !strings.Contains(err.Error(), "NewChunkedKeyring") {
+		t.Fatalf("Expected the error to suggest chunking: %v", err)
+	}
+	if _, err = backing.Get("llamas"); err != ErrKeyNotFound {
+		t.Fatal("Expected the item not to reach the backend")
+	}
+
+	if err = k.Set(Item{Key: "llamas", Data: []byte("llamas")}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,73 @@
+// Package ssh generates SSH keys and keeps their private halves on a keyring.
+//
+// Private keys are stored PEM-encoded in the OpenSSH format, so they can be exported
+// and used directly by ssh(1) if needed.
+package ssh
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"fmt"
+
+	"github.com/99designs/keyring"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// KeyDescription is the Item.Description given to SSH private keys stored on a keyring
+const KeyDescription = "openssh-key"
+
+// GenerateAndStore generates an Ed25519 key pair, stores the private key on kr under
+// keyName and returns the public key
+func GenerateAndStore(kr keyring.Keyring, keyName, comment string) (gossh.PublicKey, error) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := gossh.MarshalPrivateKey(priv, comment)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey, err := gossh.NewPublicKey(pub)
+	if err != nil {
+		return nil, err
+	}
+
+	err = kr.Set(keyring.Item{
+		Key:         keyName,
+		Data:        pem.EncodeToMemory(block),
+		Label:       comment,
+		Description: KeyDescription,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return publicKey, nil
+}
+
+// GetSSHKey loads the private key stored on kr under keyName. The returned signer can be
+// used with golang.org/x/crypto/ssh via ssh.NewSignerFromSigner.
+func GetSSHKey(kr keyring.Keyring, keyName string) (crypto.Signer, error) {
+	item, err := kr.Get(keyName)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := gossh.ParseRawPrivateKey(item.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	switch k := key.(type) {
+	case *ed25519.PrivateKey:
+		return *k, nil
+	case crypto.Signer:
+		return k, nil
+	default:
+		return nil, fmt.Errorf("Key %q is a %T, which can't be used for signing", keyName, key)
+	}
+}
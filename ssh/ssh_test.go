@@ -0,0 +1,47 @@
+package ssh
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/99designs/keyring"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+func TestGenerateAndStore(t *testing.T) {
+	kr := &keyring.ArrayKeyring{}
+
+	publicKey, err := GenerateAndStore(kr, "deploy", "llamas@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	item, err := kr.Get("deploy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.Description != KeyDescription {
+		t.Fatalf("Unexpected description: %q", item.Description)
+	}
+
+	key, err := GetSSHKey(kr, "deploy")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signer, err := gossh.NewSignerFromSigner(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(signer.PublicKey().Marshal(), publicKey.Marshal()) {
+		t.Fatal("Loaded key doesn't match the generated public key")
+	}
+
+	sig, err := signer.Sign(nil, []byte("llamas are great"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = publicKey.Verify([]byte("llamas are great"), sig); err != nil {
+		t.Fatal(err)
+	}
+}
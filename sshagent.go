@@ -0,0 +1,141 @@
+package keyring
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+func init() {
+	supportedBackends[SSHAgentBackend] = opener(func(cfg Config) (Keyring, error) {
+		sock := cfg.SSHAuthSock
+		if sock == "" {
+			sock = os.Getenv("SSH_AUTH_SOCK")
+		}
+		if sock == "" {
+			return nil, errors.New("No SSH agent socket provided and SSH_AUTH_SOCK isn't set")
+		}
+
+		return &sshAgentKeyring{sock: sock}, nil
+	})
+}
+
+// sshAgentKeyring exposes the keys held by a running ssh-agent. Items are keyed by the
+// SHA256 fingerprint of the key, as shown by ssh-add -l. Get returns the public key in
+// authorized_keys format, as the agent never reveals private keys, while Set adds a PEM
+// encoded private key to the agent.
+type sshAgentKeyring struct {
+	sock string
+}
+
+// withAgent connects to the agent for the duration of fn
+func (k *sshAgentKeyring) withAgent(fn func(agent.ExtendedAgent) error) error {
+	conn, err := net.Dial("unix", k.sock)
+	if err != nil {
+		return fmt.Errorf("Failed to connect to SSH agent at %s: %v", k.sock, err)
+	}
+	defer conn.Close()
+
+	return fn(agent.NewClient(conn))
+}
+
+// find returns the agent key with the given fingerprint
+func (k *sshAgentKeyring) find(a agent.ExtendedAgent, fingerprint string) (*agent.Key, error) {
+	keys, err := a.List()
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range keys {
+		if ssh.FingerprintSHA256(key) == fingerprint {
+			return key, nil
+		}
+	}
+	return nil, ErrKeyNotFound
+}
+
+func (k *sshAgentKeyring) Get(key string) (Item, error) {
+	var item Item
+	err := k.withAgent(func(a agent.ExtendedAgent) error {
+		agentKey, err := k.find(a, key)
+		if err != nil {
+			return err
+		}
+		item = Item{
+			Key:         key,
+			Data:        ssh.MarshalAuthorizedKey(agentKey),
+			Label:       agentKey.Comment,
+			Description: agentKey.Type(),
+		}
+		return nil
+	})
+	return item, WrapError(err, SSHAgentBackend, k.sock, key)
+}
+
+func (k *sshAgentKeyring) GetMetadata(key string) (Metadata, error) {
+	var md Metadata
+	err := k.withAgent(func(a agent.ExtendedAgent) error {
+		agentKey, err := k.find(a, key)
+		if err != nil {
+			return err
+		}
+		md.Item = &Item{
+			Key:         key,
+			Label:       agentKey.Comment,
+			Description: agentKey.Type(),
+		}
+		return nil
+	})
+	return md, WrapError(err, SSHAgentBackend, k.sock, key)
+}
+
+// Set adds the PEM encoded private key in item.Data to the agent, with item.Label as its
+// comment. The key must be the fingerprint of the private key, or empty.
+func (k *sshAgentKeyring) Set(item Item) error {
+	privateKey, err := ssh.ParseRawPrivateKey(item.Data)
+	if err != nil {
+		return fmt.Errorf("Failed to parse private key for %q: %v", item.Key, err)
+	}
+
+	signer, err := ssh.NewSignerFromKey(privateKey)
+	if err != nil {
+		return WrapError(err, SSHAgentBackend, k.sock, item.Key)
+	}
+	if fingerprint := ssh.FingerprintSHA256(signer.PublicKey()); item.Key != "" && item.Key != fingerprint {
+		return fmt.Errorf("Key %q doesn't match the private key's fingerprint %s", item.Key, fingerprint)
+	}
+
+	err = k.withAgent(func(a agent.ExtendedAgent) error {
+		return a.Add(agent.AddedKey{PrivateKey: privateKey, Comment: item.Label})
+	})
+	return WrapError(err, SSHAgentBackend, k.sock, item.Key)
+}
+
+func (k *sshAgentKeyring) Remove(key string) error {
+	err := k.withAgent(func(a agent.ExtendedAgent) error {
+		agentKey, err := k.find(a, key)
+		if err != nil {
+			return err
+		}
+		return a.Remove(agentKey)
+	})
+	return WrapError(err, SSHAgentBackend, k.sock, key)
+}
+
+func (k *sshAgentKeyring) Keys() ([]string, error) {
+	var keys = []string{}
+	err := k.withAgent(func(a agent.ExtendedAgent) error {
+		agentKeys, err := a.List()
+		if err != nil {
+			return err
+		}
+		for _, key := range agentKeys {
+			keys = append(keys, ssh.FingerprintSHA256(key))
+		}
+		return nil
+	})
+	return keys, WrapError(err, SSHAgentBackend, k.sock, "")
+}
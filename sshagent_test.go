@@ -0,0 +1,83 @@
+package keyring
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+func startTestAgent(t *testing.T) string {
+	t.Helper()
+
+	sock := filepath.Join(t.TempDir(), "agent.sock")
+	l, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Skipf("Unix sockets aren't available: %v", err)
+	}
+	t.Cleanup(func() { l.Close() })
+
+	a := agent.NewKeyring()
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				_ = agent.ServeAgent(a, conn)
+			}()
+		}
+	}()
+
+	return sock
+}
+
+func TestSSHAgentKeyring(t *testing.T) {
+	k, err := supportedBackends[SSHAgentBackend](Config{SSHAuthSock: startTestAgent(t)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := ssh.MarshalPrivateKey(priv, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = k.Set(Item{Data: pem.EncodeToMemory(block), Label: "llama@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+
+	keys, err := k.Keys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 1 || !strings.HasPrefix(keys[0], "SHA256:") {
+		t.Fatalf("Unexpected keys: %v", keys)
+	}
+
+	item, err := k.Get(keys[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(item.Data), "ssh-ed25519 ") || item.Label != "llama@example.com" {
+		t.Fatalf("Unexpected item: %+v", item)
+	}
+
+	if err = k.Remove(keys[0]); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = k.Get(keys[0]); err != ErrKeyNotFound {
+		t.Fatalf("Expected ErrKeyNotFound, got: %v", err)
+	}
+}
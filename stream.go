@@ -0,0 +1,44 @@
+package keyring
+
+import (
+	"io"
+	"io/ioutil"
+)
+
+// streamer is implemented by backends that can write and read item data without holding
+// it all in memory
+type streamer interface {
+	setStream(key string, r io.Reader, label, description string) error
+	getStream(key string, w io.Writer) (label, description string, err error)
+}
+
+// SetStream stores the data read from r as the item key. Backends that support streaming,
+// such as age, encrypt the data as it's read. All others read it into memory and call Set.
+func SetStream(kr Keyring, key string, r io.Reader, label, description string) error {
+	if s, ok := kr.(streamer); ok {
+		return s.setStream(key, r, label, description)
+	}
+
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return kr.Set(Item{Key: key, Data: data, Label: label, Description: description})
+}
+
+// GetStream writes the data of the item key to w. Backends that support streaming, such as
+// age, decrypt the data as it's written. All others call Get and write the data at once.
+func GetStream(kr Keyring, key string, w io.Writer) (label, description string, err error) {
+	if s, ok := kr.(streamer); ok {
+		return s.getStream(key, w)
+	}
+
+	item, err := kr.Get(key)
+	if err != nil {
+		return "", "", err
+	}
+	if _, err = w.Write(item.Data); err != nil {
+		return "", "", err
+	}
+	return item.Label, item.Description, nil
+}
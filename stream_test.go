@@ -0,0 +1,77 @@
+package keyring
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestStreamFallsBackToBuffering(t *testing.T) {
+	kr := &ArrayKeyring{}
+
+	if err := SetStream(kr, "llamas", strings.NewReader("llamas are great"), "Llamas", "All about llamas"); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	label, description, err := GetStream(kr, "llamas", &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "llamas are great" || label != "Llamas" || description != "All about llamas" {
+		t.Fatalf("Unexpected item %q, %q, %q", buf.String(), label, description)
+	}
+}
+
+func TestAgeKeyringStreams(t *testing.T) {
+	k := openAgeKeyring(t, t.TempDir(), "test")
+	if _, ok := k.(streamer); !ok {
+		t.Fatal("Expected the age backend to support streaming")
+	}
+
+	data := bytes.Repeat([]byte("llamas are great\n"), 100000)
+	if err := SetStream(k, "llamas", bytes.NewReader(data), "Llamas", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	label, _, err := GetStream(k, "llamas", &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), data) || label != "Llamas" {
+		t.Fatalf("Unexpected streamed item with %d bytes and label %q", buf.Len(), label)
+	}
+
+	// Streamed items can be read with Get, and items from Set can be streamed
+	item, err := k.Get("llamas")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(item.Data, data) {
+		t.Fatalf("Expected Get to return the streamed data, got %d bytes", len(item.Data))
+	}
+
+	if err = k.Set(Item{Key: "alpacas", Data: []byte("alpacas are great")}); err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	if _, _, err = GetStream(k, "alpacas", &buf); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "alpacas are great" {
+		t.Fatalf("Unexpected data %q", buf.String())
+	}
+
+	keys, err := k.Keys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 2 {
+		t.Fatalf("Expected 2 keys, got %v", keys)
+	}
+
+	if _, _, err = GetStream(k, "guanacos", &buf); err != ErrKeyNotFound {
+		t.Fatalf("Expected ErrKeyNotFound, got: %v", err)
+	}
+}
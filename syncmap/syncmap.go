@@ -0,0 +1,316 @@
+// Package syncmap is an in-memory keyring that survives restarts by journaling writes to
+// a file, for small deployments that don't warrant a database.
+//
+// Items are held in a sync.Map, so reads never touch the disk. Each Set and Remove is
+// appended to the journal as a line of JSON and synced before it takes effect, and the
+// journal is replayed when the keyring is created. Once the journal grows past
+// Config.JournalMaxBytes it's compacted by rewriting it with only the current items.
+//
+// Items are stored in the journal unencrypted, so it should only be used where the file
+// system is trusted.
+package syncmap
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/99designs/keyring"
+)
+
+// DefaultJournalMaxBytes is the size at which the journal is compacted if
+// Config.JournalMaxBytes isn't set
+const DefaultJournalMaxBytes = 1 << 20
+
+const (
+	opSet    = "set"
+	opRemove = "remove"
+)
+
+// Config configures a JournaledMemoryKeyring
+type Config struct {
+	// JournalPath is the file that writes are journaled to
+	JournalPath string
+	// JournalMaxBytes is the journal size above which it's compacted. Zero means
+	// DefaultJournalMaxBytes.
+	JournalMaxBytes int64
+}
+
+// JournaledMemoryKeyring is an in-memory keyring backed by a write-ahead journal
+type JournaledMemoryKeyring struct {
+	items sync.Map // key -> entry
+
+	// mu serialises writes, so that the journal and items agree on their order
+	mu       sync.Mutex
+	path     string
+	maxBytes int64
+	journal  *os.File
+	size     int64
+}
+
+type entry struct {
+	item     keyring.Item
+	modified time.Time
+}
+
+// journalEntry is a line of the journal
+type journalEntry struct {
+	Op   string        `json:"op"`
+	Key  string        `json:"key,omitempty"`
+	Item *keyring.Item `json:"item,omitempty"`
+	Time time.Time     `json:"time"`
+}
+
+// NewJournaledMemoryKeyring returns a keyring journaled to journalPath, restoring the
+// items in the journal if it exists
+func NewJournaledMemoryKeyring(journalPath string) (keyring.Keyring, error) {
+	return New(Config{JournalPath: journalPath})
+}
+
+// New returns a JournaledMemoryKeyring as described by cfg, restoring the items in the
+// journal if it exists
+func New(cfg Config) (*JournaledMemoryKeyring, error) {
+	if cfg.JournalPath == "" {
+		return nil, errors.New("No journal path provided")
+	}
+
+	k := &JournaledMemoryKeyring{
+		path:     cfg.JournalPath,
+		maxBytes: cfg.JournalMaxBytes,
+	}
+	if k.maxBytes <= 0 {
+		k.maxBytes = DefaultJournalMaxBytes
+	}
+
+	if err := k.replay(); err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(k.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+	k.journal = f
+
+	return k, nil
+}
+
+// replay restores items from the journal. A partial last line, left by a crash part way
+// through a write, is discarded.
+func (k *JournaledMemoryKeyring) replay() error {
+	f, err := os.Open(k.path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	var count int
+	for {
+		line, err := r.ReadBytes('\n')
+		if err == io.EOF {
+			if len(line) > 0 {
+				keyring.Debugf("Discarding partial journal entry in %s", k.path)
+				if err = os.Truncate(k.path, k.size); err != nil {
+					return err
+				}
+			}
+			break
+		} else if err != nil {
+			return err
+		}
+
+		var e journalEntry
+		if err = json.Unmarshal(line, &e); err == nil {
+			err = e.validate()
+		}
+		if err != nil {
+			return fmt.Errorf("Failed to parse journal entry %d in %s: %v", count+1, k.path, err)
+		}
+		k.apply(e)
+		k.size += int64(len(line))
+		count++
+	}
+
+	keyring.Debugf("Replayed %d journal entries from %s", count, k.path)
+	return nil
+}
+
+// validate checks that e has what apply needs for its operation
+func (e journalEntry) validate() error {
+	switch e.Op {
+	case opSet:
+		if e.Item == nil {
+			return errors.New("Set entry has no item")
+		}
+	case opRemove:
+		if e.Key == "" {
+			return errors.New("Remove entry has no key")
+		}
+	default:
+		return fmt.Errorf("Unknown operation %q", e.Op)
+	}
+	return nil
+}
+
+func (k *JournaledMemoryKeyring) apply(e journalEntry) {
+	switch e.Op {
+	case opSet:
+		k.items.Store(e.Item.Key, entry{item: *e.Item, modified: e.Time})
+	case opRemove:
+		k.items.Delete(e.Key)
+	}
+}
+
+// write journals e and then applies it. Callers must hold mu.
+func (k *JournaledMemoryKeyring) write(e journalEntry) error {
+	if k.journal == nil {
+		return os.ErrClosed
+	}
+
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	if _, err = k.journal.Write(line); err != nil {
+		return err
+	}
+	if err = k.journal.Sync(); err != nil {
+		return err
+	}
+	k.size += int64(len(line))
+	k.apply(e)
+
+	if k.size > k.maxBytes {
+		if err = k.compact(); err != nil {
+			keyring.Debugf("Failed to compact journal %s: %v", k.path, err)
+		}
+	}
+	return nil
+}
+
+// compact replaces the journal with one that sets each current item. Callers must hold mu.
+func (k *JournaledMemoryKeyring) compact() error {
+	var buf bytes.Buffer
+	var err error
+	k.items.Range(func(_, v interface{}) bool {
+		e := v.(entry)
+		var line []byte
+		line, err = json.Marshal(journalEntry{Op: opSet, Item: &e.item, Time: e.modified})
+		buf.Write(line)
+		buf.WriteByte('\n')
+		return err == nil
+	})
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(k.path), filepath.Base(k.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	// On Windows the journal can't be replaced while it's open
+	if err = k.journal.Close(); err != nil {
+		return err
+	}
+	renameErr := os.Rename(tmp.Name(), k.path)
+	if k.journal, err = os.OpenFile(k.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600); err != nil {
+		return err
+	}
+	if renameErr != nil {
+		return renameErr
+	}
+
+	keyring.Debugf("Compacted journal %s from %d to %d bytes", k.path, k.size, buf.Len())
+	k.size = int64(buf.Len())
+	return nil
+}
+
+func (k *JournaledMemoryKeyring) Get(key string) (keyring.Item, error) {
+	v, ok := k.items.Load(key)
+	if !ok {
+		return keyring.Item{}, keyring.ErrKeyNotFound
+	}
+	return v.(entry).item, nil
+}
+
+func (k *JournaledMemoryKeyring) GetMetadata(key string) (keyring.Metadata, error) {
+	v, ok := k.items.Load(key)
+	if !ok {
+		return keyring.Metadata{}, keyring.ErrKeyNotFound
+	}
+
+	e := v.(entry)
+	item := e.item
+	item.Data = nil
+	return keyring.Metadata{Item: &item, ModificationTime: e.modified}, nil
+}
+
+func (k *JournaledMemoryKeyring) Set(item keyring.Item) error {
+	if err := keyring.ValidateRotationSchedule(item); err != nil {
+		return err
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	return k.write(journalEntry{Op: opSet, Item: &item, Time: time.Now()})
+}
+
+func (k *JournaledMemoryKeyring) Remove(key string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if _, ok := k.items.Load(key); !ok {
+		return keyring.ErrKeyNotFound
+	}
+	return k.write(journalEntry{Op: opRemove, Key: key, Time: time.Now()})
+}
+
+func (k *JournaledMemoryKeyring) Keys() ([]string, error) {
+	var keys = []string{}
+	k.items.Range(func(key, _ interface{}) bool {
+		keys = append(keys, key.(string))
+		return true
+	})
+	return keys, nil
+}
+
+// Close closes the journal. Items can still be read, but not written.
+func (k *JournaledMemoryKeyring) Close() error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if k.journal == nil {
+		return nil
+	}
+	err := k.journal.Close()
+	k.journal = nil
+	return err
+}
@@ -0,0 +1,109 @@
+package syncmap
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/99designs/keyring"
+)
+
+func TestJournaledMemoryKeyringReplays(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal")
+
+	kr, err := NewJournaledMemoryKeyring(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = kr.Set(keyring.Item{Key: "llamas", Data: []byte("llamas are great")})
+	_ = kr.Set(keyring.Item{Key: "alpacas", Data: []byte("alpacas are great")})
+	if err = kr.Remove("alpacas"); err != nil {
+		t.Fatal(err)
+	}
+	_ = kr.(*JournaledMemoryKeyring).Close()
+
+	// Simulate a crash part way through writing an entry
+	f, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0600)
+	_, _ = f.WriteString(`{"op":"set","item":{"Key":"gua`)
+	f.Close()
+
+	kr, err = NewJournaledMemoryKeyring(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	item, err := kr.Get("llamas")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(item.Data) != "llamas are great" {
+		t.Fatalf("Unexpected data %q", item.Data)
+	}
+	if _, err = kr.Get("alpacas"); err != keyring.ErrKeyNotFound {
+		t.Fatalf("Expected ErrKeyNotFound, got: %v", err)
+	}
+
+	// The partial entry is discarded so that later entries are readable
+	_ = kr.Set(keyring.Item{Key: "guanacos", Data: []byte("guanacos")})
+	_ = kr.(*JournaledMemoryKeyring).Close()
+	if kr, err = NewJournaledMemoryKeyring(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = kr.Get("guanacos"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestJournaledMemoryKeyringCompacts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal")
+
+	kr, err := New(Config{JournalPath: path, JournalMaxBytes: 1024})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer kr.Close()
+
+	for i := 0; i < 100; i++ {
+		if err = kr.Set(keyring.Item{Key: "llamas", Data: []byte(fmt.Sprintf("llamas %d", i))}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stat, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stat.Size() > 1024 {
+		t.Fatalf("Expected the journal to be compacted, it's %d bytes", stat.Size())
+	}
+
+	reopened, err := New(Config{JournalPath: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+
+	item, err := reopened.Get("llamas")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(item.Data) != "llamas 99" {
+		t.Fatalf("Unexpected data %q", item.Data)
+	}
+}
+
+func TestJournaledMemoryKeyringRejectsInvalidEntries(t *testing.T) {
+	for _, line := range []string{
+		`{"op":"set"}`,
+		`{"op":"remove"}`,
+		`{"op":"llamas","key":"llamas"}`,
+	} {
+		path := filepath.Join(t.TempDir(), "journal")
+		if err := os.WriteFile(path, []byte(line+"\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := NewJournaledMemoryKeyring(path); err == nil {
+			t.Fatalf("Expected an error replaying %s", line)
+		}
+	}
+}
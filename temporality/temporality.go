@@ -0,0 +1,143 @@
+// Package temporality restricts access to a keyring to a recurring time window, such as
+// business hours.
+package temporality
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/99designs/keyring"
+)
+
+// Schedule is a daily window during which a keyring may be accessed. Only the time of day
+// of AllowFrom and AllowUntil is used. If AllowUntil is before AllowFrom, the window runs
+// past midnight into the next day.
+type Schedule struct {
+	// AllowedDays are the days on which the window opens. Empty means every day.
+	AllowedDays []time.Weekday
+	AllowFrom   time.Time
+	AllowUntil  time.Time
+	// Location is the time zone of the window, defaulting to time.Local
+	Location *time.Location
+}
+
+// ErrAccessDenied is returned when a keyring is accessed outside its schedule
+type ErrAccessDenied struct {
+	NextAllowed time.Time
+}
+
+func (e ErrAccessDenied) Error() string {
+	if e.NextAllowed.IsZero() {
+		return "Access to the keyring is not allowed by its schedule"
+	}
+	return fmt.Sprintf("Access to the keyring is not allowed until %s", e.NextAllowed.Format(time.RFC1123))
+}
+
+// now is replaced in tests
+var now = time.Now
+
+type temporalKeyring struct {
+	kr       keyring.Keyring
+	schedule Schedule
+}
+
+// NewTemporalKeyring wraps kr so that Get, Set and Remove return ErrAccessDenied outside
+// the window described by schedule. GetMetadata and Keys are always allowed, so that the
+// keyring can still be audited.
+func NewTemporalKeyring(kr keyring.Keyring, schedule Schedule) keyring.Keyring {
+	return &temporalKeyring{kr: kr, schedule: schedule}
+}
+
+func (k *temporalKeyring) check() error {
+	t := now()
+	if k.schedule.Allowed(t) {
+		return nil
+	}
+	return ErrAccessDenied{NextAllowed: k.schedule.NextAllowed(t)}
+}
+
+// Allowed returns whether t falls within the schedule
+func (s Schedule) Allowed(t time.Time) bool {
+	t = t.In(s.location())
+	tod := sinceMidnight(t)
+	from, until := sinceMidnight(s.AllowFrom), sinceMidnight(s.AllowUntil)
+
+	if from <= until {
+		return s.allowedDay(t.Weekday()) && tod >= from && tod < until
+	}
+
+	// The window spans midnight, so the early hours belong to the previous day's window
+	if tod >= from {
+		return s.allowedDay(t.Weekday())
+	}
+	return tod < until && s.allowedDay((t.Weekday()+6)%7)
+}
+
+// NextAllowed returns the next time after t at which the window opens, or the zero time
+// if it never does
+func (s Schedule) NextAllowed(t time.Time) time.Time {
+	t = t.In(s.location())
+	h, m, sec := s.AllowFrom.Clock()
+
+	for day := 0; day <= 7; day++ {
+		start := time.Date(t.Year(), t.Month(), t.Day()+day, h, m, sec, s.AllowFrom.Nanosecond(), t.Location())
+		if start.After(t) && s.allowedDay(start.Weekday()) {
+			return start
+		}
+	}
+	return time.Time{}
+}
+
+func (s Schedule) location() *time.Location {
+	if s.Location == nil {
+		return time.Local
+	}
+	return s.Location
+}
+
+func (s Schedule) allowedDay(day time.Weekday) bool {
+	if len(s.AllowedDays) == 0 {
+		return true
+	}
+	for _, d := range s.AllowedDays {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+func sinceMidnight(t time.Time) time.Duration {
+	h, m, sec := t.Clock()
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute +
+		time.Duration(sec)*time.Second + time.Duration(t.Nanosecond())
+}
+
+func (k *temporalKeyring) Get(key string) (keyring.Item, error) {
+	if err := k.check(); err != nil {
+		return keyring.Item{}, err
+	}
+	return k.kr.Get(key)
+}
+
+func (k *temporalKeyring) GetMetadata(key string) (keyring.Metadata, error) {
+	return k.kr.GetMetadata(key)
+}
+
+func (k *temporalKeyring) Set(item keyring.Item) error {
+	if err := k.check(); err != nil {
+		return err
+	}
+	return k.kr.Set(item)
+}
+
+func (k *temporalKeyring) Remove(key string) error {
+	if err := k.check(); err != nil {
+		return err
+	}
+	return k.kr.Remove(key)
+}
+
+func (k *temporalKeyring) Keys() ([]string, error) {
+	return k.kr.Keys()
+}
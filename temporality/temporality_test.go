@@ -0,0 +1,81 @@
+package temporality
+
+import (
+	"testing"
+	"time"
+
+	"github.com/99designs/keyring"
+)
+
+var businessHours = Schedule{
+	AllowedDays: []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+	AllowFrom:   time.Date(0, 1, 1, 9, 0, 0, 0, time.UTC),
+	AllowUntil:  time.Date(0, 1, 1, 17, 0, 0, 0, time.UTC),
+	Location:    time.UTC,
+}
+
+func at(t time.Time) func() {
+	orig := now
+	now = func() time.Time { return t }
+	return func() { now = orig }
+}
+
+func TestTemporalKeyringInsideWindow(t *testing.T) {
+	// A Wednesday afternoon
+	defer at(time.Date(2024, 5, 15, 14, 0, 0, 0, time.UTC))()
+
+	k := NewTemporalKeyring(&keyring.ArrayKeyring{}, businessHours)
+	if err := k.Set(keyring.Item{Key: "llamas"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := k.Get("llamas"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTemporalKeyringOutsideWindow(t *testing.T) {
+	// A Friday evening, so the window next opens on Monday morning
+	defer at(time.Date(2024, 5, 17, 18, 0, 0, 0, time.UTC))()
+
+	kr := keyring.NewArrayKeyring([]keyring.Item{{Key: "llamas"}})
+	k := NewTemporalKeyring(kr, businessHours)
+
+	_, err := k.Get("llamas")
+	denied, ok := err.(ErrAccessDenied)
+	if !ok {
+		t.Fatalf("Expected ErrAccessDenied, got: %v", err)
+	}
+	if expected := time.Date(2024, 5, 20, 9, 0, 0, 0, time.UTC); !denied.NextAllowed.Equal(expected) {
+		t.Fatalf("Expected next allowed time of %s, got %s", expected, denied.NextAllowed)
+	}
+
+	if err = k.Remove("llamas"); err == nil {
+		t.Fatal("Expected Remove to be denied")
+	}
+	if keys, err := k.Keys(); err != nil || len(keys) != 1 {
+		t.Fatalf("Expected Keys to be allowed, got: %v, %v", keys, err)
+	}
+}
+
+func TestScheduleSpanningMidnight(t *testing.T) {
+	nights := Schedule{
+		AllowedDays: []time.Weekday{time.Saturday},
+		AllowFrom:   time.Date(0, 1, 1, 22, 0, 0, 0, time.UTC),
+		AllowUntil:  time.Date(0, 1, 1, 2, 0, 0, 0, time.UTC),
+		Location:    time.UTC,
+	}
+
+	for _, tc := range []struct {
+		t       time.Time
+		allowed bool
+	}{
+		{time.Date(2024, 5, 18, 23, 0, 0, 0, time.UTC), true},  // Saturday night
+		{time.Date(2024, 5, 19, 1, 0, 0, 0, time.UTC), true},   // early Sunday, still Saturday's window
+		{time.Date(2024, 5, 19, 23, 0, 0, 0, time.UTC), false}, // Sunday night
+		{time.Date(2024, 5, 18, 1, 0, 0, 0, time.UTC), false},  // early Saturday, Friday's window
+	} {
+		if nights.Allowed(tc.t) != tc.allowed {
+			t.Fatalf("Expected Allowed(%s) to be %v", tc.t, tc.allowed)
+		}
+	}
+}
@@ -0,0 +1,268 @@
+package terraform
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/99designs/keyring"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	dsschema "github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// itemModel is the keyring_item data source and resource
+type itemModel struct {
+	Service     types.String `tfsdk:"service"`
+	Key         types.String `tfsdk:"key"`
+	Backend     types.String `tfsdk:"backend"`
+	Data        types.String `tfsdk:"data"`
+	Label       types.String `tfsdk:"label"`
+	Description types.String `tfsdk:"description"`
+}
+
+func (m *itemModel) keyring(k *keyrings) (keyring.Keyring, error) {
+	if k == nil {
+		return nil, fmt.Errorf("The keyring provider has not been configured")
+	}
+	return k.get(m.Service.ValueString(), m.Backend.ValueString())
+}
+
+// fill sets the model from item, leaving empty strings null so that unset optional
+// attributes don't show a difference
+func (m *itemModel) fill(item keyring.Item) {
+	m.Data = types.StringValue(string(item.Data))
+	m.Label = optionalString(item.Label)
+	m.Description = optionalString(item.Description)
+}
+
+func (m *itemModel) item() keyring.Item {
+	return keyring.Item{
+		Key:         m.Key.ValueString(),
+		Data:        []byte(m.Data.ValueString()),
+		Label:       m.Label.ValueString(),
+		Description: m.Description.ValueString(),
+	}
+}
+
+func optionalString(s string) types.String {
+	if s == "" {
+		return types.StringNull()
+	}
+	return types.StringValue(s)
+}
+
+func providerData(data any, diags *diag.Diagnostics) *keyrings {
+	if data == nil {
+		return nil
+	}
+	k, ok := data.(*keyrings)
+	if !ok {
+		diags.AddError("Unexpected provider data", fmt.Sprintf("Expected *keyrings, got %T", data))
+	}
+	return k
+}
+
+type itemDataSource struct {
+	keyrings *keyrings
+}
+
+func (d *itemDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_item"
+}
+
+func (d *itemDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = dsschema.Schema{
+		Description: "Reads an item from a keyring.",
+		Attributes: map[string]dsschema.Attribute{
+			"service": dsschema.StringAttribute{
+				Description: "Service the item belongs to. Defaults to the provider's service_name.",
+				Optional:    true,
+			},
+			"key": dsschema.StringAttribute{
+				Description: "Key of the item.",
+				Required:    true,
+			},
+			"backend": dsschema.StringAttribute{
+				Description: "Backend to read the item from. Defaults to the first available allowed backend.",
+				Optional:    true,
+			},
+			"data": dsschema.StringAttribute{
+				Description: "Secret data of the item.",
+				Computed:    true,
+				Sensitive:   true,
+			},
+			"label": dsschema.StringAttribute{
+				Description: "Label of the item.",
+				Computed:    true,
+			},
+			"description": dsschema.StringAttribute{
+				Description: "Description of the item.",
+				Computed:    true,
+			},
+		},
+	}
+}
+
+func (d *itemDataSource) Configure(_ context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	d.keyrings = providerData(req.ProviderData, &resp.Diagnostics)
+}
+
+func (d *itemDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var m itemModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &m)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	kr, err := m.keyring(d.keyrings)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to open keyring", err.Error())
+		return
+	}
+	item, err := kr.Get(m.Key.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(fmt.Sprintf("Failed to read item %q", m.Key.ValueString()), err.Error())
+		return
+	}
+
+	m.fill(item)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &m)...)
+}
+
+type itemResource struct {
+	keyrings *keyrings
+}
+
+func (r *itemResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_item"
+}
+
+func (r *itemResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	replace := []planmodifier.String{stringplanmodifier.RequiresReplace()}
+
+	resp.Schema = schema.Schema{
+		Description: "Manages an item in a keyring.",
+		Attributes: map[string]schema.Attribute{
+			"service": schema.StringAttribute{
+				Description:   "Service the item belongs to. Defaults to the provider's service_name.",
+				Optional:      true,
+				PlanModifiers: replace,
+			},
+			"key": schema.StringAttribute{
+				Description:   "Key of the item.",
+				Required:      true,
+				PlanModifiers: replace,
+			},
+			"backend": schema.StringAttribute{
+				Description:   "Backend to store the item in. Defaults to the first available allowed backend.",
+				Optional:      true,
+				PlanModifiers: replace,
+			},
+			"data": schema.StringAttribute{
+				Description: "Secret data of the item.",
+				Required:    true,
+				Sensitive:   true,
+			},
+			"label": schema.StringAttribute{
+				Description: "Label of the item.",
+				Optional:    true,
+			},
+			"description": schema.StringAttribute{
+				Description: "Description of the item.",
+				Optional:    true,
+			},
+		},
+	}
+}
+
+func (r *itemResource) Configure(_ context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	r.keyrings = providerData(req.ProviderData, &resp.Diagnostics)
+}
+
+// set stores the item described by m, as both Create and Update do
+func (r *itemResource) set(m *itemModel, diags *diag.Diagnostics) {
+	kr, err := m.keyring(r.keyrings)
+	if err != nil {
+		diags.AddError("Failed to open keyring", err.Error())
+		return
+	}
+	if err = kr.Set(m.item()); err != nil {
+		diags.AddError(fmt.Sprintf("Failed to store item %q", m.Key.ValueString()), err.Error())
+	}
+}
+
+func (r *itemResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var m itemModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &m)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.set(&m, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &m)...)
+}
+
+func (r *itemResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var m itemModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &m)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	kr, err := m.keyring(r.keyrings)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to open keyring", err.Error())
+		return
+	}
+	item, err := kr.Get(m.Key.ValueString())
+	if err == keyring.ErrKeyNotFound {
+		// The item was removed outside Terraform, so it needs to be created again
+		resp.State.RemoveResource(ctx)
+		return
+	} else if err != nil {
+		resp.Diagnostics.AddError(fmt.Sprintf("Failed to read item %q", m.Key.ValueString()), err.Error())
+		return
+	}
+
+	m.fill(item)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &m)...)
+}
+
+func (r *itemResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var m itemModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &m)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	r.set(&m, &resp.Diagnostics)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &m)...)
+}
+
+func (r *itemResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var m itemModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &m)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	kr, err := m.keyring(r.keyrings)
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to open keyring", err.Error())
+		return
+	}
+	if err = kr.Remove(m.Key.ValueString()); err != nil && err != keyring.ErrKeyNotFound {
+		resp.Diagnostics.AddError(fmt.Sprintf("Failed to remove item %q", m.Key.ValueString()), err.Error())
+	}
+}
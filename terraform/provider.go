@@ -0,0 +1,174 @@
+// Package terraform is a Terraform provider exposing keyring items, as the keyring_item
+// data source and resource.
+//
+// The provider block configures how keyrings are opened, either in HCL or by pointing
+// config_file at a YAML file read with keyring.LoadConfig:
+//
+//	provider "keyring" {
+//	  service_name     = "example"
+//	  allowed_backends = ["file"]
+//	  file_dir         = "~/.example-keyring"
+//	  file_password    = var.keyring_password
+//	}
+//
+// The provider binary is built from cmd/terraform-provider-keyring.
+package terraform
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/99designs/keyring"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/provider"
+	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// New returns a function creating the provider, for providerserver.Serve
+func New(version string) func() provider.Provider {
+	return func() provider.Provider {
+		return &keyringProvider{version: version}
+	}
+}
+
+type keyringProvider struct {
+	version string
+}
+
+type providerModel struct {
+	ConfigFile      types.String `tfsdk:"config_file"`
+	ServiceName     types.String `tfsdk:"service_name"`
+	AllowedBackends types.List   `tfsdk:"allowed_backends"`
+	FileDir         types.String `tfsdk:"file_dir"`
+	FilePassword    types.String `tfsdk:"file_password"`
+}
+
+func (p *keyringProvider) Metadata(_ context.Context, _ provider.MetadataRequest, resp *provider.MetadataResponse) {
+	resp.TypeName = "keyring"
+	resp.Version = p.version
+}
+
+func (p *keyringProvider) Schema(_ context.Context, _ provider.SchemaRequest, resp *provider.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Reads and writes items in a keyring such as the macOS Keychain, Secret Service or an encrypted file.",
+		Attributes: map[string]schema.Attribute{
+			"config_file": schema.StringAttribute{
+				Description: "YAML keyring configuration file. Other attributes override its settings.",
+				Optional:    true,
+			},
+			"service_name": schema.StringAttribute{
+				Description: "Service name used by items that don't set one.",
+				Optional:    true,
+			},
+			"allowed_backends": schema.ListAttribute{
+				Description: "Backends that may be used, in order of preference. Defaults to all available.",
+				ElementType: types.StringType,
+				Optional:    true,
+			},
+			"file_dir": schema.StringAttribute{
+				Description: "Directory of the file backend.",
+				Optional:    true,
+			},
+			"file_password": schema.StringAttribute{
+				Description: "Password of the file backend.",
+				Optional:    true,
+				Sensitive:   true,
+			},
+		},
+	}
+}
+
+func (p *keyringProvider) Configure(ctx context.Context, req provider.ConfigureRequest, resp *provider.ConfigureResponse) {
+	var m providerModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &m)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var cfg keyring.Config
+	if path := m.ConfigFile.ValueString(); path != "" {
+		var err error
+		if cfg, err = keyring.LoadConfig(path); err != nil {
+			resp.Diagnostics.AddError("Failed to load keyring config", err.Error())
+			return
+		}
+	}
+	if !m.ServiceName.IsNull() {
+		cfg.ServiceName = m.ServiceName.ValueString()
+	}
+	if !m.AllowedBackends.IsNull() {
+		var backends []string
+		resp.Diagnostics.Append(m.AllowedBackends.ElementsAs(ctx, &backends, false)...)
+		cfg.AllowedBackends = nil
+		for _, b := range backends {
+			cfg.AllowedBackends = append(cfg.AllowedBackends, keyring.BackendType(b))
+		}
+	}
+	if !m.FileDir.IsNull() {
+		cfg.FileDir = m.FileDir.ValueString()
+	}
+	if !m.FilePassword.IsNull() {
+		password := m.FilePassword.ValueString()
+		cfg.FilePasswordFunc = func(string) (string, error) {
+			return password, nil
+		}
+	}
+
+	keyrings := &keyrings{cfg: cfg, open: map[string]keyring.Keyring{}}
+	resp.DataSourceData = keyrings
+	resp.ResourceData = keyrings
+}
+
+func (p *keyringProvider) DataSources(_ context.Context) []func() datasource.DataSource {
+	return []func() datasource.DataSource{
+		func() datasource.DataSource { return &itemDataSource{} },
+	}
+}
+
+func (p *keyringProvider) Resources(_ context.Context) []func() resource.Resource {
+	return []func() resource.Resource{
+		func() resource.Resource { return &itemResource{} },
+	}
+}
+
+// keyrings opens keyrings for each service and backend used, reusing them between
+// operations
+type keyrings struct {
+	cfg keyring.Config
+
+	mu   sync.Mutex
+	open map[string]keyring.Keyring
+}
+
+// get returns the keyring for service, restricted to backend if it's set. An empty service
+// means the provider's service_name.
+func (k *keyrings) get(service string, backend string) (keyring.Keyring, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	cfg := k.cfg
+	if service != "" {
+		cfg.ServiceName = service
+	}
+	if cfg.ServiceName == "" {
+		return nil, fmt.Errorf("No service set on the item or the provider")
+	}
+	if backend != "" {
+		cfg.AllowedBackends = []keyring.BackendType{keyring.BackendType(backend)}
+	}
+
+	id := cfg.ServiceName + "\x00" + backend
+	if kr, ok := k.open[id]; ok {
+		return kr, nil
+	}
+
+	kr, err := keyring.Open(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to open keyring for service %q: %v", cfg.ServiceName, err)
+	}
+	k.open[id] = kr
+	return kr, nil
+}
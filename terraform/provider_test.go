@@ -0,0 +1,173 @@
+package terraform
+
+import (
+	"context"
+	"testing"
+
+	"github.com/99designs/keyring"
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+var (
+	providerType = tftypes.Object{AttributeTypes: map[string]tftypes.Type{
+		"config_file":      tftypes.String,
+		"service_name":     tftypes.String,
+		"allowed_backends": tftypes.List{ElementType: tftypes.String},
+		"file_dir":         tftypes.String,
+		"file_password":    tftypes.String,
+	}}
+	itemType = tftypes.Object{AttributeTypes: map[string]tftypes.Type{
+		"service":     tftypes.String,
+		"key":         tftypes.String,
+		"backend":     tftypes.String,
+		"data":        tftypes.String,
+		"label":       tftypes.String,
+		"description": tftypes.String,
+	}}
+)
+
+func dynamicValue(t *testing.T, typ tftypes.Type, val tftypes.Value) *tfprotov6.DynamicValue {
+	dv, err := tfprotov6.NewDynamicValue(typ, val)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &dv
+}
+
+func itemValue(key string, data interface{}) tftypes.Value {
+	return tftypes.NewValue(itemType, map[string]tftypes.Value{
+		"service":     tftypes.NewValue(tftypes.String, nil),
+		"key":         tftypes.NewValue(tftypes.String, key),
+		"backend":     tftypes.NewValue(tftypes.String, "file"),
+		"data":        tftypes.NewValue(tftypes.String, data),
+		"label":       tftypes.NewValue(tftypes.String, nil),
+		"description": tftypes.NewValue(tftypes.String, nil),
+	})
+}
+
+func checkDiagnostics(t *testing.T, diags []*tfprotov6.Diagnostic) {
+	t.Helper()
+	for _, d := range diags {
+		if d.Severity == tfprotov6.DiagnosticSeverityError {
+			t.Fatalf("%s: %s", d.Summary, d.Detail)
+		}
+	}
+}
+
+func configuredServer(t *testing.T, dir string) tfprotov6.ProviderServer {
+	srv := providerserver.NewProtocol6(New("test")())()
+
+	// Terraform always fetches the schemas first
+	schemas, err := srv.GetProviderSchema(context.Background(), &tfprotov6.GetProviderSchemaRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkDiagnostics(t, schemas.Diagnostics)
+
+	resp, err := srv.ConfigureProvider(context.Background(), &tfprotov6.ConfigureProviderRequest{
+		Config: dynamicValue(t, providerType, tftypes.NewValue(providerType, map[string]tftypes.Value{
+			"config_file":      tftypes.NewValue(tftypes.String, nil),
+			"service_name":     tftypes.NewValue(tftypes.String, "test"),
+			"allowed_backends": tftypes.NewValue(tftypes.List{ElementType: tftypes.String}, nil),
+			"file_dir":         tftypes.NewValue(tftypes.String, dir),
+			"file_password":    tftypes.NewValue(tftypes.String, "no more secrets"),
+		})),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkDiagnostics(t, resp.Diagnostics)
+	return srv
+}
+
+func TestItemDataSource(t *testing.T) {
+	dir := t.TempDir()
+	kr, err := keyring.Open(keyring.Config{
+		AllowedBackends:  []keyring.BackendType{keyring.FileBackend},
+		ServiceName:      "test",
+		FileDir:          dir,
+		FilePasswordFunc: func(string) (string, error) { return "no more secrets", nil },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = kr.Set(keyring.Item{Key: "llamas", Data: []byte("llamas are great"), Label: "Llamas"})
+
+	srv := configuredServer(t, dir)
+	resp, err := srv.ReadDataSource(context.Background(), &tfprotov6.ReadDataSourceRequest{
+		TypeName: "keyring_item",
+		Config:   dynamicValue(t, itemType, itemValue("llamas", nil)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkDiagnostics(t, resp.Diagnostics)
+
+	state, err := resp.State.Unmarshal(itemType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var attrs map[string]tftypes.Value
+	_ = state.As(&attrs)
+	var data, label string
+	_ = attrs["data"].As(&data)
+	_ = attrs["label"].As(&label)
+	if data != "llamas are great" || label != "Llamas" {
+		t.Fatalf("Unexpected data %q and label %q", data, label)
+	}
+}
+
+func TestItemResource(t *testing.T) {
+	dir := t.TempDir()
+	srv := configuredServer(t, dir)
+	ctx := context.Background()
+
+	planned := itemValue("alpacas", "alpacas are great")
+	applied, err := srv.ApplyResourceChange(ctx, &tfprotov6.ApplyResourceChangeRequest{
+		TypeName:     "keyring_item",
+		PriorState:   dynamicValue(t, itemType, tftypes.NewValue(itemType, nil)),
+		PlannedState: dynamicValue(t, itemType, planned),
+		Config:       dynamicValue(t, itemType, planned),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkDiagnostics(t, applied.Diagnostics)
+
+	kr, err := keyring.Open(keyring.Config{
+		AllowedBackends:  []keyring.BackendType{keyring.FileBackend},
+		FileDir:          dir,
+		FilePasswordFunc: func(string) (string, error) { return "no more secrets", nil },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	item, err := kr.Get("alpacas")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(item.Data) != "alpacas are great" {
+		t.Fatalf("Unexpected data %q", item.Data)
+	}
+
+	// Removing the item outside Terraform removes it from the state when it's refreshed
+	_ = kr.Remove("alpacas")
+	read, err := srv.ReadResource(ctx, &tfprotov6.ReadResourceRequest{
+		TypeName:     "keyring_item",
+		CurrentState: applied.NewState,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkDiagnostics(t, read.Diagnostics)
+
+	state, err := read.NewState.Unmarshal(itemType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !state.IsNull() {
+		t.Fatalf("Expected the resource to be removed from the state, got %v", state)
+	}
+}
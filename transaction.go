@@ -0,0 +1,130 @@
+package keyring
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrTransactionDone is returned when a transaction is used after Commit or Rollback
+var ErrTransactionDone = errors.New("The transaction has already been committed or rolled back")
+
+// Transaction groups writes to a keyring so that they are applied together on Commit, or
+// not at all
+type Transaction interface {
+	Set(item Item) error
+	Remove(key string) error
+	Commit() error
+	Rollback() error
+}
+
+// Transactioner is implemented by backends with native support for transactions
+type Transactioner interface {
+	BeginTransaction() (Transaction, error)
+}
+
+// BeginTransaction starts a transaction on kr. Backends implementing Transactioner use their
+// native transactions. For all others, writes are buffered until Commit, which applies them
+// in order. If one of them fails, the writes already applied are undone by restoring the
+// previous values, and the original error is returned.
+func BeginTransaction(kr Keyring) (Transaction, error) {
+	if t, ok := kr.(Transactioner); ok {
+		return t.BeginTransaction()
+	}
+	return &bufferedTransaction{kr: kr}, nil
+}
+
+type transactionOp struct {
+	key    string
+	item   Item
+	remove bool
+}
+
+// transactionUndo restores a key to how it was before an operation was applied
+type transactionUndo struct {
+	key     string
+	prev    Item
+	existed bool
+}
+
+type bufferedTransaction struct {
+	kr   Keyring
+	ops  []transactionOp
+	done bool
+}
+
+func (t *bufferedTransaction) Set(item Item) error {
+	if t.done {
+		return ErrTransactionDone
+	}
+	t.ops = append(t.ops, transactionOp{key: item.Key, item: item})
+	return nil
+}
+
+func (t *bufferedTransaction) Remove(key string) error {
+	if t.done {
+		return ErrTransactionDone
+	}
+	t.ops = append(t.ops, transactionOp{key: key, remove: true})
+	return nil
+}
+
+func (t *bufferedTransaction) Rollback() error {
+	if t.done {
+		return ErrTransactionDone
+	}
+	t.done = true
+	t.ops = nil
+	return nil
+}
+
+func (t *bufferedTransaction) Commit() error {
+	if t.done {
+		return ErrTransactionDone
+	}
+	t.done = true
+
+	var undo []transactionUndo
+	for _, op := range t.ops {
+		prev, err := t.kr.Get(op.key)
+		if err != nil && err != ErrKeyNotFound {
+			return t.undo(undo, err)
+		}
+		u := transactionUndo{key: op.key, prev: prev, existed: err == nil}
+
+		if op.remove {
+			err = t.kr.Remove(op.key)
+		} else {
+			err = t.kr.Set(op.item)
+		}
+		if err != nil {
+			return t.undo(undo, err)
+		}
+		undo = append(undo, u)
+	}
+
+	return nil
+}
+
+// undo reverts applied operations, most recent first, and returns cause along with any
+// keys that couldn't be restored
+func (t *bufferedTransaction) undo(undo []transactionUndo, cause error) error {
+	var failed []string
+	for idx := len(undo) - 1; idx >= 0; idx-- {
+		u := undo[idx]
+		var err error
+		if u.existed {
+			err = t.kr.Set(u.prev)
+		} else {
+			err = t.kr.Remove(u.key)
+		}
+		if err != nil && err != ErrKeyNotFound {
+			Debugf("Failed to restore %q after failed commit: %v", u.key, err)
+			failed = append(failed, u.key)
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("Transaction failed: %v (and restoring %q also failed)", cause, failed)
+	}
+	return cause
+}
@@ -0,0 +1,86 @@
+package keyring
+
+import (
+	"errors"
+	"testing"
+)
+
+type failingSetKeyring struct {
+	*ArrayKeyring
+	failKey string
+}
+
+func (k failingSetKeyring) Set(item Item) error {
+	if item.Key == k.failKey {
+		return errors.New("llamas refused")
+	}
+	return k.ArrayKeyring.Set(item)
+}
+
+func TestTransactionCommit(t *testing.T) {
+	k := NewArrayKeyring([]Item{{Key: "old-token", Data: []byte("old")}})
+
+	tx, err := BeginTransaction(k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = tx.Set(Item{Key: "new-token", Data: []byte("new")})
+	_ = tx.Remove("old-token")
+
+	if _, err = k.Get("new-token"); err != ErrKeyNotFound {
+		t.Fatal("Expected writes to be buffered until commit")
+	}
+
+	if err = tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = k.Get("new-token"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = k.Get("old-token"); err != ErrKeyNotFound {
+		t.Fatal("Expected old-token to be removed")
+	}
+
+	if err = tx.Set(Item{Key: "another"}); err != ErrTransactionDone {
+		t.Fatalf("Expected ErrTransactionDone, got: %v", err)
+	}
+}
+
+func TestTransactionRollback(t *testing.T) {
+	k := &ArrayKeyring{}
+
+	tx, _ := BeginTransaction(k)
+	_ = tx.Set(Item{Key: "llamas", Data: []byte("llamas are great")})
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := k.Get("llamas"); err != ErrKeyNotFound {
+		t.Fatal("Expected rolled back write not to be applied")
+	}
+}
+
+func TestTransactionCommitFailureRestores(t *testing.T) {
+	backing := NewArrayKeyring([]Item{{Key: "llamas", Data: []byte("original")}})
+	k := failingSetKeyring{ArrayKeyring: backing, failKey: "alpacas"}
+
+	tx, _ := BeginTransaction(k)
+	_ = tx.Set(Item{Key: "llamas", Data: []byte("updated")})
+	_ = tx.Set(Item{Key: "vicunas", Data: []byte("new")})
+	_ = tx.Set(Item{Key: "alpacas", Data: []byte("fails")})
+
+	if err := tx.Commit(); err == nil {
+		t.Fatal("Expected commit to fail")
+	}
+
+	item, err := backing.Get("llamas")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(item.Data) != "original" {
+		t.Fatalf("Expected llamas to be restored, got: %q", item.Data)
+	}
+	if _, err = backing.Get("vicunas"); err != ErrKeyNotFound {
+		t.Fatal("Expected vicunas to be removed again")
+	}
+}
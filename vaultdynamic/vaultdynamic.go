@@ -0,0 +1,275 @@
+// Package vaultdynamic exposes HashiCorp Vault dynamic secrets, such as database
+// credentials, through the keyring.Keyring interface.
+//
+// Keys name a secrets engine mount and role, so the key "database/readonly" reads
+// credentials from database/creds/readonly. Credentials are generated on the first Get and
+// their lease is renewed in the background until it is revoked with Remove, reaches its
+// maximum TTL, or Close is called.
+package vaultdynamic
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/99designs/keyring"
+)
+
+// ErrReadOnly is returned by Set, as dynamic secrets are generated by Vault
+var ErrReadOnly = keyring.ErrReadOnly
+
+// Config configures access to Vault
+type Config struct {
+	// Address is the Vault server address, defaulting to $VAULT_ADDR
+	Address string
+	// Token is the Vault token, defaulting to $VAULT_TOKEN
+	Token string
+	// Client is the HTTP client used for requests, defaulting to http.DefaultClient
+	Client *http.Client
+}
+
+// VaultDynamicBackend is a read-only keyring of Vault dynamic secrets
+type VaultDynamicBackend struct {
+	address string
+	token   string
+	client  *http.Client
+
+	mu     sync.Mutex
+	leases map[string]*lease
+}
+
+type lease struct {
+	id      string
+	item    keyring.Item
+	created time.Time
+	stop    chan struct{}
+}
+
+// secretResponse is the part of Vault's response to reading or renewing a secret that we need
+type secretResponse struct {
+	LeaseID       string                 `json:"lease_id"`
+	LeaseDuration int                    `json:"lease_duration"`
+	Renewable     bool                   `json:"renewable"`
+	Data          map[string]interface{} `json:"data"`
+}
+
+// New returns a VaultDynamicBackend for the Vault server described by cfg
+func New(cfg Config) (*VaultDynamicBackend, error) {
+	b := &VaultDynamicBackend{
+		address: cfg.Address,
+		token:   cfg.Token,
+		client:  cfg.Client,
+		leases:  map[string]*lease{},
+	}
+	if b.address == "" {
+		b.address = os.Getenv("VAULT_ADDR")
+	}
+	if b.token == "" {
+		b.token = os.Getenv("VAULT_TOKEN")
+	}
+	if b.client == nil {
+		b.client = http.DefaultClient
+	}
+
+	if b.address == "" {
+		return nil, errors.New("No Vault address provided")
+	}
+	if b.token == "" {
+		return nil, errors.New("No Vault token provided")
+	}
+
+	return b, nil
+}
+
+func (b *VaultDynamicBackend) request(method, urlPath string, body interface{}) (*secretResponse, error) {
+	var reqBody io.Reader
+	if body != nil {
+		buf, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reqBody = bytes.NewReader(buf)
+	}
+
+	req, err := http.NewRequest(method, strings.TrimRight(b.address, "/")+"/v1/"+urlPath, reqBody)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", b.token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, keyring.ErrKeyNotFound
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("Vault returned %s for %s: %s", resp.Status, urlPath, strings.TrimSpace(string(msg)))
+	}
+
+	var secret secretResponse
+	if resp.StatusCode != http.StatusNoContent {
+		if err = json.NewDecoder(resp.Body).Decode(&secret); err != nil {
+			return nil, err
+		}
+	}
+	return &secret, nil
+}
+
+// Get returns the credentials for key as a JSON object, generating them if there isn't
+// already a live lease for key
+func (b *VaultDynamicBackend) Get(key string) (keyring.Item, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if l, ok := b.leases[key]; ok {
+		return l.item, nil
+	}
+
+	mount, role := path.Dir(key), path.Base(key)
+	if mount == "." || role == "" {
+		return keyring.Item{}, fmt.Errorf("Key %q must be of the form <mount>/<role>", key)
+	}
+
+	secret, err := b.request(http.MethodGet, mount+"/creds/"+role, nil)
+	if err != nil {
+		return keyring.Item{}, err
+	}
+
+	data, err := json.Marshal(secret.Data)
+	if err != nil {
+		return keyring.Item{}, err
+	}
+
+	l := &lease{
+		id: secret.LeaseID,
+		item: keyring.Item{
+			Key:   key,
+			Data:  data,
+			Label: secret.LeaseID,
+		},
+		created: time.Now(),
+		stop:    make(chan struct{}),
+	}
+	b.leases[key] = l
+	keyring.Debugf("Generated credentials for %q with lease %s", key, l.id)
+
+	if secret.Renewable && secret.LeaseDuration > 0 {
+		go b.renew(key, l, secret.LeaseDuration)
+	}
+
+	return l.item, nil
+}
+
+// renew keeps l alive, renewing it after two thirds of its duration has passed. If renewal
+// fails the lease is forgotten, so that the next Get generates new credentials.
+func (b *VaultDynamicBackend) renew(key string, l *lease, duration int) {
+	for {
+		select {
+		case <-time.After(time.Duration(duration) * time.Second * 2 / 3):
+		case <-l.stop:
+			return
+		}
+
+		secret, err := b.request(http.MethodPut, "sys/leases/renew", map[string]interface{}{
+			"lease_id":  l.id,
+			"increment": duration,
+		})
+		if err == nil && secret.LeaseDuration > 0 {
+			keyring.Debugf("Renewed lease %s for %ds", l.id, secret.LeaseDuration)
+			duration = secret.LeaseDuration
+			continue
+		}
+
+		keyring.Debugf("Failed to renew lease %s: %v", l.id, err)
+		b.mu.Lock()
+		if b.leases[key] == l {
+			delete(b.leases, key)
+		}
+		b.mu.Unlock()
+		return
+	}
+}
+
+// GetMetadata returns when the credentials for key were generated, labelled with their
+// lease ID
+func (b *VaultDynamicBackend) GetMetadata(key string) (keyring.Metadata, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	l, ok := b.leases[key]
+	if !ok {
+		return keyring.Metadata{}, keyring.ErrKeyNotFound
+	}
+
+	return keyring.Metadata{
+		Item:             &keyring.Item{Key: key, Label: l.id},
+		ModificationTime: l.created,
+	}, nil
+}
+
+// Set returns ErrReadOnly
+func (b *VaultDynamicBackend) Set(_ keyring.Item) error {
+	return ErrReadOnly
+}
+
+// Remove revokes the lease on the credentials for key
+func (b *VaultDynamicBackend) Remove(key string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	l, ok := b.leases[key]
+	if !ok {
+		return keyring.ErrKeyNotFound
+	}
+
+	_, err := b.request(http.MethodPut, "sys/leases/revoke", map[string]interface{}{
+		"lease_id": l.id,
+	})
+	if err != nil {
+		return err
+	}
+
+	close(l.stop)
+	delete(b.leases, key)
+	return nil
+}
+
+// Keys lists the keys that currently have live leases
+func (b *VaultDynamicBackend) Keys() ([]string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var keys = []string{}
+	for key := range b.leases {
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// Close stops renewing leases. Leases aren't revoked, and expire at the end of their TTL.
+func (b *VaultDynamicBackend) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for key, l := range b.leases {
+		close(l.stop)
+		delete(b.leases, key)
+	}
+	return nil
+}
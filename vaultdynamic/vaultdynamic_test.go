@@ -0,0 +1,126 @@
+package vaultdynamic
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/99designs/keyring"
+)
+
+type fakeVault struct {
+	mu      sync.Mutex
+	issued  int
+	revoked []string
+}
+
+func (v *fakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("X-Vault-Token") != "s.llamas" {
+		http.Error(w, "permission denied", http.StatusForbidden)
+		return
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	switch r.URL.Path {
+	case "/v1/database/creds/readonly":
+		v.issued++
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"lease_id":       "database/creds/readonly/abc123",
+			"lease_duration": 3600,
+			"renewable":      true,
+			"data":           map[string]string{"username": "llama", "password": "alpaca"},
+		})
+	case "/v1/sys/leases/revoke":
+		var body struct {
+			LeaseID string `json:"lease_id"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		v.revoked = append(v.revoked, body.LeaseID)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func newTestBackend(t *testing.T) (*VaultDynamicBackend, *fakeVault) {
+	vault := &fakeVault{}
+	srv := httptest.NewServer(vault)
+	t.Cleanup(srv.Close)
+
+	b, err := New(Config{Address: srv.URL, Token: "s.llamas"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = b.Close() })
+
+	return b, vault
+}
+
+func TestGetGeneratesCredentialsOnce(t *testing.T) {
+	b, vault := newTestBackend(t)
+
+	for i := 0; i < 2; i++ {
+		item, err := b.Get("database/readonly")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var creds map[string]string
+		if err = json.Unmarshal(item.Data, &creds); err != nil {
+			t.Fatal(err)
+		}
+		if creds["username"] != "llama" || creds["password"] != "alpaca" {
+			t.Fatalf("Unexpected credentials: %v", creds)
+		}
+	}
+
+	if vault.issued != 1 {
+		t.Fatalf("Expected credentials to be generated once, got %d", vault.issued)
+	}
+
+	keys, err := b.Keys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 1 || keys[0] != "database/readonly" {
+		t.Fatalf("Unexpected keys: %v", keys)
+	}
+}
+
+func TestGetUnknownRole(t *testing.T) {
+	b, _ := newTestBackend(t)
+
+	if _, err := b.Get("database/admin"); err != keyring.ErrKeyNotFound {
+		t.Fatalf("Expected ErrKeyNotFound, got: %v", err)
+	}
+}
+
+func TestRemoveRevokesLease(t *testing.T) {
+	b, vault := newTestBackend(t)
+
+	if _, err := b.Get("database/readonly"); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Remove("database/readonly"); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(vault.revoked) != 1 || vault.revoked[0] != "database/creds/readonly/abc123" {
+		t.Fatalf("Unexpected revocations: %v", vault.revoked)
+	}
+	if err := b.Remove("database/readonly"); err != keyring.ErrKeyNotFound {
+		t.Fatalf("Expected ErrKeyNotFound, got: %v", err)
+	}
+}
+
+func TestSetIsReadOnly(t *testing.T) {
+	b, _ := newTestBackend(t)
+
+	if err := b.Set(keyring.Item{Key: "database/readonly"}); err != ErrReadOnly {
+		t.Fatalf("Expected ErrReadOnly, got: %v", err)
+	}
+}
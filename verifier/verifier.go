@@ -0,0 +1,113 @@
+// Package verifier checks that every item on a keyring can still be read, such as after
+// the items of the file backend have been re-encrypted with a new password.
+package verifier
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/99designs/keyring"
+)
+
+// VerifyOptions controls how VerifyAll reads items
+type VerifyOptions struct {
+	// Concurrency is the most items read at once. Zero means keyring.DefaultBulkConcurrency.
+	Concurrency int
+}
+
+// Failure is an item that couldn't be verified
+type Failure struct {
+	Key string
+	Err error
+}
+
+// VerifyResult is the outcome of VerifyAll
+type VerifyResult struct {
+	// Checked is the number of items read
+	Checked int
+	// Failed are the items that couldn't be verified, sorted by key
+	Failed []Failure
+}
+
+// OK reports whether every item was verified
+func (r VerifyResult) OK() bool {
+	return len(r.Failed) == 0
+}
+
+// VerifyAll reads every item listed by keyring.IndexMetadata, or by Keys for backends that
+// need credentials for metadata. An item fails if Get returns an error, including
+// keyring.ErrKeyNotFound and keyring.ErrCorrupted, or if it has a Checksum that doesn't
+// match its data, which fails with a *keyring.ErrChecksumMismatch. Items that aren't
+// active yet have been read successfully, so they pass. The error is only for failing to
+// list the items.
+func VerifyAll(kr keyring.Keyring, opts VerifyOptions) (VerifyResult, error) {
+	keys, err := listKeys(kr)
+	if err != nil {
+		return VerifyResult{}, err
+	}
+
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = keyring.DefaultBulkConcurrency
+	}
+
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		result = VerifyResult{Failed: []Failure{}}
+		sem    = make(chan struct{}, concurrency)
+	)
+	for _, key := range keys {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(key string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			err := verify(kr, key)
+
+			mu.Lock()
+			defer mu.Unlock()
+			result.Checked++
+			if err != nil {
+				keyring.Debugf("Failed to verify %q: %v", key, err)
+				result.Failed = append(result.Failed, Failure{Key: key, Err: err})
+			}
+		}(key)
+	}
+	wg.Wait()
+
+	sort.Slice(result.Failed, func(i, j int) bool {
+		return result.Failed[i].Key < result.Failed[j].Key
+	})
+	return result, nil
+}
+
+func listKeys(kr keyring.Keyring) ([]string, error) {
+	index, err := keyring.IndexMetadata(kr)
+	if err == keyring.ErrMetadataNeedsCredentials {
+		return kr.Keys()
+	} else if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(index))
+	for key := range index {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func verify(kr keyring.Keyring, key string) error {
+	item, err := kr.Get(key)
+	if err == keyring.ErrNotYetActive {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	return keyring.VerifyChecksum(item)
+}
@@ -0,0 +1,81 @@
+package verifier
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/99designs/keyring"
+)
+
+// brokenKeyring lists keys that it then fails to Get
+type brokenKeyring struct {
+	keyring.Keyring
+	errs map[string]error
+}
+
+func (k *brokenKeyring) Get(key string) (keyring.Item, error) {
+	if err, ok := k.errs[key]; ok {
+		return keyring.Item{}, err
+	}
+	return k.Keyring.Get(key)
+}
+
+func (k *brokenKeyring) Keys() ([]string, error) {
+	keys, err := k.Keyring.Keys()
+	for key := range k.errs {
+		keys = append(keys, key)
+	}
+	return keys, err
+}
+
+func TestVerifyAll(t *testing.T) {
+	sum := sha256.Sum256([]byte("llamas are great"))
+	kr := &brokenKeyring{
+		Keyring: keyring.NewArrayKeyring([]keyring.Item{
+			{Key: "llamas", Data: []byte("llamas are great"), Checksum: hex.EncodeToString(sum[:])},
+			{Key: "alpacas", Data: []byte("alpacas are great"), Checksum: hex.EncodeToString(sum[:])},
+			{Key: "camels", Data: []byte("camels are great")},
+			{Key: "later", Data: []byte("not yet"), NotBefore: time.Now().Add(time.Hour)},
+		}),
+		errs: map[string]error{
+			"corrupted": keyring.ErrCorrupted,
+			"missing":   keyring.ErrKeyNotFound,
+		},
+	}
+
+	result, err := VerifyAll(kr, VerifyOptions{Concurrency: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Checked != 6 {
+		t.Fatalf("Expected 6 items checked, got %d", result.Checked)
+	}
+	if result.OK() || len(result.Failed) != 3 {
+		t.Fatalf("Expected 3 failures, got %+v", result.Failed)
+	}
+
+	var mismatch *keyring.ErrChecksumMismatch
+	if f := result.Failed[0]; f.Key != "alpacas" || !errors.As(f.Err, &mismatch) {
+		t.Fatalf("Expected a checksum mismatch for alpacas, got %+v", f)
+	}
+	if f := result.Failed[1]; f.Key != "corrupted" || !errors.Is(f.Err, keyring.ErrCorrupted) {
+		t.Fatalf("Expected corrupted to fail with ErrCorrupted, got %+v", f)
+	}
+	if f := result.Failed[2]; f.Key != "missing" || f.Err != keyring.ErrKeyNotFound {
+		t.Fatalf("Expected missing to fail with ErrKeyNotFound, got %+v", f)
+	}
+}
+
+func TestVerifyAllEmpty(t *testing.T) {
+	result, err := VerifyAll(keyring.NewArrayKeyring(nil), VerifyOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.OK() || result.Checked != 0 {
+		t.Fatalf("Unexpected result: %+v", result)
+	}
+}
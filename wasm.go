@@ -0,0 +1,361 @@
+//go:build js && wasm
+// +build js,wasm
+
+package keyring
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"syscall/js"
+	"time"
+)
+
+const (
+	wasmDefaultDatabase = "keyring"
+	wasmObjectStore     = "items"
+
+	// wasmPBKDF2Iterations follows the OWASP recommendation for PBKDF2-HMAC-SHA256
+	wasmPBKDF2Iterations = 600000
+)
+
+func init() {
+	supportedBackends[WASMBackend] = opener(func(cfg Config) (Keyring, error) {
+		if js.Global().Get("indexedDB").IsUndefined() {
+			return nil, errors.New("IndexedDB is not available")
+		}
+		if js.Global().Get("crypto").Get("subtle").IsUndefined() {
+			return nil, errors.New("The Web Crypto API is not available")
+		}
+
+		k := &wasmKeyring{
+			database:     cfg.WASMDatabaseName,
+			service:      cfg.ServiceName,
+			passwordFunc: cfg.WASMPasswordFunc,
+		}
+		if k.database == "" {
+			k.database = wasmDefaultDatabase
+		}
+		if k.passwordFunc == nil {
+			return nil, errors.New("No WASMPasswordFunc provided")
+		}
+
+		return k, nil
+	})
+}
+
+// wasmKeyring stores items in the browser's IndexedDB, encrypted with AES-256-GCM using the
+// Web Crypto API. Each item has its own random salt, from which its key is derived from the
+// passphrase with PBKDF2.
+//
+// Calls block until the browser completes the underlying promises, so they must not be
+// made from within a js.Func callback.
+type wasmKeyring struct {
+	database     string
+	service      string
+	passwordFunc PromptFunc
+	password     string
+	db           js.Value
+}
+
+// wasmRecord is what is stored in IndexedDB for each item
+type wasmRecord struct {
+	salt     []byte
+	iv       []byte
+	data     []byte
+	modified time.Time
+}
+
+// await blocks until the promise p settles, returning its value or rejection
+func await(p js.Value) (js.Value, error) {
+	resultCh := make(chan js.Value, 1)
+	errCh := make(chan error, 1)
+
+	onResolve := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		resultCh <- args[0]
+		return nil
+	})
+	defer onResolve.Release()
+	onReject := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		errCh <- jsError(args[0])
+		return nil
+	})
+	defer onReject.Release()
+
+	p.Call("then", onResolve, onReject)
+
+	select {
+	case result := <-resultCh:
+		return result, nil
+	case err := <-errCh:
+		return js.Undefined(), err
+	}
+}
+
+// awaitRequest blocks until the IndexedDB request req completes, returning its result
+func awaitRequest(req js.Value) (js.Value, error) {
+	resultCh := make(chan js.Value, 1)
+	errCh := make(chan error, 1)
+
+	onSuccess := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		resultCh <- req.Get("result")
+		return nil
+	})
+	defer onSuccess.Release()
+	onError := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		errCh <- jsError(req.Get("error"))
+		return nil
+	})
+	defer onError.Release()
+
+	req.Set("onsuccess", onSuccess)
+	req.Set("onerror", onError)
+
+	select {
+	case result := <-resultCh:
+		return result, nil
+	case err := <-errCh:
+		return js.Undefined(), err
+	}
+}
+
+func jsError(v js.Value) error {
+	if v.IsUndefined() || v.IsNull() {
+		return errors.New("Unknown JavaScript error")
+	}
+	if msg := v.Get("message"); msg.Type() == js.TypeString {
+		return errors.New(msg.String())
+	}
+	return errors.New(v.Call("toString").String())
+}
+
+func bytesToJS(b []byte) js.Value {
+	arr := js.Global().Get("Uint8Array").New(len(b))
+	js.CopyBytesToJS(arr, b)
+	return arr
+}
+
+func bytesFromJS(v js.Value) []byte {
+	arr := js.Global().Get("Uint8Array").New(v)
+	b := make([]byte, arr.Get("length").Int())
+	js.CopyBytesToGo(b, arr)
+	return b
+}
+
+func (k *wasmKeyring) open() (js.Value, error) {
+	if k.db.Truthy() {
+		return k.db, nil
+	}
+
+	req := js.Global().Get("indexedDB").Call("open", k.database, 1)
+	onUpgrade := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		req.Get("result").Call("createObjectStore", wasmObjectStore)
+		return nil
+	})
+	defer onUpgrade.Release()
+	req.Set("onupgradeneeded", onUpgrade)
+
+	db, err := awaitRequest(req)
+	if err != nil {
+		return js.Undefined(), fmt.Errorf("Failed to open IndexedDB database %s: %v", k.database, err)
+	}
+
+	k.db = db
+	return db, nil
+}
+
+func (k *wasmKeyring) store(mode string) (js.Value, error) {
+	db, err := k.open()
+	if err != nil {
+		return js.Undefined(), err
+	}
+	return db.Call("transaction", wasmObjectStore, mode).Call("objectStore", wasmObjectStore), nil
+}
+
+func (k *wasmKeyring) id(key string) string {
+	return k.service + "/" + key
+}
+
+func (k *wasmKeyring) unlock() error {
+	if k.password != "" {
+		return nil
+	}
+
+	pwd, err := k.passwordFunc(fmt.Sprintf("Enter passphrase to unlock %s", k.database))
+	if err != nil {
+		return err
+	}
+	k.password = pwd
+	return nil
+}
+
+// deriveKey derives an AES-256-GCM key from the passphrase and salt using PBKDF2
+func (k *wasmKeyring) deriveKey(salt []byte) (js.Value, error) {
+	if err := k.unlock(); err != nil {
+		return js.Undefined(), err
+	}
+
+	subtle := js.Global().Get("crypto").Get("subtle")
+	baseKey, err := await(subtle.Call("importKey", "raw", bytesToJS([]byte(k.password)),
+		"PBKDF2", false, []interface{}{"deriveKey"}))
+	if err != nil {
+		return js.Undefined(), err
+	}
+
+	return await(subtle.Call("deriveKey",
+		map[string]interface{}{
+			"name":       "PBKDF2",
+			"salt":       bytesToJS(salt),
+			"iterations": wasmPBKDF2Iterations,
+			"hash":       "SHA-256",
+		},
+		baseKey,
+		map[string]interface{}{"name": "AES-GCM", "length": 256},
+		false,
+		[]interface{}{"encrypt", "decrypt"},
+	))
+}
+
+func (k *wasmKeyring) getRecord(key string) (wasmRecord, error) {
+	store, err := k.store("readonly")
+	if err != nil {
+		return wasmRecord{}, err
+	}
+
+	v, err := awaitRequest(store.Call("get", k.id(key)))
+	if err != nil {
+		return wasmRecord{}, err
+	}
+	if v.IsUndefined() {
+		return wasmRecord{}, ErrKeyNotFound
+	}
+
+	return wasmRecord{
+		salt:     bytesFromJS(v.Get("salt")),
+		iv:       bytesFromJS(v.Get("iv")),
+		data:     bytesFromJS(v.Get("data")),
+		modified: time.UnixMilli(int64(v.Get("modified").Float())),
+	}, nil
+}
+
+func (k *wasmKeyring) Get(key string) (Item, error) {
+	rec, err := k.getRecord(key)
+	if err != nil {
+		return Item{}, WrapError(err, WASMBackend, k.service, key)
+	}
+
+	cryptoKey, err := k.deriveKey(rec.salt)
+	if err != nil {
+		return Item{}, WrapError(err, WASMBackend, k.service, key)
+	}
+
+	plaintext, err := await(js.Global().Get("crypto").Get("subtle").Call("decrypt",
+		map[string]interface{}{"name": "AES-GCM", "iv": bytesToJS(rec.iv)},
+		cryptoKey, bytesToJS(rec.data)))
+	if err != nil {
+		return Item{}, fmt.Errorf("Failed to decrypt %q: %v", key, err)
+	}
+
+	var item Item
+	if err = json.Unmarshal(bytesFromJS(plaintext), &item); err != nil {
+		return Item{}, WrapError(err, WASMBackend, k.service, key)
+	}
+
+	return checkActive(item)
+}
+
+// GetMetadata for wasm only returns the modification time, as everything else is encrypted
+func (k *wasmKeyring) GetMetadata(key string) (Metadata, error) {
+	rec, err := k.getRecord(key)
+	if err != nil {
+		return Metadata{}, WrapError(err, WASMBackend, k.service, key)
+	}
+
+	return Metadata{
+		ModificationTime: rec.modified,
+	}, nil
+}
+
+func (k *wasmKeyring) Set(item Item) error {
+	if err := ValidateRotationSchedule(item); err != nil {
+		return WrapError(err, WASMBackend, k.service, item.Key)
+	}
+
+	payload, err := json.Marshal(item)
+	if err != nil {
+		return WrapError(err, WASMBackend, k.service, item.Key)
+	}
+
+	salt := make([]byte, 16)
+	iv := make([]byte, 12)
+	if _, err = rand.Read(salt); err != nil {
+		return WrapError(err, WASMBackend, k.service, item.Key)
+	}
+	if _, err = rand.Read(iv); err != nil {
+		return WrapError(err, WASMBackend, k.service, item.Key)
+	}
+
+	cryptoKey, err := k.deriveKey(salt)
+	if err != nil {
+		return WrapError(err, WASMBackend, k.service, item.Key)
+	}
+
+	ciphertext, err := await(js.Global().Get("crypto").Get("subtle").Call("encrypt",
+		map[string]interface{}{"name": "AES-GCM", "iv": bytesToJS(iv)},
+		cryptoKey, bytesToJS(payload)))
+	if err != nil {
+		return fmt.Errorf("Failed to encrypt %q: %v", item.Key, err)
+	}
+
+	store, err := k.store("readwrite")
+	if err != nil {
+		return WrapError(err, WASMBackend, k.service, item.Key)
+	}
+
+	record := js.Global().Get("Object").New()
+	record.Set("salt", bytesToJS(salt))
+	record.Set("iv", bytesToJS(iv))
+	record.Set("data", js.Global().Get("Uint8Array").New(ciphertext))
+	record.Set("modified", time.Now().UnixMilli())
+
+	_, err = awaitRequest(store.Call("put", record, k.id(item.Key)))
+	return WrapError(err, WASMBackend, k.service, item.Key)
+}
+
+func (k *wasmKeyring) Remove(key string) error {
+	if _, err := k.getRecord(key); err != nil {
+		return WrapError(err, WASMBackend, k.service, key)
+	}
+
+	store, err := k.store("readwrite")
+	if err != nil {
+		return WrapError(err, WASMBackend, k.service, key)
+	}
+
+	_, err = awaitRequest(store.Call("delete", k.id(key)))
+	return WrapError(err, WASMBackend, k.service, key)
+}
+
+func (k *wasmKeyring) Keys() ([]string, error) {
+	store, err := k.store("readonly")
+	if err != nil {
+		return nil, WrapError(err, WASMBackend, k.service, "")
+	}
+
+	ids, err := awaitRequest(store.Call("getAllKeys"))
+	if err != nil {
+		return nil, WrapError(err, WASMBackend, k.service, "")
+	}
+
+	var keys = []string{}
+	prefix := k.id("")
+	for i := 0; i < ids.Length(); i++ {
+		if id := ids.Index(i).String(); strings.HasPrefix(id, prefix) {
+			keys = append(keys, strings.TrimPrefix(id, prefix))
+		}
+	}
+
+	return keys, nil
+}
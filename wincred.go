@@ -1,16 +1,23 @@
+//go:build windows
 // +build windows
 
 package keyring
 
 import (
+	"fmt"
 	"strings"
+	"time"
 
 	"github.com/danieljoos/wincred"
 )
 
+// winCredNotBeforeAttribute is the credential attribute that holds Item.NotBefore
+const winCredNotBeforeAttribute = "NotBefore"
+
 type windowsKeyring struct {
-	name   string
-	prefix string
+	name     string
+	prefix   string
+	credType WinCredentialType
 }
 
 func init() {
@@ -25,20 +32,33 @@ func init() {
 			prefix = "keyring"
 		}
 
+		credType := cfg.WinCredentialType
+		if credType == "" {
+			credType = WinCredTypeGeneric
+		}
+		if credType != WinCredTypeGeneric && credType != WinCredTypeDomainPassword {
+			return nil, fmt.Errorf("Unknown Windows credential type %q", credType)
+		}
+
 		return &windowsKeyring{
-			name:   name,
-			prefix: prefix,
+			name:     name,
+			prefix:   prefix,
+			credType: credType,
 		}, nil
 	})
 }
 
 func (k *windowsKeyring) Get(key string) (Item, error) {
+	if k.credType == WinCredTypeDomainPassword {
+		return k.getDomainPassword(key)
+	}
+
 	cred, err := wincred.GetGenericCredential(k.credentialName(key))
 	if err != nil {
 		if err.Error() == "Element not found." {
 			return Item{}, ErrKeyNotFound
 		}
-		return Item{}, err
+		return Item{}, WrapError(err, WinCredBackend, k.name, key)
 	}
 
 	item := Item{
@@ -46,7 +66,15 @@ func (k *windowsKeyring) Get(key string) (Item, error) {
 		Data: cred.CredentialBlob,
 	}
 
-	return item, nil
+	for _, attr := range cred.Attributes {
+		if attr.Keyword == winCredNotBeforeAttribute {
+			if item.NotBefore, err = time.Parse(time.RFC3339Nano, string(attr.Value)); err != nil {
+				return Item{}, err
+			}
+		}
+	}
+
+	return checkActive(item)
 }
 
 // GetMetadata for pass returns an error indicating that it's unsupported
@@ -57,20 +85,41 @@ func (k *windowsKeyring) GetMetadata(_ string) (Metadata, error) {
 }
 
 func (k *windowsKeyring) Set(item Item) error {
+	if k.credType == WinCredTypeDomainPassword {
+		return k.setDomainPassword(item)
+	}
+
 	cred := wincred.NewGenericCredential(k.credentialName(item.Key))
 	cred.CredentialBlob = item.Data
-	return cred.Write()
+	if !item.NotBefore.IsZero() {
+		cred.Attributes = []wincred.CredentialAttribute{{
+			Keyword: winCredNotBeforeAttribute,
+			Value:   []byte(item.NotBefore.Format(time.RFC3339Nano)),
+		}}
+	}
+	return WrapError(cred.Write(), WinCredBackend, k.name, item.Key)
 }
 
 func (k *windowsKeyring) Remove(key string) error {
+	if k.credType == WinCredTypeDomainPassword {
+		cred, err := wincred.GetDomainPassword(key)
+		if err != nil {
+			if err.Error() == "Element not found." {
+				return ErrKeyNotFound
+			}
+			return WrapError(err, WinCredBackend, k.name, key)
+		}
+		return WrapError(cred.Delete(), WinCredBackend, k.name, key)
+	}
+
 	cred, err := wincred.GetGenericCredential(k.credentialName(key))
 	if err != nil {
 		if err.Error() == "Element not found." {
 			return ErrKeyNotFound
 		}
-		return err
+		return WrapError(err, WinCredBackend, k.name, key)
 	}
-	return cred.Delete()
+	return WrapError(cred.Delete(), WinCredBackend, k.name, key)
 }
 
 func (k *windowsKeyring) Keys() ([]string, error) {
@@ -78,6 +127,14 @@ func (k *windowsKeyring) Keys() ([]string, error) {
 
 	if creds, err := wincred.List(); err == nil {
 		for _, cred := range creds {
+			if k.credType == WinCredTypeDomainPassword {
+				// List doesn't report the type, so check for a domain password of the same name
+				if _, err := wincred.GetDomainPassword(cred.TargetName); err == nil {
+					results = append(results, cred.TargetName)
+				}
+				continue
+			}
+
 			prefix := k.credentialName("")
 			if strings.HasPrefix(cred.TargetName, prefix) {
 				results = append(results, strings.TrimPrefix(cred.TargetName, prefix))
@@ -88,6 +145,39 @@ func (k *windowsKeyring) Keys() ([]string, error) {
 	return results, nil
 }
 
+// getDomainPassword reads the domain password for the server named key. Domain credentials
+// are named after the server, so they aren't prefixed.
+func (k *windowsKeyring) getDomainPassword(key string) (Item, error) {
+	cred, err := wincred.GetDomainPassword(key)
+	if err != nil {
+		if err.Error() == "Element not found." {
+			return Item{}, ErrKeyNotFound
+		}
+		return Item{}, WrapError(err, WinCredBackend, k.name, key)
+	}
+
+	domain, username := splitWinDomainUserName(cred.UserName)
+	return WinDomainCredential{
+		Item:     Item{Key: key, Data: decodeWinPassword(cred.CredentialBlob)},
+		Domain:   domain,
+		Username: username,
+	}.ToItem(), nil
+}
+
+// setDomainPassword writes item as a domain password, taking the user from the "domain"
+// and "username" tags set by WinDomainCredential.ToItem
+func (k *windowsKeyring) setDomainPassword(item Item) error {
+	c := WinDomainCredentialFromItem(item)
+	if c.Username == "" {
+		return fmt.Errorf("Domain credential %q has no username", item.Key)
+	}
+
+	cred := wincred.NewDomainPassword(item.Key)
+	cred.UserName = winDomainUserName(c.Domain, c.Username)
+	cred.CredentialBlob = encodeWinPassword(item.Data)
+	return WrapError(cred.Write(), WinCredBackend, k.name, item.Key)
+}
+
 func (k *windowsKeyring) credentialName(key string) string {
 	return k.prefix + ":" + k.name + ":" + key
 }
@@ -0,0 +1,90 @@
+package keyring
+
+import (
+	"encoding/binary"
+	"strings"
+	"unicode/utf16"
+)
+
+// WinCredentialType is the type of Windows credential that the wincred backend stores
+type WinCredentialType string
+
+const (
+	// WinCredTypeGeneric stores items as CRED_TYPE_GENERIC credentials, named with
+	// Config.WinCredPrefix and the service name. This is the default.
+	WinCredTypeGeneric WinCredentialType = "generic"
+	// WinCredTypeDomainPassword stores items as CRED_TYPE_DOMAIN_PASSWORD credentials, which
+	// Windows uses to log on to the server named by the key
+	WinCredTypeDomainPassword WinCredentialType = "domain-password"
+)
+
+// WinDomainCredential is an item stored as a Windows domain password credential. The
+// item's key is the target server and its data is the password.
+type WinDomainCredential struct {
+	Item
+	Domain   string
+	Username string
+}
+
+// ToItem returns the item to Set on a wincred keyring of type WinCredTypeDomainPassword.
+// The domain and username are carried in the item's tags.
+func (c WinDomainCredential) ToItem() Item {
+	item := c.Item
+	tags := map[string]string{}
+	for name, value := range item.Tags {
+		tags[name] = value
+	}
+	tags["domain"] = c.Domain
+	tags["username"] = c.Username
+	item.Tags = tags
+	return item
+}
+
+// WinDomainCredentialFromItem returns the domain credential for an item read from a
+// wincred keyring of type WinCredTypeDomainPassword
+func WinDomainCredentialFromItem(item Item) WinDomainCredential {
+	return WinDomainCredential{
+		Item:     item,
+		Domain:   item.Tags["domain"],
+		Username: item.Tags["username"],
+	}
+}
+
+// winDomainUserName joins a domain and username as DOMAIN\username, the form Windows
+// expects for domain credentials
+func winDomainUserName(domain, username string) string {
+	if domain == "" {
+		return username
+	}
+	return domain + `\` + username
+}
+
+// splitWinDomainUserName splits DOMAIN\username or username@domain into its parts
+func splitWinDomainUserName(s string) (domain, username string) {
+	if idx := strings.Index(s, `\`); idx >= 0 {
+		return s[:idx], s[idx+1:]
+	}
+	if idx := strings.LastIndex(s, "@"); idx >= 0 {
+		return s[idx+1:], s[:idx]
+	}
+	return "", s
+}
+
+// encodeWinPassword encodes a password as little-endian UTF-16, as Windows stores domain
+// passwords
+func encodeWinPassword(password []byte) []byte {
+	units := utf16.Encode([]rune(string(password)))
+	b := make([]byte, len(units)*2)
+	for idx, u := range units {
+		binary.LittleEndian.PutUint16(b[idx*2:], u)
+	}
+	return b
+}
+
+func decodeWinPassword(b []byte) []byte {
+	units := make([]uint16, len(b)/2)
+	for idx := range units {
+		units[idx] = binary.LittleEndian.Uint16(b[idx*2:])
+	}
+	return []byte(string(utf16.Decode(units)))
+}
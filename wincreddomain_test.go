@@ -0,0 +1,32 @@
+package keyring
+
+import "testing"
+
+func TestWinDomainCredentialItemRoundTrip(t *testing.T) {
+	cred := WinDomainCredential{
+		Item:     Item{Key: "fileserver.example.com", Data: []byte("llamas are gr€at")},
+		Domain:   "EXAMPLE",
+		Username: "llama",
+	}
+
+	got := WinDomainCredentialFromItem(cred.ToItem())
+	if got.Domain != "EXAMPLE" || got.Username != "llama" || got.Key != "fileserver.example.com" {
+		t.Fatalf("Unexpected credential: %+v", got)
+	}
+
+	domain, username := splitWinDomainUserName(winDomainUserName(got.Domain, got.Username))
+	if domain != "EXAMPLE" || username != "llama" {
+		t.Fatalf("Unexpected user name split: %q %q", domain, username)
+	}
+	if domain, username = splitWinDomainUserName("llama@example.com"); domain != "example.com" || username != "llama" {
+		t.Fatalf("Unexpected UPN split: %q %q", domain, username)
+	}
+
+	encoded := encodeWinPassword(got.Data)
+	if len(encoded) != 2*len([]rune(string(got.Data))) {
+		t.Fatalf("Expected UTF-16 encoding, got %d bytes", len(encoded))
+	}
+	if string(decodeWinPassword(encoded)) != "llamas are gr€at" {
+		t.Fatalf("Password didn't round trip: %q", decodeWinPassword(encoded))
+	}
+}
@@ -0,0 +1,48 @@
+package keyring
+
+import (
+	"fmt"
+	"strings"
+)
+
+// BackendError is an error from a backend's underlying store, with the backend, service
+// and key it happened for
+type BackendError struct {
+	Backend BackendType
+	Service string
+	Key     string
+	Err     error
+}
+
+func (e *BackendError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "keyring [%s]", e.Backend)
+	if e.Service != "" {
+		fmt.Fprintf(&b, " service=%s", e.Service)
+	}
+	if e.Key != "" {
+		fmt.Fprintf(&b, " key=%s", e.Key)
+	}
+	fmt.Fprintf(&b, ": %v", e.Err)
+	return b.String()
+}
+
+// Unwrap returns the underlying error
+func (e *BackendError) Unwrap() error {
+	return e.Err
+}
+
+// WrapError adds the backend, service and key to err, e.g. "keyring [keychain] service=myapp
+// key=api-token: The user name or passphrase you entered is not correct.". Empty service or
+// key are left out. nil and the package's sentinel errors, such as ErrKeyNotFound, are
+// returned unchanged so that they can still be compared with ==.
+func WrapError(err error, backend BackendType, service, key string) error {
+	switch err {
+	case nil, ErrKeyNotFound, ErrReadOnly, ErrCorrupted, ErrNotYetActive, ErrMetadataNeedsCredentials, ErrNoAvailImpl, ErrPassphraseRequired:
+		return err
+	}
+	if _, ok := err.(*BackendError); ok {
+		return err
+	}
+	return &BackendError{Backend: backend, Service: service, Key: key, Err: err}
+}
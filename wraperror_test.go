@@ -0,0 +1,71 @@
+package keyring
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestWrapError(t *testing.T) {
+	cause := errors.New("errSecInteractionNotAllowed")
+	err := WrapError(cause, KeychainBackend, "myapp", "api-token")
+
+	if got, want := err.Error(), "keyring [keychain] service=myapp key=api-token: errSecInteractionNotAllowed"; got != want {
+		t.Fatalf("Expected %q, got %q", want, got)
+	}
+	if !errors.Is(err, cause) {
+		t.Fatalf("Expected the wrapped error to match its cause")
+	}
+
+	var be *BackendError
+	if !errors.As(err, &be) || be.Backend != KeychainBackend || be.Key != "api-token" {
+		t.Fatalf("Expected a *BackendError, got %#v", err)
+	}
+}
+
+func TestWrapErrorOmitsEmptyFields(t *testing.T) {
+	err := WrapError(errors.New("boom"), PassBackend, "", "")
+	if got, want := err.Error(), "keyring [pass]: boom"; got != want {
+		t.Fatalf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestWrapErrorUnwrapsToErrorType(t *testing.T) {
+	_, cause := os.Open("/no/such/file")
+	err := WrapError(cause, FileBackend, "", "llamas")
+
+	var pathErr *os.PathError
+	if !errors.As(err, &pathErr) {
+		t.Fatalf("Expected an *os.PathError, got %#v", err)
+	}
+	if !os.IsNotExist(errors.Unwrap(err)) {
+		t.Fatalf("Expected the cause to be a not exist error")
+	}
+}
+
+func TestWrapErrorPassesThroughSentinels(t *testing.T) {
+	for _, sentinel := range []error{nil, ErrKeyNotFound, ErrReadOnly, ErrCorrupted, ErrMetadataNeedsCredentials} {
+		if err := WrapError(sentinel, KeychainBackend, "myapp", "api-token"); err != sentinel {
+			t.Fatalf("Expected %v unchanged, got %v", sentinel, err)
+		}
+	}
+}
+
+func TestWrapErrorDoesNotWrapTwice(t *testing.T) {
+	err := WrapError(errors.New("boom"), PassBackend, "", "llamas")
+	if WrapError(err, PassBackend, "", "llamas") != err {
+		t.Fatalf("Expected an already wrapped error to be returned unchanged")
+	}
+}
+
+func TestFileKeyringWrapsErrors(t *testing.T) {
+	dir := t.TempDir()
+	cause := errors.New("no terminal")
+	k := &fileKeyring{dir: dir, passwordFunc: func(string) (string, error) { return "", cause }}
+
+	if err := k.Set(Item{Key: "llamas"}); !errors.Is(err, cause) {
+		t.Fatalf("Expected the prompt error, got: %v", err)
+	} else if got, want := err.Error(), "keyring [file] service="+dir+" key=llamas: no terminal"; got != want {
+		t.Fatalf("Expected %q, got %q", want, got)
+	}
+}